@@ -0,0 +1,134 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// GraylogViewTypeSearch is the saved search
+	GraylogViewTypeSearch = "SEARCH"
+	// GraylogViewTypeDashboard is the dashboard
+	GraylogViewTypeDashboard = "DASHBOARD"
+
+	// DriftPolicyRevert replaces the changes of the view made in Graylog with the definition from the spec
+	DriftPolicyRevert = "Revert"
+	// DriftPolicyReport keeps the changes of the view made in Graylog and reports them in the status
+	DriftPolicyReport = "Report"
+
+	// GraylogViewReady is the condition of the view applied to Graylog
+	GraylogViewReady = "Ready"
+	// GraylogViewDrifted is the condition of the view changed in Graylog after it was applied by the operator
+	GraylogViewDrifted = "Drifted"
+
+	// DefaultDriftCheckInterval is the interval of the drift checks if driftCheckInterval is not set
+	DefaultDriftCheckInterval = 5 * time.Minute
+)
+
+// GraylogViewSpec defines the dashboard or the saved search in Graylog
+type GraylogViewSpec struct {
+	// Type of the view, SEARCH for the saved search or DASHBOARD for the dashboard
+	// +kubebuilder:validation:Enum=SEARCH;DASHBOARD
+	Type string `json:"type"`
+	// Title of the view in Graylog
+	// +kubebuilder:validation:MinLength=1
+	Title       string `json:"title"`
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Streams are the titles of the streams which are searched by all queries of the view.
+	// The queries search all streams if it is not set
+	Streams []string `json:"streams,omitempty"`
+	// Search is the search of the view with the queries and the parameters as in the views API of Graylog.
+	// The id of the search is generated by Graylog
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Search runtime.RawExtension `json:"search"`
+	// State of the view with the widgets by the ids of the queries as in the views API of Graylog
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	State runtime.RawExtension `json:"state,omitempty"`
+	// DriftPolicy is the action for the changes of the view made in Graylog. Revert replaces them
+	// with the view from the spec, Report only sets the Drifted condition and emits the Event
+	// +kubebuilder:validation:Enum=Revert;Report
+	// +kubebuilder:default=Revert
+	DriftPolicy string `json:"driftPolicy,omitempty"`
+	// DriftCheckInterval is the interval of the comparison of the view in Graylog with the applied one, 5m by default
+	DriftCheckInterval *metav1.Duration `json:"driftCheckInterval,omitempty"`
+}
+
+// GraylogViewStatus defines the observed state of GraylogView
+type GraylogViewStatus struct {
+	// ViewId is the id of the view in Graylog
+	ViewId string `json:"viewId,omitempty"`
+	// SearchId is the id of the search of the view in Graylog
+	SearchId string `json:"searchId,omitempty"`
+	// ObservedGeneration is the generation of the spec which is applied to Graylog
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// AppliedHash is the hash of the view and its search read from Graylog after they were applied.
+	// The view is drifted if the hash of the view in Graylog differs from it
+	AppliedHash string `json:"appliedHash,omitempty"`
+	// LastDriftTime is the time when the changes of the view in Graylog were detected last time
+	LastDriftTime *metav1.Time `json:"lastDriftTime,omitempty"`
+	// Conditions are Ready and Drifted
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Title",type=string,JSONPath=`.spec.title`
+//+kubebuilder:printcolumn:name="View",type=string,JSONPath=`.status.viewId`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Drifted",type=string,JSONPath=`.status.conditions[?(@.type=="Drifted")].status`
+
+// GraylogView is the Schema for the dashboards and the saved searches of Graylog
+type GraylogView struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GraylogViewSpec   `json:"spec,omitempty"`
+	Status GraylogViewStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GraylogViewList contains a list of GraylogView
+type GraylogViewList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GraylogView `json:"items"`
+}
+
+// IsReportDrift returns true if the changes of the view in Graylog are only reported
+func (view *GraylogView) IsReportDrift() bool {
+	return view.Spec.DriftPolicy == DriftPolicyReport
+}
+
+// GetDriftCheckInterval returns the interval of the drift checks
+func (view *GraylogView) GetDriftCheckInterval() time.Duration {
+	if view.Spec.DriftCheckInterval != nil && view.Spec.DriftCheckInterval.Duration > 0 {
+		return view.Spec.DriftCheckInterval.Duration
+	}
+	return DefaultDriftCheckInterval
+}
+
+func init() {
+	SchemeBuilder.Register(&GraylogView{}, &GraylogViewList{})
+}
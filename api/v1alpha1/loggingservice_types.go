@@ -22,9 +22,12 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -43,6 +46,13 @@ type LoggingServiceSpec struct {
 	ContainerRuntimeType         string                        `json:"containerRuntimeType,omitempty"`
 	Ipv6                         bool                          `json:"ipv6,omitempty"`
 	OpenshiftDeploy              bool                          `json:"openshiftDeploy,omitempty"`
+	// DeletionPolicy defines which resources are cleaned up when the LoggingService is deleted:
+	// Retain - keep PVCs and objects in Graylog and OpenSearch,
+	// DeleteKubernetesOnly - delete all Kubernetes resources of the components including PVCs,
+	// DeleteAll - also delete objects created by the operator in Graylog and OpenSearch
+	// +kubebuilder:validation:Enum=Retain;DeleteKubernetesOnly;DeleteAll
+	// +kubebuilder:default=Retain
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
 }
 
 // LoggingServiceCondition contains description of status of LoggingService
@@ -56,7 +66,76 @@ type LoggingServiceCondition struct {
 
 // LoggingServiceStatus defines the observed state of LoggingService
 type LoggingServiceStatus struct {
-	Conditions []LoggingServiceCondition `json:"conditions"`
+	Conditions     []LoggingServiceCondition `json:"conditions"`
+	MongoDBUpgrade *MongoDBUpgradeStatus     `json:"mongoDBUpgrade,omitempty"`
+	// CredentialsRotation is the result of the last rotation of Graylog credentials
+	CredentialsRotation *CredentialsRotationStatus `json:"credentialsRotation,omitempty"`
+	// ContentPacks are the installed revisions of the content packs from contentPackPaths and contentPacks
+	ContentPacks []ContentPackStatus `json:"contentPacks,omitempty"`
+	// ExternalObjects are the objects created by the operator in Graylog and OpenSearch from the spec
+	ExternalObjects *ExternalObjectsStatus `json:"externalObjects,omitempty"`
+}
+
+// ExternalObjectsStatus keeps the objects created by the operator in Graylog and OpenSearch from the spec,
+// so the objects which are removed from the spec are deleted
+type ExternalObjectsStatus struct {
+	// AuthBackends are the titles of the authentication backends
+	AuthBackends []string `json:"authBackends,omitempty"`
+	// ClusterSettings are the keys of the persistent cluster settings of OpenSearch
+	ClusterSettings []string `json:"clusterSettings,omitempty"`
+	// IndexTemplates are the names of the index templates of OpenSearch
+	IndexTemplates []string `json:"indexTemplates,omitempty"`
+	// LifecyclePolicies are the names of the ISM policies of OpenSearch or the ILM policies of Elasticsearch
+	LifecyclePolicies []string `json:"lifecyclePolicies,omitempty"`
+	// EventDefinitions are the titles of the event definitions of Graylog
+	EventDefinitions []string `json:"eventDefinitions,omitempty"`
+	// Notifications are the titles of the event notifications of Graylog
+	Notifications []string `json:"notifications,omitempty"`
+	// Roles are the names of the roles of Graylog from the roles and the namespace teams
+	Roles []string `json:"roles,omitempty"`
+	// Users are the usernames of the users of Graylog
+	Users []string `json:"users,omitempty"`
+	// LookupTables are the names of the lookup tables of Graylog
+	LookupTables []string `json:"lookupTables,omitempty"`
+	// LookupCaches are the names of the lookup caches of Graylog
+	LookupCaches []string `json:"lookupCaches,omitempty"`
+	// LookupDataAdapters are the names of the lookup data adapters of Graylog
+	LookupDataAdapters []string `json:"lookupDataAdapters,omitempty"`
+}
+
+// MongoDBUpgradeStatus keeps the progress of the MongoDB upgrade, so the upgrade is resumed from the next step
+type MongoDBUpgradeStatus struct {
+	// CompletedVersion is the featureCompatibilityVersion set by the last completed step
+	CompletedVersion string `json:"completedVersion,omitempty"`
+	// Image of the last completed step. MongoDB is rolled back to it if the next step fails
+	Image string `json:"image,omitempty"`
+	// FailedVersion is the version of the step which failed
+	FailedVersion string `json:"failedVersion,omitempty"`
+	// BackupVersion is the target version of the upgrade the pre-upgrade backup is taken for
+	BackupVersion string `json:"backupVersion,omitempty"`
+}
+
+// CredentialsRotationStatus keeps the last completed rotation, so the rotation is run once for each trigger
+type CredentialsRotationStatus struct {
+	// Trigger is the value of the annotation logging.qubership.org/rotate-credentials of the last completed rotation
+	Trigger string `json:"trigger,omitempty"`
+	// LastRotationTime is the time when the login with the new credentials was confirmed
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+}
+
+// ContentPackStatus is the installed revision of the content pack, so the content pack is reinstalled
+// only if its revision is changed and is uninstalled when its source is removed
+type ContentPackStatus struct {
+	// Source is the URL of the content pack or of the archive with it
+	Source string `json:"source"`
+	// Id of the content pack
+	Id string `json:"id"`
+	// Revision of the content pack which is installed
+	Revision int `json:"revision"`
+	// InstallationId is the id of the installation of the revision
+	InstallationId string `json:"installationId,omitempty"`
+	// Digest of the manifest of the OCI artifact the content pack is installed from
+	Digest string `json:"digest,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -87,6 +166,10 @@ type Graylog struct {
 	MongoResources                           *v1.ResourceRequirements     `json:"mongoResources,omitempty"`
 	InitResources                            *v1.ResourceRequirements     `json:"initResources,omitempty"`
 	MongoDBUpgrade                           *MongoDBUpgrade              `json:"mongoDBUpgrade,omitempty"`
+	MongoDB                                  *GraylogMongoDB              `json:"mongoDB,omitempty"`
+	Storage                                  *GraylogStorage              `json:"storage,omitempty"`
+	Backup                                   *GraylogBackup               `json:"backup,omitempty"`
+	CredentialsRotation                      *GraylogCredentialsRotation  `json:"credentialsRotation,omitempty"`
 	AuthProxy                                *AuthProxy                   `json:"authProxy,omitempty"`
 	TLS                                      *GraylogTLS                  `json:"tls,omitempty"`
 	OpenSearch                               *OpenSearch                  `json:"openSearch,omitempty"`
@@ -111,6 +194,14 @@ type Graylog struct {
 	InitContainerDockerImage                 string                       `json:"initContainerDockerImage,omitempty"`
 	GraylogSecretName                        string                       `json:"graylogSecretName"`
 	ContentPacks                             []*ContentPackPathHTTPConfig `json:"contentPacks,omitempty"`
+	SavedSearches                            []SavedSearchSource          `json:"savedSearches,omitempty"`
+	Alerts                                   *GraylogAlerts               `json:"alerts,omitempty"`
+	Outputs                                  []GraylogOutput              `json:"outputs,omitempty"`
+	LookupTables                             *GraylogLookupTables         `json:"lookupTables,omitempty"`
+	Roles                                    []GraylogRole                `json:"roles,omitempty"`
+	Users                                    []GraylogUser                `json:"users,omitempty"`
+	NamespaceTeams                           *GraylogNamespaceTeams       `json:"namespaceTeams,omitempty"`
+	Authentication                           *GraylogAuthentication       `json:"authentication,omitempty"`
 	Streams                                  []Stream                     `json:"streams,omitempty"`
 	ProcessbufferProcessors                  int                          `json:"processbufferProcessors,omitempty"`
 	OutputbufferProcessorThreadsMaxPoolSize  int                          `json:"outputbufferProcessorThreadsMaxPoolSize,omitempty"`
@@ -123,22 +214,104 @@ type Graylog struct {
 	MaxSize                                  int                          `json:"maxSize,omitempty"`
 	InputbufferProcessors                    int                          `json:"inputbufferProcessors,omitempty"`
 	StartupTimeout                           int                          `json:"startupTimeout,omitempty"`
+	Replicas                                 int                          `json:"replicas,omitempty"`
 	IndexShards                              int                          `json:"indexShards,omitempty"`
 	IndexReplicas                            int                          `json:"indexReplicas,omitempty"`
 	MaxNumberOfIndices                       int                          `json:"maxNumberOfIndices,omitempty"`
 	LogsRotationSizeGb                       int                          `json:"logsRotationSizeGb,omitempty"`
 	InputPort                                int                          `json:"inputPort"`
 	S3Archive                                bool                         `json:"s3Archive"`
+	// Version of Graylog in DockerImage, e.g. 6.0.5. It is required for the image without the version in the tag,
+	// e.g. pinned by digest, because the version of the running Graylog is the previous one during the upgrade
+	Version string `json:"version,omitempty"`
+	// OCIPollInterval is the interval of the checks of the OCI artifacts of contentPacks and savedSearches
+	// for the new pushes of their tags, 10m by default
+	OCIPollInterval *metav1.Duration `json:"ociPollInterval,omitempty"`
 }
 
 type ContentPackPathHTTPConfig struct {
 	HTTPConfig *HTTPConfig `yaml:"http,omitempty" json:"tls,omitempty"`
 	URL        string      `yaml:"url,omitempty" json:"url,omitempty"`
+	// Checksum is the SHA-256 checksum of the file downloaded by the URL in the format "sha256:<hex>".
+	// The content pack isn't installed if the checksum of the download doesn't match. It is supported only with the URL
+	Checksum string `yaml:"checksum,omitempty" json:"checksum,omitempty"`
+	// ContentSource is used instead of the URL to install the content packs without a web server.
+	// The files with the suffix .zip are unpacked as the archives downloaded by the URL
+	ContentSource `yaml:",inline" json:",inline"`
+}
+
+// ContentSource is the source of the JSON files with Graylog content which doesn't require a web server.
+// Only one of the sources can be specified
+type ContentSource struct {
+	// ConfigMap in the namespace of the LoggingService with the files in its keys
+	ConfigMap *ContentObjectReference `yaml:"configMap,omitempty" json:"configMap,omitempty"`
+	// Secret in the namespace of the LoggingService with the files in its keys
+	Secret *ContentObjectReference `yaml:"secret,omitempty" json:"secret,omitempty"`
+	// Inline contains the files by their names, e.g. "errors-search.json": "{...}"
+	Inline map[string]string `yaml:"inline,omitempty" json:"inline,omitempty"`
+	// OCI is the reference of the OCI artifact with the files in its layers in the format
+	// [http://]host[:port]/repository[:tag|@digest], e.g. "registry.example.com/logging/content-packs:1.0.0".
+	// The names of the files are taken from the annotation org.opencontainers.image.title of the layers
+	OCI string `yaml:"oci,omitempty" json:"oci,omitempty"`
+}
+
+// ContentObjectReference refers to the ConfigMap or the Secret with the files
+type ContentObjectReference struct {
+	Name string `yaml:"name" json:"name"`
+	// Key with the file, all keys of the object are used if it's empty
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+}
+
+// SavedSearchSource is the source of the custom saved searches. The names of the files
+// must have the suffix search.json for the searches and view.json for the views
+type SavedSearchSource struct {
+	// HTTPConfig contains the credentials and the TLS config of the OCI registry
+	HTTPConfig    *HTTPConfig `yaml:"http,omitempty" json:"tls,omitempty"`
+	ContentSource `yaml:",inline" json:",inline"`
 }
 
 type OpenSearch struct {
 	HTTPConfig *HTTPConfig `yaml:"http,omitempty" json:"tls,omitempty"`
 	Host       string      `yaml:"url,omitempty" json:"url,omitempty"`
+	// Templates are the composable index templates created in OpenSearch
+	Templates []OpenSearchIndexTemplate `yaml:"templates,omitempty" json:"templates,omitempty"`
+	// Policies are the index lifecycle policies: ISM policies in OpenSearch or ILM policies in Elasticsearch
+	Policies []OpenSearchPolicy `yaml:"policies,omitempty" json:"policies,omitempty"`
+	// ClusterSettings are the persistent cluster settings in the flat format,
+	// e.g. "cluster.routing.allocation.disk.watermark.low": "85%"
+	ClusterSettings map[string]string `yaml:"clusterSettings,omitempty" json:"clusterSettings,omitempty"`
+}
+
+// OpenSearchIndexTemplate describes the composable index template
+type OpenSearchIndexTemplate struct {
+	Name          string   `yaml:"name" json:"name"`
+	IndexPatterns []string `yaml:"indexPatterns" json:"indexPatterns"`
+	// Priority of the template, the template with the highest priority is applied if several templates match the index
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+	// Settings are the index settings in the flat format, e.g. "index.number_of_replicas": "1"
+	Settings map[string]string `yaml:"settings,omitempty" json:"settings,omitempty"`
+	// FieldTypes are the OpenSearch types of the message fields, e.g. "request_time": "float"
+	FieldTypes map[string]string `yaml:"fieldTypes,omitempty" json:"fieldTypes,omitempty"`
+}
+
+// OpenSearchPolicy describes the lifecycle policy which moves the indices from the hot state
+// to the warm state and deletes them after the configured age
+type OpenSearchPolicy struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// IndexPatterns are the patterns of the new indices the ISM policy is applied to.
+	// The ILM policy of Elasticsearch is applied by the "index.lifecycle.name" setting of the index template
+	IndexPatterns []string `yaml:"indexPatterns,omitempty" json:"indexPatterns,omitempty"`
+	Priority      int      `yaml:"priority,omitempty" json:"priority,omitempty"`
+	// WarmAfter is the age of the index to move it to the warm state, e.g. "7d". The warm state is skipped if it is not set
+	// +kubebuilder:validation:Pattern=`^[0-9]+(d|h|m|s)$`
+	WarmAfter string `yaml:"warmAfter,omitempty" json:"warmAfter,omitempty"`
+	// WarmReplicas is the number of replicas of the index in the warm state
+	// +kubebuilder:validation:Minimum=0
+	WarmReplicas *int `yaml:"warmReplicas,omitempty" json:"warmReplicas,omitempty"`
+	// DeleteAfter is the age of the index to delete it, e.g. "30d". The index is not deleted if it is not set
+	// +kubebuilder:validation:Pattern=`^[0-9]+(d|h|m|s)$`
+	DeleteAfter string `yaml:"deleteAfter,omitempty" json:"deleteAfter,omitempty"`
 }
 
 type HTTPConfig struct {
@@ -418,12 +591,311 @@ type Key struct {
 }
 
 type Stream struct {
-	Name               string `json:"name"`
+	Name string `json:"name"`
+	// RotationStrategy of the index set: sizeBased, timeBased, countBased
+	// or timeSizeOptimizing (Graylog 5.1 and later)
 	RotationStrategy   string `json:"rotationStrategy,omitempty"`
 	RotationPeriod     string `json:"rotationPeriod,omitempty"`
 	MaxSize            int    `json:"maxSize,omitempty"`
 	MaxNumberOfIndices int    `json:"maxNumberOfIndices,omitempty"`
-	Install            bool   `json:"install"`
+	// MaxDocsPerIndex is the number of messages in the index for the countBased rotation strategy
+	MaxDocsPerIndex int `json:"maxDocsPerIndex,omitempty"`
+	// IndexLifetimeMin and IndexLifetimeMax are ISO 8601 durations for the timeSizeOptimizing rotation strategy
+	IndexLifetimeMin string `json:"indexLifetimeMin,omitempty"`
+	IndexLifetimeMax string `json:"indexLifetimeMax,omitempty"`
+	// RetentionStrategy is the action for the indices out of the retention: delete, close
+	// or snapshot to the archives repository and delete
+	// +kubebuilder:validation:Enum=delete;close;snapshot
+	RetentionStrategy string `json:"retentionStrategy,omitempty"`
+	// SnapshotAfter is the age of the index after which it is saved to the snapshot and deleted, e.g. 30d
+	SnapshotAfter string `json:"snapshotAfter,omitempty"`
+	// IndexAnalyzer is the OpenSearch analyzer of the message fields
+	IndexAnalyzer string `json:"indexAnalyzer,omitempty"`
+	// FieldTypes contains custom types of the message fields (Graylog 5.1 and later), e.g. "took_ms: long"
+	FieldTypes map[string]string `json:"fieldTypes,omitempty"`
+	Install    bool              `json:"install"`
+	// Description of the custom stream
+	Description string `json:"description,omitempty"`
+	// MatchingType of the stream rules of the custom stream: AND or OR
+	// +kubebuilder:validation:Enum=AND;OR
+	MatchingType string `json:"matchingType,omitempty"`
+	// Rules are the stream rules which route messages to the custom stream
+	Rules []StreamRule `json:"rules,omitempty"`
+	// PipelineRule is the source of the pipeline rule which routes messages to the custom stream.
+	// The rule is added to the "Logs routing" pipeline, the id of the stream is available as {{ .streamId }}
+	PipelineRule string `json:"pipelineRule,omitempty"`
+	// IndexSet is the title of the existing index set to share with the custom stream.
+	// The dedicated index set "<name> index set" is created if it is empty
+	IndexSet string `json:"indexSet,omitempty"`
+	// IndexPrefix of the dedicated index set of the custom stream
+	IndexPrefix string `json:"indexPrefix,omitempty"`
+	// RemoveFromDefault removes messages matched by the stream rules of the custom stream from "Default Stream"
+	RemoveFromDefault *bool `json:"removeFromDefault,omitempty"`
+}
+
+// StreamRule is the rule of the custom Graylog stream
+type StreamRule struct {
+	Field string `json:"field"`
+	// +kubebuilder:validation:Enum=exact;regex;contains;presence;greater;smaller
+	Type        string `json:"type"`
+	Value       string `json:"value,omitempty"`
+	Inverted    bool   `json:"inverted,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// GraylogRole is the Graylog role with read access to the streams and dashboards
+type GraylogRole struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Streams contains titles of the streams which the role can read
+	Streams []string `json:"streams,omitempty"`
+	// Dashboards contains titles of the dashboards which the role can read
+	Dashboards []string `json:"dashboards,omitempty"`
+	// Permissions contains additional Graylog permissions of the role, e.g. "savedsearches:create"
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// GraylogUser is the Graylog user with the password from the Secret
+type GraylogUser struct {
+	Username       string               `json:"username"`
+	Email          string               `json:"email,omitempty"`
+	FirstName      string               `json:"firstName,omitempty"`
+	LastName       string               `json:"lastName,omitempty"`
+	PasswordSecret v1.SecretKeySelector `json:"passwordSecret"`
+	// Roles contains names of the roles of the user. The Reader role is always added
+	Roles []string `json:"roles,omitempty"`
+	// SessionTimeoutMs is the session timeout of the user in milliseconds
+	SessionTimeoutMs int64 `json:"sessionTimeoutMs,omitempty"`
+}
+
+// GraylogNamespaceTeams creates the stream and the role per namespace, so the teams see only logs of their namespaces
+type GraylogNamespaceTeams struct {
+	Namespaces []string `json:"namespaces"`
+	// IndexSet is the title of the index set which is shared by the namespace streams. Default: "Default index set"
+	IndexSet string `json:"indexSet,omitempty"`
+}
+
+// GraylogAuthentication configures the authentication backend which is activated in Graylog
+type GraylogAuthentication struct {
+	// Type of the backend: ldap, active-directory or oidc. OIDC is available only in Graylog Enterprise
+	// +kubebuilder:validation:Enum=ldap;active-directory;oidc
+	Type string `json:"type"`
+	// Title of the backend in Graylog. Default: "Logging operator <type>"
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// DefaultRoles contains names of the roles which are assigned to all users of the backend. Default: Reader
+	DefaultRoles []string `json:"defaultRoles,omitempty"`
+	// LDAP contains the settings of the ldap and active-directory backends
+	LDAP *GraylogLDAP `json:"ldap,omitempty"`
+	// OIDC contains the settings of the oidc backend
+	OIDC *GraylogOIDC `json:"oidc,omitempty"`
+}
+
+// GraylogLDAP contains the settings of the LDAP or Active Directory servers
+type GraylogLDAP struct {
+	// Servers contains host:port of the LDAP servers
+	Servers []string `json:"servers"`
+	// +kubebuilder:validation:Enum=none;tls;start_tls
+	TransportSecurity  string `json:"transportSecurity,omitempty"`
+	VerifyCertificates bool   `json:"verifyCertificates,omitempty"`
+	// CA is the CA certificate of the LDAP servers which is added to the truststore of Graylog
+	CA                       *v1.SecretKeySelector `json:"ca,omitempty"`
+	SystemUserDN             string                `json:"systemUserDn,omitempty"`
+	SystemUserPasswordSecret *v1.SecretKeySelector `json:"systemUserPasswordSecret,omitempty"`
+	UserSearchBase           string                `json:"userSearchBase"`
+	// UserSearchPattern is the LDAP filter of the users, {0} is replaced with the login
+	UserSearchPattern     string   `json:"userSearchPattern,omitempty"`
+	UserUniqueIDAttribute string   `json:"userUniqueIdAttribute,omitempty"`
+	UserNameAttribute     string   `json:"userNameAttribute,omitempty"`
+	UserFullNameAttribute string   `json:"userFullNameAttribute,omitempty"`
+	EmailAttributes       []string `json:"emailAttributes,omitempty"`
+}
+
+// GraylogOIDC contains the settings of the OpenID Connect provider
+type GraylogOIDC struct {
+	// BaseURL is the URL of the OpenID Connect provider
+	BaseURL            string                `json:"baseUrl"`
+	ClientID           string                `json:"clientId"`
+	ClientSecretSecret *v1.SecretKeySelector `json:"clientSecretSecret,omitempty"`
+	// Claims maps the user attributes of Graylog to the claims of the token, e.g. "username: preferred_username"
+	Claims map[string]string `json:"claims,omitempty"`
+}
+
+// GraylogAlerts contains event definitions and notifications which are managed in Graylog
+type GraylogAlerts struct {
+	// InstallDefault enables the default event definitions for the Audit logs stream
+	InstallDefault bool `json:"installDefault,omitempty"`
+	// DefaultNotifications contains titles of the notifications attached to the default event definitions
+	DefaultNotifications []string                 `json:"defaultNotifications,omitempty"`
+	Notifications        []GraylogNotification    `json:"notifications,omitempty"`
+	EventDefinitions     []GraylogEventDefinition `json:"eventDefinitions,omitempty"`
+}
+
+// GraylogOutput describes the Graylog output which forwards the messages of the streams, e.g. to other Graylog
+type GraylogOutput struct {
+	Title string `json:"title"`
+	// Type of the output: gelf, stdout or the class name of the output plugin
+	Type string `json:"type"`
+	// Configuration of the output by the names of its fields as in the outputs API of Graylog
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Configuration runtime.RawExtension `json:"configuration,omitempty"`
+	// SecretRefs are the references to the keys of the Secrets by the names of the configuration fields,
+	// they have the priority over the values of the configuration
+	SecretRefs map[string]v1.SecretKeySelector `json:"secretRefs,omitempty"`
+	// Streams contains titles of the streams which messages are forwarded to the output
+	Streams []string `json:"streams,omitempty"`
+}
+
+// GraylogLookupTables contains lookup tables with their data adapters and caches which are managed in Graylog.
+// Data adapters and caches of the lookup tables are found by names, so the tables can use the ones created in Graylog
+type GraylogLookupTables struct {
+	DataAdapters []GraylogLookupDataAdapter `json:"dataAdapters,omitempty"`
+	Caches       []GraylogLookupCache       `json:"caches,omitempty"`
+	Tables       []GraylogLookupTable       `json:"tables,omitempty"`
+}
+
+// GraylogLookupDataAdapter describes the data adapter which provides the values of the lookup table
+type GraylogLookupDataAdapter struct {
+	// Name of the data adapter, it is used to find the data adapter in Graylog
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	Name string `json:"name"`
+	// Title of the data adapter, the name is used if it is empty
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Type of the data adapter: csv - CSV file from the ConfigMap, dsvhttp - DSV file downloaded over HTTP,
+	// inmemory - the entries set in the custom resource
+	// +kubebuilder:validation:Enum=csv;dsvhttp;inmemory
+	Type    string                       `json:"type"`
+	CSV     *GraylogLookupCSVAdapter     `json:"csv,omitempty"`
+	DSVHTTP *GraylogLookupDSVHTTPAdapter `json:"dsvHttp,omitempty"`
+	// Entries are the values by the keys of the inmemory data adapter
+	Entries map[string]string `json:"entries,omitempty"`
+	// CaseInsensitive enables the lookup of the keys ignoring the case
+	CaseInsensitive bool `json:"caseInsensitive,omitempty"`
+}
+
+// GraylogLookupCSVAdapter describes the CSV file from the key of the ConfigMap which is mounted into the Graylog pods
+type GraylogLookupCSVAdapter struct {
+	ConfigMap v1.ConfigMapKeySelector `json:"configMap"`
+	// KeyColumn is the name of the column with the keys
+	KeyColumn string `json:"keyColumn"`
+	// ValueColumn is the name of the column with the values
+	ValueColumn string `json:"valueColumn"`
+	// Separator of the columns, default is ","
+	Separator string `json:"separator,omitempty"`
+	// QuoteChar is the character which quotes the values, default is the double quote
+	QuoteChar string `json:"quoteChar,omitempty"`
+	// CheckIntervalSeconds is the interval of the check of the file changes, default is 60
+	CheckIntervalSeconds int `json:"checkIntervalSeconds,omitempty"`
+}
+
+// GraylogLookupDSVHTTPAdapter describes the DSV file which Graylog downloads over HTTP
+type GraylogLookupDSVHTTPAdapter struct {
+	URL string `json:"url"`
+	// KeyColumn is the number of the column with the keys starting from 0
+	KeyColumn int `json:"keyColumn,omitempty"`
+	// ValueColumn is the number of the column with the values starting from 0, default is 1
+	ValueColumn *int `json:"valueColumn,omitempty"`
+	// Separator of the columns, default is ","
+	Separator string `json:"separator,omitempty"`
+	// LineSeparator is the separator of the lines, default is a line break
+	LineSeparator string `json:"lineSeparator,omitempty"`
+	// QuoteChar is the character which quotes the values, default is the double quote
+	QuoteChar string `json:"quoteChar,omitempty"`
+	// IgnoreChar is the first character of the lines which are ignored, default is #
+	IgnoreChar string `json:"ignoreChar,omitempty"`
+	// RefreshIntervalSeconds is the interval of the download of the file, default is 60
+	RefreshIntervalSeconds int `json:"refreshIntervalSeconds,omitempty"`
+}
+
+// GraylogLookupCache describes the cache of the values of the lookup table
+type GraylogLookupCache struct {
+	// Name of the cache, it is used to find the cache in Graylog
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	Name string `json:"name"`
+	// Title of the cache, the name is used if it is empty
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Type of the cache: memory - the cache in the memory of Graylog nodes, none - values are not cached
+	// +kubebuilder:validation:Enum=memory;none
+	// +kubebuilder:default=memory
+	Type string `json:"type,omitempty"`
+	// MaxSize is the maximum number of the cached values, default is 1000
+	MaxSize int `json:"maxSize,omitempty"`
+	// ExpireAfterAccessSeconds is the time after the last access when the value is removed from the cache, default is 60
+	ExpireAfterAccessSeconds int `json:"expireAfterAccessSeconds,omitempty"`
+	// ExpireAfterWriteSeconds is the time after the lookup when the value is removed from the cache
+	ExpireAfterWriteSeconds int `json:"expireAfterWriteSeconds,omitempty"`
+}
+
+// GraylogLookupTable describes the lookup table which can be used by lookup functions of the pipeline rules
+type GraylogLookupTable struct {
+	// Name of the lookup table, it is used in the pipeline rules and to find the lookup table in Graylog
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	Name string `json:"name"`
+	// Title of the lookup table, the name is used if it is empty
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// DataAdapter is the name of the data adapter
+	DataAdapter string `json:"dataAdapter"`
+	// Cache is the name of the cache
+	Cache string `json:"cache"`
+	// DefaultValue is returned if the key is not found
+	DefaultValue string `json:"defaultValue,omitempty"`
+}
+
+// GraylogNotification describes the Graylog notification which is sent when the event is raised
+type GraylogNotification struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// +kubebuilder:validation:Enum=http;email;slack
+	Type string `json:"type"`
+	// URL of the HTTP webhook or the Slack incoming webhook
+	URL string `json:"url,omitempty"`
+	// URLSecret is the reference to the key of the Secret with the webhook URL, it has the priority over the URL
+	URLSecret  *v1.SecretKeySelector `json:"urlSecret,omitempty"`
+	Sender     string                `json:"sender,omitempty"`
+	Subject    string                `json:"subject,omitempty"`
+	Recipients []string              `json:"recipients,omitempty"`
+	Channel    string                `json:"channel,omitempty"`
+	// CustomMessage is the template of the email body or the Slack message
+	CustomMessage string `json:"customMessage,omitempty"`
+}
+
+// GraylogEventDefinition describes the Graylog event definition (alert) with filter and aggregation conditions
+type GraylogEventDefinition struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Priority of the event: 1 - low, 2 - normal, 3 - high
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3
+	Priority int `json:"priority,omitempty"`
+	// Streams contains titles of the streams to search the messages in
+	Streams []string `json:"streams,omitempty"`
+	// Query is the search query to filter the messages
+	Query               string `json:"query,omitempty"`
+	SearchWithinMinutes int    `json:"searchWithinMinutes,omitempty"`
+	ExecuteEveryMinutes int    `json:"executeEveryMinutes,omitempty"`
+	// Aggregation is the condition on the filtered messages. If it is not set, every found message raises the event
+	Aggregation *GraylogAggregation `json:"aggregation,omitempty"`
+	// Notifications contains titles of the notifications to send
+	Notifications      []string `json:"notifications,omitempty"`
+	GracePeriodMinutes int      `json:"gracePeriodMinutes,omitempty"`
+	// BacklogSize is the number of messages included into the notification
+	BacklogSize int `json:"backlogSize,omitempty"`
+}
+
+// GraylogAggregation describes the aggregation condition of the event definition
+type GraylogAggregation struct {
+	// +kubebuilder:validation:Enum=count;avg;min;max;sum;stddev;card
+	Function string `json:"function"`
+	// Field is the message field to aggregate, it is not required for the count function
+	Field   string   `json:"field,omitempty"`
+	GroupBy []string `json:"groupBy,omitempty"`
+	// +kubebuilder:validation:Enum=">";">=";"<";"<=";"=="
+	Condition string `json:"condition"`
+	Threshold int    `json:"threshold"`
 }
 
 // GenerateCerts define settings for cert-manager.
@@ -432,11 +904,115 @@ type GenerateCerts struct {
 	Enabled    bool   `json:"enabled"`
 }
 
-// MongoDBUpgrade is used for the sequential MongoDB upgrading from 3.6 to 5.0
+// GraylogMongoDB configures MongoDB which is used by Graylog instead of the container in the Graylog pod.
+// It is required if Graylog has more than one replica
+type GraylogMongoDB struct {
+	// URISecret is the key of the Secret with the connection string of the external MongoDB replica set
+	URISecret *v1.SecretKeySelector `json:"uriSecret,omitempty"`
+	// ReplicaSet deploys the MongoDB replica set managed by the operator
+	ReplicaSet *MongoDBReplicaSet `json:"replicaSet,omitempty"`
+}
+
+// MongoDBReplicaSet is the MongoDB replica set deployed by the operator with the image from mongoDBImage
+type MongoDBReplicaSet struct {
+	Install bool `json:"install"`
+	// Replicas is the number of members of the replica set. Default: 3
+	Replicas         int    `json:"replicas,omitempty"`
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// StorageSize is the size of the volume of each member. Default: 10Gi
+	StorageSize string `json:"storageSize,omitempty"`
+}
+
+// GraylogStorage contains settings of the volumes which are created per Graylog node if Graylog has more than one replica
+type GraylogStorage struct {
+	ClassName string `json:"className,omitempty"`
+	// Size of the volume with the journal and the data of the node. Default: 10Gi
+	Size string `json:"size,omitempty"`
+}
+
+// GraylogBackup configures backups of the Graylog configuration stored in MongoDB with mongodump.
+// Backups are stored on the persistent volume or in the S3-compatible storage
+type GraylogBackup struct {
+	Install bool `json:"install"`
+	// Schedule of backups in the Cron format. Default: "0 0 * * *"
+	Schedule string `json:"schedule,omitempty"`
+	// RetentionDays is the number of days backups are kept. Default: 7
+	RetentionDays int `json:"retentionDays,omitempty"`
+	// Image with mongodump and mongorestore. Default: mongoDBImage
+	Image string `json:"image,omitempty"`
+	// PersistentVolumeClaim is the name of the existing PVC to store backups on. Can't be used together with S3
+	PersistentVolumeClaim string                   `json:"persistentVolumeClaim,omitempty"`
+	S3                    *BackupS3                `json:"s3,omitempty"`
+	Restore               *GraylogRestore          `json:"restore,omitempty"`
+	Resources             *v1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// BackupS3 is the bucket of the S3-compatible storage, e.g. AWS S3 or MinIO
+type BackupS3 struct {
+	Endpoint string `json:"endpoint"`
+	Bucket   string `json:"bucket"`
+	// Prefix is the path in the bucket where backups are stored
+	Prefix string `json:"prefix,omitempty"`
+	// Image with the MinIO client which uploads and downloads backups
+	Image           string                `json:"image"`
+	AccessKeySecret *v1.SecretKeySelector `json:"accessKeySecret"`
+	SecretKeySecret *v1.SecretKeySelector `json:"secretKeySecret"`
+}
+
+// GraylogRestore restores the backup before Graylog starts. The backup is restored once for each value of Backup
+type GraylogRestore struct {
+	// Backup is the name of the backup archive, e.g. graylog-20240101-000000.archive.gz, or "latest" for the newest backup
+	Backup string `json:"backup"`
+}
+
+// GraylogCredentialsRotation configures the rotation of Graylog credentials requested by CredentialsRotationAnnotation.
+// New passwords of the admin user and the users created by the operator are generated into the Secret graylogSecretName
+type GraylogCredentialsRotation struct {
+	// PasswordLength is the length of the generated passwords. Default: 24
+	PasswordLength int `json:"passwordLength,omitempty"`
+	// Secrets of agents, auth-proxy and other clients of Graylog which are updated with the new credentials
+	Secrets []RotatedSecret `json:"secrets,omitempty"`
+}
+
+// RotatedSecret is the Secret in the namespace of the LoggingService which keeps credentials of the Graylog user
+type RotatedSecret struct {
+	Name string `json:"name"`
+	// User is the name of the Graylog user, e.g. operator or graylog_api_th_user. Default: the admin user
+	User string `json:"user,omitempty"`
+	// UserKey is the key of the user name in the Secret. The user name isn't written if it is empty
+	UserKey string `json:"userKey,omitempty"`
+	// PasswordKey is the key of the password in the Secret
+	PasswordKey string `json:"passwordKey"`
+}
+
+// MongoDBUpgrade is used for the sequential MongoDB upgrading through the featureCompatibilityVersion of each step.
+// The images of versions 4.0, 4.2 and 4.4 are used for the upgrade from 3.6 to 5.0 if Steps are not specified
 type MongoDBUpgrade struct {
-	MongoDBImage40 string `json:"mongoDBImage40"`
-	MongoDBImage42 string `json:"mongoDBImage42"`
-	MongoDBImage44 string `json:"mongoDBImage44"`
+	MongoDBImage40 string `json:"mongoDBImage40,omitempty"`
+	MongoDBImage42 string `json:"mongoDBImage42,omitempty"`
+	MongoDBImage44 string `json:"mongoDBImage44,omitempty"`
+	// Steps are the ordered versions MongoDB is upgraded through, e.g. 4.0, 4.2, 4.4, 5.0, 6.0, 7.0
+	Steps []MongoDBUpgradeStep `json:"steps,omitempty"`
+}
+
+// MongoDBUpgradeStep sets the featureCompatibilityVersion of the database with mongod of the image
+type MongoDBUpgradeStep struct {
+	// Version is the featureCompatibilityVersion set by the step, e.g. "6.0"
+	Version string `json:"version"`
+	Image   string `json:"image"`
+}
+
+// UpgradeSteps returns the steps of the upgrade. The last of the legacy steps uses the image of the current MongoDB
+func (in *MongoDBUpgrade) UpgradeSteps(mongoDBImage string) []MongoDBUpgradeStep {
+	if len(in.Steps) > 0 {
+		return in.Steps
+	}
+	return []MongoDBUpgradeStep{
+		{Version: "4.0", Image: in.MongoDBImage40},
+		{Version: "4.2", Image: in.MongoDBImage42},
+		{Version: "4.4", Image: in.MongoDBImage44},
+		{Version: "5.0", Image: mongoDBImage},
+	}
 }
 
 type LoggingServiceParameters struct {
@@ -489,6 +1065,26 @@ type LokiFluentd struct {
 	ExtraParams   string          `json:"extraParams,omitempty"`
 }
 
+const (
+	// DefaultOCIPollInterval is the interval of the checks of the OCI artifacts if ociPollInterval is not set
+	DefaultOCIPollInterval = 10 * time.Minute
+
+	// PausedAnnotation freezes the reconciliation of the LoggingService.
+	// Possible values are "true" or a comma-separated list of the components below.
+	PausedAnnotation = "logging.qubership.org/paused"
+
+	// CredentialsRotationAnnotation requests the rotation of Graylog credentials. The value is any string,
+	// e.g. the current time, the rotation is run once for each new value.
+	CredentialsRotationAnnotation = "logging.qubership.org/rotate-credentials"
+
+	PausedGraylog             = "graylog"
+	PausedGraylogContent      = "graylog-content"
+	PausedFluentd             = "fluentd"
+	PausedFluentbit           = "fluentbit"
+	PausedFluentbitAggregator = "fluentbit-aggregator"
+	PausedEventsReader        = "events-reader"
+)
+
 func (in *LoggingService) ToParams() LoggingServiceParameters {
 	return LoggingServiceParameters{
 		Values: in.Spec,
@@ -502,6 +1098,49 @@ func init() {
 	SchemeBuilder.Register(&LoggingService{}, &LoggingServiceList{})
 }
 
+// IsPaused returns true if the reconciliation of the component is paused by the PausedAnnotation.
+// The annotation value "true" pauses all components, otherwise it contains a comma-separated list of components.
+// Pausing of Graylog also pauses the management of the Graylog content.
+func (in *LoggingService) IsPaused(component string) bool {
+	value := strings.TrimSpace(in.GetAnnotations()[PausedAnnotation])
+	if value == "" {
+		return false
+	}
+	if strings.EqualFold(value, "true") {
+		return true
+	}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if strings.EqualFold(item, component) || (component == PausedGraylogContent && strings.EqualFold(item, PausedGraylog)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CredentialsRotationTrigger returns the value of the CredentialsRotationAnnotation
+// if the rotation with it is not completed yet, otherwise it returns an empty string
+func (in *LoggingService) CredentialsRotationTrigger() string {
+	trigger := strings.TrimSpace(in.GetAnnotations()[CredentialsRotationAnnotation])
+	if in.Status.CredentialsRotation != nil && in.Status.CredentialsRotation.Trigger == trigger {
+		return ""
+	}
+	return trigger
+}
+
+// IsPausedAll returns true if the reconciliation of all components is paused
+func (in *LoggingService) IsPausedAll() bool {
+	return strings.EqualFold(strings.TrimSpace(in.GetAnnotations()[PausedAnnotation]), "true")
+}
+
+func (in *LoggingService) IsDeleteKubernetes() bool {
+	return in.Spec.DeletionPolicy == "DeleteKubernetesOnly" || in.IsDeleteAll()
+}
+
+func (in *LoggingService) IsDeleteAll() bool {
+	return in.Spec.DeletionPolicy == "DeleteAll"
+}
+
 func (in *Graylog) IsForceUpdate() bool {
 	return in.ContentDeployPolicy == "force-update"
 }
@@ -514,6 +1153,108 @@ func (in *Graylog) IsInstall() bool {
 	return in != nil
 }
 
+// IsHA returns true if Graylog is deployed as the cluster of several nodes
+func (in *Graylog) IsHA() bool {
+	return in.Replicas > 1
+}
+
+// IsExternalMongoDB returns true if Graylog uses MongoDB which is not deployed by the operator
+func (in *Graylog) IsExternalMongoDB() bool {
+	return in.MongoDB != nil && in.MongoDB.URISecret != nil
+}
+
+// IsManagedMongoDB returns true if Graylog uses the MongoDB replica set deployed by the operator
+func (in *Graylog) IsManagedMongoDB() bool {
+	return in.MongoDB != nil && in.MongoDB.ReplicaSet != nil && in.MongoDB.ReplicaSet.Install
+}
+
+// IsEmbeddedMongoDB returns true if MongoDB is the container in the Graylog pod
+func (in *Graylog) IsEmbeddedMongoDB() bool {
+	return !in.IsExternalMongoDB() && !in.IsManagedMongoDB()
+}
+
+// ManagedMongoDBURI returns the connection string of the MongoDB replica set deployed by the operator
+func (in *Graylog) ManagedMongoDBURI(namespace string) string {
+	replicas := in.MongoDB.ReplicaSet.Replicas
+	if replicas == 0 {
+		replicas = 3
+	}
+	hosts := make([]string, 0, replicas)
+	for i := 0; i < replicas; i++ {
+		hosts = append(hosts, fmt.Sprintf("graylog-mongo-%d.graylog-mongo.%s.svc:27017", i, namespace))
+	}
+	return "mongodb://" + strings.Join(hosts, ",") + "/graylog?replicaSet=rs0"
+}
+
+// IsBackupEnabled returns true if backups of MongoDB are configured
+func (in *Graylog) IsBackupEnabled() bool {
+	return in.Backup != nil && in.Backup.Install
+}
+
+// RotationPasswordLength returns the length of the passwords generated by the rotation of credentials
+func (in *Graylog) RotationPasswordLength() int {
+	if in.CredentialsRotation != nil && in.CredentialsRotation.PasswordLength > 0 {
+		return in.CredentialsRotation.PasswordLength
+	}
+	return 24
+}
+
+// contentSources returns the sources of the content packs and the saved searches
+func (in *Graylog) contentSources() []*ContentSource {
+	sources := make([]*ContentSource, 0, len(in.ContentPacks)+len(in.SavedSearches))
+	for _, contentPack := range in.ContentPacks {
+		if contentPack != nil {
+			sources = append(sources, &contentPack.ContentSource)
+		}
+	}
+	for i := range in.SavedSearches {
+		sources = append(sources, &in.SavedSearches[i].ContentSource)
+	}
+	return sources
+}
+
+// UsesContentObject returns true if the content packs or the saved searches are read
+// from the ConfigMap or the Secret with the name
+func (in *Graylog) UsesContentObject(kind string, name string) bool {
+	for _, source := range in.contentSources() {
+		switch {
+		case kind == "ConfigMap" && source.ConfigMap != nil && source.ConfigMap.Name == name:
+			return true
+		case kind == "Secret" && source.Secret != nil && source.Secret.Name == name:
+			return true
+		}
+	}
+	return false
+}
+
+// UsesOCI returns true if the content packs or the saved searches are pulled from the OCI artifacts
+func (in *Graylog) UsesOCI() bool {
+	for _, source := range in.contentSources() {
+		if source.OCI != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetOCIPollInterval returns the interval of the checks of the OCI artifacts
+func (in *Graylog) GetOCIPollInterval() time.Duration {
+	if in.OCIPollInterval != nil && in.OCIPollInterval.Duration > 0 {
+		return in.OCIPollInterval.Duration
+	}
+	return DefaultOCIPollInterval
+}
+
+// IsSet returns true if any of the sources is specified
+func (in *ContentSource) IsSet() bool {
+	return in.ConfigMap != nil || in.Secret != nil || len(in.Inline) != 0 || in.OCI != ""
+}
+
+// IsRestoreRequested returns true if the backup must be restored before Graylog starts
+func (in *Graylog) IsRestoreRequested() bool {
+	return in.IsBackupEnabled() && in.Backup.Restore != nil && in.Backup.Restore.Backup != ""
+}
+
 func (in *Fluentd) IsInstall() bool {
 	return in != nil
 }
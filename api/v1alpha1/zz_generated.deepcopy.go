@@ -21,8 +21,9 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -30,17 +31,17 @@ func (in *Auth) DeepCopyInto(out *Auth) {
 	*out = *in
 	if in.Token != nil {
 		in, out := &in.Token, &out.Token
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.User != nil {
 		in, out := &in.User, &out.User
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Password != nil {
 		in, out := &in.Password, &out.Password
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -60,12 +61,12 @@ func (in *AuthProxy) DeepCopyInto(out *AuthProxy) {
 	*out = *in
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.BindPasswordSecret != nil {
 		in, out := &in.BindPasswordSecret, &out.BindPasswordSecret
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	out.CA = in.CA
@@ -83,6 +84,31 @@ func (in *AuthProxy) DeepCopy() *AuthProxy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupS3) DeepCopyInto(out *BackupS3) {
+	*out = *in
+	if in.AccessKeySecret != nil {
+		in, out := &in.AccessKeySecret, &out.AccessKeySecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeySecret != nil {
+		in, out := &in.SecretKeySecret, &out.SecretKeySecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupS3.
+func (in *BackupS3) DeepCopy() *BackupS3 {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupS3)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CA) DeepCopyInto(out *CA) {
 	*out = *in
@@ -148,12 +174,12 @@ func (in *CloudEventsReader) DeepCopyInto(out *CloudEventsReader) {
 	*out = *in
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Labels != nil {
@@ -192,7 +218,7 @@ func (in *ConfigmapReload) DeepCopyInto(out *ConfigmapReload) {
 	*out = *in
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -207,6 +233,21 @@ func (in *ConfigmapReload) DeepCopy() *ConfigmapReload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContentObjectReference) DeepCopyInto(out *ContentObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentObjectReference.
+func (in *ContentObjectReference) DeepCopy() *ContentObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ContentObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContentPackPathHTTPConfig) DeepCopyInto(out *ContentPackPathHTTPConfig) {
 	*out = *in
@@ -215,6 +256,7 @@ func (in *ContentPackPathHTTPConfig) DeepCopyInto(out *ContentPackPathHTTPConfig
 		*out = new(HTTPConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	in.ContentSource.DeepCopyInto(&out.ContentSource)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentPackPathHTTPConfig.
@@ -227,17 +269,64 @@ func (in *ContentPackPathHTTPConfig) DeepCopy() *ContentPackPathHTTPConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContentPackStatus) DeepCopyInto(out *ContentPackStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentPackStatus.
+func (in *ContentPackStatus) DeepCopy() *ContentPackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ContentPackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContentSource) DeepCopyInto(out *ContentSource) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ContentObjectReference)
+		**out = **in
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(ContentObjectReference)
+		**out = **in
+	}
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentSource.
+func (in *ContentSource) DeepCopy() *ContentSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ContentSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Credentials) DeepCopyInto(out *Credentials) {
 	*out = *in
 	if in.User != nil {
 		in, out := &in.User, &out.User
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Password != nil {
 		in, out := &in.Password, &out.Password
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -252,12 +341,101 @@ func (in *Credentials) DeepCopy() *Credentials {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsRotationStatus) DeepCopyInto(out *CredentialsRotationStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsRotationStatus.
+func (in *CredentialsRotationStatus) DeepCopy() *CredentialsRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalObjectsStatus) DeepCopyInto(out *ExternalObjectsStatus) {
+	*out = *in
+	if in.AuthBackends != nil {
+		in, out := &in.AuthBackends, &out.AuthBackends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterSettings != nil {
+		in, out := &in.ClusterSettings, &out.ClusterSettings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IndexTemplates != nil {
+		in, out := &in.IndexTemplates, &out.IndexTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LifecyclePolicies != nil {
+		in, out := &in.LifecyclePolicies, &out.LifecyclePolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EventDefinitions != nil {
+		in, out := &in.EventDefinitions, &out.EventDefinitions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LookupTables != nil {
+		in, out := &in.LookupTables, &out.LookupTables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LookupCaches != nil {
+		in, out := &in.LookupCaches, &out.LookupCaches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LookupDataAdapters != nil {
+		in, out := &in.LookupDataAdapters, &out.LookupDataAdapters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalObjectsStatus.
+func (in *ExternalObjectsStatus) DeepCopy() *ExternalObjectsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalObjectsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Fluentbit) DeepCopyInto(out *Fluentbit) {
 	*out = *in
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Annotations != nil {
@@ -301,28 +479,28 @@ func (in *Fluentbit) DeepCopyInto(out *Fluentbit) {
 	in.TLS.DeepCopyInto(&out.TLS)
 	if in.AdditionalVolumes != nil {
 		in, out := &in.AdditionalVolumes, &out.AdditionalVolumes
-		*out = make([]v1.Volume, len(*in))
+		*out = make([]corev1.Volume, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.AdditionalVolumeMounts != nil {
 		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
+		*out = make([]corev1.VolumeMount, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Output != nil {
@@ -359,7 +537,7 @@ func (in *FluentbitAggregator) DeepCopyInto(out *FluentbitAggregator) {
 	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Annotations != nil {
@@ -391,14 +569,14 @@ func (in *FluentbitAggregator) DeepCopyInto(out *FluentbitAggregator) {
 	in.TLS.DeepCopyInto(&out.TLS)
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Output != nil {
@@ -472,7 +650,7 @@ func (in *Fluentd) DeepCopyInto(out *Fluentd) {
 	*out = *in
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Annotations != nil {
@@ -504,7 +682,7 @@ func (in *Fluentd) DeepCopyInto(out *Fluentd) {
 	in.TLS.DeepCopyInto(&out.TLS)
 	if in.AdditionalVolumeMounts != nil {
 		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
+		*out = make([]corev1.VolumeMount, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -516,21 +694,21 @@ func (in *Fluentd) DeepCopyInto(out *Fluentd) {
 	}
 	if in.AdditionalVolumes != nil {
 		in, out := &in.AdditionalVolumes, &out.AdditionalVolumes
-		*out = make([]v1.Volume, len(*in))
+		*out = make([]corev1.Volume, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Output != nil {
@@ -619,24 +797,44 @@ func (in *Graylog) DeepCopyInto(out *Graylog) {
 	*out = *in
 	if in.GraylogResources != nil {
 		in, out := &in.GraylogResources, &out.GraylogResources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.MongoResources != nil {
 		in, out := &in.MongoResources, &out.MongoResources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.InitResources != nil {
 		in, out := &in.InitResources, &out.InitResources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.MongoDBUpgrade != nil {
 		in, out := &in.MongoDBUpgrade, &out.MongoDBUpgrade
 		*out = new(MongoDBUpgrade)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MongoDB != nil {
+		in, out := &in.MongoDB, &out.MongoDB
+		*out = new(GraylogMongoDB)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(GraylogStorage)
 		**out = **in
 	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(GraylogBackup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialsRotation != nil {
+		in, out := &in.CredentialsRotation, &out.CredentialsRotation
+		*out = new(GraylogCredentialsRotation)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AuthProxy != nil {
 		in, out := &in.AuthProxy, &out.AuthProxy
 		*out = new(AuthProxy)
@@ -654,7 +852,7 @@ func (in *Graylog) DeepCopyInto(out *Graylog) {
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Annotations != nil {
@@ -682,10 +880,65 @@ func (in *Graylog) DeepCopyInto(out *Graylog) {
 			}
 		}
 	}
+	if in.SavedSearches != nil {
+		in, out := &in.SavedSearches, &out.SavedSearches
+		*out = make([]SavedSearchSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(GraylogAlerts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]GraylogOutput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LookupTables != nil {
+		in, out := &in.LookupTables, &out.LookupTables
+		*out = new(GraylogLookupTables)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]GraylogRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]GraylogUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NamespaceTeams != nil {
+		in, out := &in.NamespaceTeams, &out.NamespaceTeams
+		*out = new(GraylogNamespaceTeams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(GraylogAuthentication)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Streams != nil {
 		in, out := &in.Streams, &out.Streams
 		*out = make([]Stream, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OCIPollInterval != nil {
+		in, out := &in.OCIPollInterval, &out.OCIPollInterval
+		*out = new(v1.Duration)
+		**out = **in
 	}
 }
 
@@ -700,149 +953,796 @@ func (in *Graylog) DeepCopy() *Graylog {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GraylogTLS) DeepCopyInto(out *GraylogTLS) {
+func (in *GraylogAggregation) DeepCopyInto(out *GraylogAggregation) {
 	*out = *in
-	if in.HTTP != nil {
-		in, out := &in.HTTP, &out.HTTP
-		*out = new(HTTPGraylogTLS)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Input != nil {
-		in, out := &in.Input, &out.Input
-		*out = new(InputGraylogTLS)
-		(*in).DeepCopyInto(*out)
+	if in.GroupBy != nil {
+		in, out := &in.GroupBy, &out.GroupBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogTLS.
-func (in *GraylogTLS) DeepCopy() *GraylogTLS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogAggregation.
+func (in *GraylogAggregation) DeepCopy() *GraylogAggregation {
 	if in == nil {
 		return nil
 	}
-	out := new(GraylogTLS)
+	out := new(GraylogAggregation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HTTPConfig) DeepCopyInto(out *HTTPConfig) {
+func (in *GraylogAlerts) DeepCopyInto(out *GraylogAlerts) {
 	*out = *in
-	if in.Credentials != nil {
-		in, out := &in.Credentials, &out.Credentials
-		*out = new(Credentials)
-		(*in).DeepCopyInto(*out)
+	if in.DefaultNotifications != nil {
+		in, out := &in.DefaultNotifications, &out.DefaultNotifications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.TLSConfig != nil {
-		in, out := &in.TLSConfig, &out.TLSConfig
-		*out = new(TLSConfig)
-		(*in).DeepCopyInto(*out)
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]GraylogNotification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EventDefinitions != nil {
+		in, out := &in.EventDefinitions, &out.EventDefinitions
+		*out = make([]GraylogEventDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPConfig.
-func (in *HTTPConfig) DeepCopy() *HTTPConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogAlerts.
+func (in *GraylogAlerts) DeepCopy() *GraylogAlerts {
 	if in == nil {
 		return nil
 	}
-	out := new(HTTPConfig)
+	out := new(GraylogAlerts)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HTTPGraylogTLS) DeepCopyInto(out *HTTPGraylogTLS) {
+func (in *GraylogAuthentication) DeepCopyInto(out *GraylogAuthentication) {
 	*out = *in
-	if in.GenerateCerts != nil {
-		in, out := &in.GenerateCerts, &out.GenerateCerts
-		*out = new(GenerateCerts)
-		**out = **in
+	if in.DefaultRoles != nil {
+		in, out := &in.DefaultRoles, &out.DefaultRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Cert != nil {
-		in, out := &in.Cert, &out.Cert
-		*out = new(Cert)
-		**out = **in
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(GraylogLDAP)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Key != nil {
-		in, out := &in.Key, &out.Key
-		*out = new(Key)
-		**out = **in
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(GraylogOIDC)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGraylogTLS.
-func (in *HTTPGraylogTLS) DeepCopy() *HTTPGraylogTLS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogAuthentication.
+func (in *GraylogAuthentication) DeepCopy() *GraylogAuthentication {
 	if in == nil {
 		return nil
 	}
-	out := new(HTTPGraylogTLS)
+	out := new(GraylogAuthentication)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InputGraylogTLS) DeepCopyInto(out *InputGraylogTLS) {
+func (in *GraylogBackup) DeepCopyInto(out *GraylogBackup) {
 	*out = *in
-	in.TLS.DeepCopyInto(&out.TLS)
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(BackupS3)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(GraylogRestore)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputGraylogTLS.
-func (in *InputGraylogTLS) DeepCopy() *InputGraylogTLS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogBackup.
+func (in *GraylogBackup) DeepCopy() *GraylogBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(InputGraylogTLS)
+	out := new(GraylogBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Key) DeepCopyInto(out *Key) {
+func (in *GraylogCredentialsRotation) DeepCopyInto(out *GraylogCredentialsRotation) {
 	*out = *in
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]RotatedSecret, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Key.
-func (in *Key) DeepCopy() *Key {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogCredentialsRotation.
+func (in *GraylogCredentialsRotation) DeepCopy() *GraylogCredentialsRotation {
 	if in == nil {
 		return nil
 	}
-	out := new(Key)
+	out := new(GraylogCredentialsRotation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoggingService) DeepCopyInto(out *LoggingService) {
+func (in *GraylogEventDefinition) DeepCopyInto(out *GraylogEventDefinition) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Aggregation != nil {
+		in, out := &in.Aggregation, &out.Aggregation
+		*out = new(GraylogAggregation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingService.
-func (in *LoggingService) DeepCopy() *LoggingService {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogEventDefinition.
+func (in *GraylogEventDefinition) DeepCopy() *GraylogEventDefinition {
 	if in == nil {
 		return nil
 	}
-	out := new(LoggingService)
+	out := new(GraylogEventDefinition)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LoggingService) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoggingServiceCondition) DeepCopyInto(out *LoggingServiceCondition) {
+func (in *GraylogLDAP) DeepCopyInto(out *GraylogLDAP) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingServiceCondition.
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SystemUserPasswordSecret != nil {
+		in, out := &in.SystemUserPasswordSecret, &out.SystemUserPasswordSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EmailAttributes != nil {
+		in, out := &in.EmailAttributes, &out.EmailAttributes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogLDAP.
+func (in *GraylogLDAP) DeepCopy() *GraylogLDAP {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogLDAP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogLookupCSVAdapter) DeepCopyInto(out *GraylogLookupCSVAdapter) {
+	*out = *in
+	in.ConfigMap.DeepCopyInto(&out.ConfigMap)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogLookupCSVAdapter.
+func (in *GraylogLookupCSVAdapter) DeepCopy() *GraylogLookupCSVAdapter {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogLookupCSVAdapter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogLookupCache) DeepCopyInto(out *GraylogLookupCache) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogLookupCache.
+func (in *GraylogLookupCache) DeepCopy() *GraylogLookupCache {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogLookupCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogLookupDSVHTTPAdapter) DeepCopyInto(out *GraylogLookupDSVHTTPAdapter) {
+	*out = *in
+	if in.ValueColumn != nil {
+		in, out := &in.ValueColumn, &out.ValueColumn
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogLookupDSVHTTPAdapter.
+func (in *GraylogLookupDSVHTTPAdapter) DeepCopy() *GraylogLookupDSVHTTPAdapter {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogLookupDSVHTTPAdapter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogLookupDataAdapter) DeepCopyInto(out *GraylogLookupDataAdapter) {
+	*out = *in
+	if in.CSV != nil {
+		in, out := &in.CSV, &out.CSV
+		*out = new(GraylogLookupCSVAdapter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DSVHTTP != nil {
+		in, out := &in.DSVHTTP, &out.DSVHTTP
+		*out = new(GraylogLookupDSVHTTPAdapter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogLookupDataAdapter.
+func (in *GraylogLookupDataAdapter) DeepCopy() *GraylogLookupDataAdapter {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogLookupDataAdapter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogLookupTable) DeepCopyInto(out *GraylogLookupTable) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogLookupTable.
+func (in *GraylogLookupTable) DeepCopy() *GraylogLookupTable {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogLookupTable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogLookupTables) DeepCopyInto(out *GraylogLookupTables) {
+	*out = *in
+	if in.DataAdapters != nil {
+		in, out := &in.DataAdapters, &out.DataAdapters
+		*out = make([]GraylogLookupDataAdapter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Caches != nil {
+		in, out := &in.Caches, &out.Caches
+		*out = make([]GraylogLookupCache, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]GraylogLookupTable, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogLookupTables.
+func (in *GraylogLookupTables) DeepCopy() *GraylogLookupTables {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogLookupTables)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogMongoDB) DeepCopyInto(out *GraylogMongoDB) {
+	*out = *in
+	if in.URISecret != nil {
+		in, out := &in.URISecret, &out.URISecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicaSet != nil {
+		in, out := &in.ReplicaSet, &out.ReplicaSet
+		*out = new(MongoDBReplicaSet)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogMongoDB.
+func (in *GraylogMongoDB) DeepCopy() *GraylogMongoDB {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogMongoDB)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogNamespaceTeams) DeepCopyInto(out *GraylogNamespaceTeams) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogNamespaceTeams.
+func (in *GraylogNamespaceTeams) DeepCopy() *GraylogNamespaceTeams {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogNamespaceTeams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogNotification) DeepCopyInto(out *GraylogNotification) {
+	*out = *in
+	if in.URLSecret != nil {
+		in, out := &in.URLSecret, &out.URLSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Recipients != nil {
+		in, out := &in.Recipients, &out.Recipients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogNotification.
+func (in *GraylogNotification) DeepCopy() *GraylogNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogOIDC) DeepCopyInto(out *GraylogOIDC) {
+	*out = *in
+	if in.ClientSecretSecret != nil {
+		in, out := &in.ClientSecretSecret, &out.ClientSecretSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogOIDC.
+func (in *GraylogOIDC) DeepCopy() *GraylogOIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogOIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogOutput) DeepCopyInto(out *GraylogOutput) {
+	*out = *in
+	in.Configuration.DeepCopyInto(&out.Configuration)
+	if in.SecretRefs != nil {
+		in, out := &in.SecretRefs, &out.SecretRefs
+		*out = make(map[string]corev1.SecretKeySelector, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogOutput.
+func (in *GraylogOutput) DeepCopy() *GraylogOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogRestore) DeepCopyInto(out *GraylogRestore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogRestore.
+func (in *GraylogRestore) DeepCopy() *GraylogRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogRole) DeepCopyInto(out *GraylogRole) {
+	*out = *in
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Dashboards != nil {
+		in, out := &in.Dashboards, &out.Dashboards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogRole.
+func (in *GraylogRole) DeepCopy() *GraylogRole {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogStorage) DeepCopyInto(out *GraylogStorage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogStorage.
+func (in *GraylogStorage) DeepCopy() *GraylogStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogTLS) DeepCopyInto(out *GraylogTLS) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPGraylogTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Input != nil {
+		in, out := &in.Input, &out.Input
+		*out = new(InputGraylogTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogTLS.
+func (in *GraylogTLS) DeepCopy() *GraylogTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogUser) DeepCopyInto(out *GraylogUser) {
+	*out = *in
+	in.PasswordSecret.DeepCopyInto(&out.PasswordSecret)
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogUser.
+func (in *GraylogUser) DeepCopy() *GraylogUser {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogView) DeepCopyInto(out *GraylogView) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogView.
+func (in *GraylogView) DeepCopy() *GraylogView {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogView)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GraylogView) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogViewList) DeepCopyInto(out *GraylogViewList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GraylogView, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogViewList.
+func (in *GraylogViewList) DeepCopy() *GraylogViewList {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogViewList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GraylogViewList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogViewSpec) DeepCopyInto(out *GraylogViewSpec) {
+	*out = *in
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Search.DeepCopyInto(&out.Search)
+	in.State.DeepCopyInto(&out.State)
+	if in.DriftCheckInterval != nil {
+		in, out := &in.DriftCheckInterval, &out.DriftCheckInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogViewSpec.
+func (in *GraylogViewSpec) DeepCopy() *GraylogViewSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogViewSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraylogViewStatus) DeepCopyInto(out *GraylogViewStatus) {
+	*out = *in
+	if in.LastDriftTime != nil {
+		in, out := &in.LastDriftTime, &out.LastDriftTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraylogViewStatus.
+func (in *GraylogViewStatus) DeepCopy() *GraylogViewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GraylogViewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPConfig) DeepCopyInto(out *HTTPConfig) {
+	*out = *in
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(Credentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPConfig.
+func (in *HTTPConfig) DeepCopy() *HTTPConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPGraylogTLS) DeepCopyInto(out *HTTPGraylogTLS) {
+	*out = *in
+	if in.GenerateCerts != nil {
+		in, out := &in.GenerateCerts, &out.GenerateCerts
+		*out = new(GenerateCerts)
+		**out = **in
+	}
+	if in.Cert != nil {
+		in, out := &in.Cert, &out.Cert
+		*out = new(Cert)
+		**out = **in
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(Key)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGraylogTLS.
+func (in *HTTPGraylogTLS) DeepCopy() *HTTPGraylogTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPGraylogTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InputGraylogTLS) DeepCopyInto(out *InputGraylogTLS) {
+	*out = *in
+	in.TLS.DeepCopyInto(&out.TLS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputGraylogTLS.
+func (in *InputGraylogTLS) DeepCopy() *InputGraylogTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(InputGraylogTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Key) DeepCopyInto(out *Key) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Key.
+func (in *Key) DeepCopy() *Key {
+	if in == nil {
+		return nil
+	}
+	out := new(Key)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingService) DeepCopyInto(out *LoggingService) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingService.
+func (in *LoggingService) DeepCopy() *LoggingService {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoggingService) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingServiceCondition) DeepCopyInto(out *LoggingServiceCondition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingServiceCondition.
 func (in *LoggingServiceCondition) DeepCopy() *LoggingServiceCondition {
 	if in == nil {
 		return nil
@@ -949,6 +1849,26 @@ func (in *LoggingServiceStatus) DeepCopyInto(out *LoggingServiceStatus) {
 		*out = make([]LoggingServiceCondition, len(*in))
 		copy(*out, *in)
 	}
+	if in.MongoDBUpgrade != nil {
+		in, out := &in.MongoDBUpgrade, &out.MongoDBUpgrade
+		*out = new(MongoDBUpgradeStatus)
+		**out = **in
+	}
+	if in.CredentialsRotation != nil {
+		in, out := &in.CredentialsRotation, &out.CredentialsRotation
+		*out = new(CredentialsRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContentPacks != nil {
+		in, out := &in.ContentPacks, &out.ContentPacks
+		*out = make([]ContentPackStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalObjects != nil {
+		in, out := &in.ExternalObjects, &out.ExternalObjects
+		*out = new(ExternalObjectsStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingServiceStatus.
@@ -1011,9 +1931,29 @@ func (in *LokiFluentd) DeepCopy() *LokiFluentd {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBReplicaSet) DeepCopyInto(out *MongoDBReplicaSet) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBReplicaSet.
+func (in *MongoDBReplicaSet) DeepCopy() *MongoDBReplicaSet {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBReplicaSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBUpgrade) DeepCopyInto(out *MongoDBUpgrade) {
 	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]MongoDBUpgradeStep, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBUpgrade.
@@ -1026,12 +1966,42 @@ func (in *MongoDBUpgrade) DeepCopy() *MongoDBUpgrade {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBUpgradeStatus) DeepCopyInto(out *MongoDBUpgradeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBUpgradeStatus.
+func (in *MongoDBUpgradeStatus) DeepCopy() *MongoDBUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBUpgradeStep) DeepCopyInto(out *MongoDBUpgradeStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBUpgradeStep.
+func (in *MongoDBUpgradeStep) DeepCopy() *MongoDBUpgradeStep {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBUpgradeStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitoringAgentLoggingPlugin) DeepCopyInto(out *MonitoringAgentLoggingPlugin) {
 	*out = *in
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Annotations != nil {
@@ -1068,6 +2038,27 @@ func (in *OpenSearch) DeepCopyInto(out *OpenSearch) {
 		*out = new(HTTPConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]OpenSearchIndexTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]OpenSearchPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClusterSettings != nil {
+		in, out := &in.ClusterSettings, &out.ClusterSettings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenSearch.
@@ -1080,6 +2071,65 @@ func (in *OpenSearch) DeepCopy() *OpenSearch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenSearchIndexTemplate) DeepCopyInto(out *OpenSearchIndexTemplate) {
+	*out = *in
+	if in.IndexPatterns != nil {
+		in, out := &in.IndexPatterns, &out.IndexPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FieldTypes != nil {
+		in, out := &in.FieldTypes, &out.FieldTypes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenSearchIndexTemplate.
+func (in *OpenSearchIndexTemplate) DeepCopy() *OpenSearchIndexTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenSearchIndexTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenSearchPolicy) DeepCopyInto(out *OpenSearchPolicy) {
+	*out = *in
+	if in.IndexPatterns != nil {
+		in, out := &in.IndexPatterns, &out.IndexPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WarmReplicas != nil {
+		in, out := &in.WarmReplicas, &out.WarmReplicas
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenSearchPolicy.
+func (in *OpenSearchPolicy) DeepCopy() *OpenSearchPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenSearchPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OutputFluentbit) DeepCopyInto(out *OutputFluentbit) {
 	*out = *in
@@ -1135,9 +2185,62 @@ func (in *Release) DeepCopy() *Release {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotatedSecret) DeepCopyInto(out *RotatedSecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotatedSecret.
+func (in *RotatedSecret) DeepCopy() *RotatedSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(RotatedSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavedSearchSource) DeepCopyInto(out *SavedSearchSource) {
+	*out = *in
+	if in.HTTPConfig != nil {
+		in, out := &in.HTTPConfig, &out.HTTPConfig
+		*out = new(HTTPConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ContentSource.DeepCopyInto(&out.ContentSource)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedSearchSource.
+func (in *SavedSearchSource) DeepCopy() *SavedSearchSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SavedSearchSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Stream) DeepCopyInto(out *Stream) {
 	*out = *in
+	if in.FieldTypes != nil {
+		in, out := &in.FieldTypes, &out.FieldTypes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]StreamRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoveFromDefault != nil {
+		in, out := &in.RemoveFromDefault, &out.RemoveFromDefault
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Stream.
@@ -1150,6 +2253,21 @@ func (in *Stream) DeepCopy() *Stream {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamRule) DeepCopyInto(out *StreamRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamRule.
+func (in *StreamRule) DeepCopy() *StreamRule {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
@@ -1176,17 +2294,17 @@ func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
 	if in.CA != nil {
 		in, out := &in.CA, &out.CA
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Cert != nil {
 		in, out := &in.Cert, &out.Cert
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Key != nil {
 		in, out := &in.Key, &out.Key
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
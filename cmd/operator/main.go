@@ -66,6 +66,14 @@ func printVersion() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == renderCommand {
+		if err := render(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var pprofAddr string
 	var pprofEnabled bool
 
@@ -94,18 +102,35 @@ func main() {
 		os.Exit(1)
 	}
 
+	recorder, err := utils.NewKubernetesEventRecorder(mgr.GetConfig(), mgr.GetScheme(), namespace)
+	if err != nil {
+		logger.Error(err, "unable to create event recorder")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.LoggingServiceReconciler{
 		Client:                   mgr.GetClient(),
 		Scheme:                   mgr.GetScheme(),
 		Log:                      utils.Logger("controller-loggingservice"),
 		Config:                   mgr.GetConfig(),
 		TimeoutOnFailedReconcile: controllers.InitialTimeoutOnFailedReconcile,
+		Recorder:                 recorder,
 		DynamicParameters:        utils.DynamicParameters{ContainerRuntimeType: ""},
 	}).SetupWithManager(mgr); err != nil {
 		logger.Error(err, "unable to create controller", "controller", "LoggingService")
 		os.Exit(1)
 	}
 
+	if err = (&controllers.GraylogViewReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      utils.Logger("controller-graylogview"),
+		Recorder: recorder,
+	}).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create controller", "controller", "GraylogView")
+		os.Exit(1)
+	}
+
 	skipMetricsService, found := os.LookupEnv("SKIP_METRICS_SERVICE")
 	if !(found && skipMetricsService == "true") {
 		// Add to the below struct any other metrics ports you want to expose.
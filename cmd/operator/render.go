@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	"github.com/Netcracker/qubership-logging-operator/controllers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	renderCommand    = "render"
+	defaultNamespace = "logging"
+)
+
+// render builds manifests and configs of all Logging components from the LoggingService custom resource
+// without the cluster and writes them to stdout or to the directory, one file per resource
+func render(args []string) error {
+	flags := flag.NewFlagSet(renderCommand, flag.ContinueOnError)
+	file := flags.String("f", "", "Path to the file with LoggingService custom resource, '-' to read it from stdin.")
+	outputDir := flags.String("o", "", "Directory to write manifests, one file per resource. If empty, manifests are written to stdout.")
+	namespace := flags.String("n", "", "Namespace of the LoggingService if it is not set in the custom resource.")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s -f <cr.yaml> [-o <directory>] [-n <namespace>]\n", filepath.Base(os.Args[0]), renderCommand)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		flags.Usage()
+		return fmt.Errorf("path to the custom resource is required")
+	}
+
+	cr, err := readLoggingService(*file)
+	if err != nil {
+		return err
+	}
+	if cr.GetNamespace() == "" {
+		cr.SetNamespace(*namespace)
+	}
+	if cr.GetNamespace() == "" {
+		cr.SetNamespace(defaultNamespace)
+	}
+
+	objects, err := controllers.Render(cr)
+	if err != nil {
+		return err
+	}
+	for _, o := range objects {
+		gvk, err := apiutil.GVKForObject(o, scheme)
+		if err != nil {
+			return err
+		}
+		o.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+
+	if *outputDir == "" {
+		return writeManifests(os.Stdout, objects)
+	}
+	if err = os.MkdirAll(*outputDir, 0755); err != nil {
+		return err
+	}
+	for _, o := range objects {
+		path := filepath.Join(*outputDir, strings.ToLower(fmt.Sprintf("%s-%s.yaml", o.GetObjectKind().GroupVersionKind().Kind, o.GetName())))
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = writeManifests(f, []client.Object{o})
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLoggingService(path string) (*loggingService.LoggingService, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cr := &loggingService.LoggingService{}
+	if err = yaml.UnmarshalStrict(data, cr); err != nil {
+		return nil, fmt.Errorf("can't parse LoggingService from %s: %w", path, err)
+	}
+	return cr, nil
+}
+
+// writeManifests writes resources as YAML documents separated by '---'
+func writeManifests(w io.Writer, objects []client.Object) error {
+	for _, o := range objects {
+		data, err := yaml.Marshal(o)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //go:embed  assets/*.yaml
@@ -70,3 +71,16 @@ func eventsReaderService(cr *loggingService.LoggingService) (*corev1.Service, er
 
 	return &service, nil
 }
+
+// Manifests returns all Kubernetes resources of the component built from the custom resource
+func Manifests(cr *loggingService.LoggingService) ([]client.Object, error) {
+	deployment, err := eventsReaderDeployment(cr)
+	if err != nil {
+		return nil, err
+	}
+	service, err := eventsReaderService(cr)
+	if err != nil {
+		return nil, err
+	}
+	return []client.Object{deployment, service}, nil
+}
@@ -12,13 +12,14 @@ type EventsReaderReconciler struct {
 	ComponentList *[]util.Component
 }
 
-func NewEventsReaderReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, pendingComponents *[]util.Component) EventsReaderReconciler {
+func NewEventsReaderReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, recorder util.EventRecorder, pendingComponents *[]util.Component) EventsReaderReconciler {
 	return EventsReaderReconciler{
 		ComponentReconciler: &util.ComponentReconciler{
 			Client:        client,
 			Scheme:        scheme,
 			Log:           util.Logger("events-reader"),
 			StatusUpdater: updater,
+			EventRecorder: recorder,
 		},
 		ComponentList: pendingComponents,
 	}
@@ -49,15 +50,15 @@ func (r *EventsReaderReconciler) Run(cr *loggingService.LoggingService) error {
 		)
 	} else {
 		r.Log.Info("Uninstalling component if exists")
-		r.uninstall(cr)
+		r.Uninstall(cr)
 		r.StatusUpdater.RemoveStatus(util.EventsReaderStatus)
 	}
 	r.Log.Info("Component reconciled")
 	return nil
 }
 
-// uninstall deletes all resources related to the component
-func (r *EventsReaderReconciler) uninstall(cr *loggingService.LoggingService) {
+// Uninstall deletes all resources related to the component
+func (r *EventsReaderReconciler) Uninstall(cr *loggingService.LoggingService) {
 	if err := r.deleteDeployment(cr); err != nil {
 		r.Log.Error(err, "Can not delete Deployment")
 	}
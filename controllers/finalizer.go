@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	events_reader "github.com/Netcracker/qubership-logging-operator/controllers/events-reader"
+	"github.com/Netcracker/qubership-logging-operator/controllers/fluentbit"
+	fluentbit_forwarder_aggregator "github.com/Netcracker/qubership-logging-operator/controllers/fluentbit-forwarder-aggregator"
+	"github.com/Netcracker/qubership-logging-operator/controllers/fluentd"
+	"github.com/Netcracker/qubership-logging-operator/controllers/graylog"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ensureFinalizer adds the finalizer to the LoggingService if the deletion policy requires the cleanup
+// and removes it for the Retain policy, so the deletion of the resource is not blocked without need
+func (r *LoggingServiceReconciler) ensureFinalizer(ctx context.Context, cr *loggingService.LoggingService) error {
+	var isChanged bool
+	if cr.IsDeleteKubernetes() {
+		isChanged = controllerutil.AddFinalizer(cr, util.LoggingServiceFinalizer)
+	} else {
+		isChanged = controllerutil.RemoveFinalizer(cr, util.LoggingServiceFinalizer)
+	}
+	if !isChanged {
+		return nil
+	}
+	return r.Client.Update(ctx, cr)
+}
+
+// reconcileDeletion runs the cleanup according to the deletion policy and removes the finalizer.
+// Kubernetes resources with owner references are deleted by the garbage collector after it.
+func (r *LoggingServiceReconciler) reconcileDeletion(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface, eventRecorder util.EventRecorder) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(cr, util.LoggingServiceFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	policy := cr.Spec.DeletionPolicy
+	if policy == "" {
+		policy = "Retain"
+	}
+	r.Log.Info(fmt.Sprintf("Start cleanup of Logging Service with deletion policy %s", policy))
+	r.StatusUpdater.UpdateStatus(util.CleanupStatus, util.InProgress, false, fmt.Sprintf("Cleanup with deletion policy %s in progress", policy))
+
+	if err := r.cleanup(ctx, cr, clientSet, eventRecorder); err != nil {
+		r.Log.Error(err, "Cleanup of Logging Service is failed")
+		eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Cleanup of Logging Service is failed: %s", err.Error()))
+		r.StatusUpdater.UpdateStatus(util.CleanupStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+
+		result := ctrl.Result{RequeueAfter: r.TimeoutOnFailedReconcile}
+		r.TimeoutOnFailedReconcile = r.TimeoutOnFailedReconcile * 2
+		return result, nil
+	}
+	r.TimeoutOnFailedReconcile = InitialTimeoutOnFailedReconcile
+	r.StatusUpdater.UpdateStatus(util.CleanupStatus, util.Success, true, fmt.Sprintf("Cleanup with deletion policy %s succeeded", policy))
+
+	controllerutil.RemoveFinalizer(cr, util.LoggingServiceFinalizer)
+	if err := r.Client.Update(ctx, cr); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.Log.Info("Cleanup of Logging Service successfully finished")
+	return ctrl.Result{}, nil
+}
+
+// cleanup deletes external objects in Graylog and OpenSearch for the DeleteAll policy
+// and runs uninstall of all components for the DeleteKubernetesOnly and DeleteAll policies
+func (r *LoggingServiceReconciler) cleanup(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface, eventRecorder util.EventRecorder) error {
+	graylogReconciler := graylog.NewGraylogReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder)
+
+	if cr.IsDeleteAll() && cr.Spec.Graylog.IsInstall() {
+		if err := graylogReconciler.DeleteExternalObjects(ctx, cr, clientSet); err != nil {
+			return err
+		}
+		r.StatusUpdater.UpdateStatus(util.CleanupStatus, util.InProgress, false, "Objects in Graylog and OpenSearch are deleted")
+	}
+
+	if cr.IsDeleteKubernetes() {
+		var pendingComponents []util.Component
+
+		graylogReconciler.Uninstall(cr)
+		fluentdReconciler := fluentd.NewFluentdReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents, r.DynamicParameters)
+		fluentdReconciler.Uninstall(cr)
+		fluentbitReconciler := fluentbit.NewFluentbitReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents, r.DynamicParameters)
+		fluentbitReconciler.Uninstall(cr)
+		fluentsReconciler := fluentbit_forwarder_aggregator.NewHAFluentReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents, r.DynamicParameters)
+		fluentsReconciler.Uninstall(cr)
+		eventsReaderReconciler := events_reader.NewEventsReaderReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents)
+		eventsReaderReconciler.Uninstall(cr)
+		r.StatusUpdater.UpdateStatus(util.CleanupStatus, util.InProgress, false, "Kubernetes resources of Logging components are deleted")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package fluentbit_forwarder_aggregator
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,6 +12,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //go:embed  assets/*.yaml
@@ -252,3 +254,42 @@ func aggregatorService(cr *loggingService.LoggingService) (*corev1.Service, erro
 	service.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(cr.Spec.Fluentbit.Aggregator.DockerImage)
 	return &service, nil
 }
+
+// Manifests returns all Kubernetes resources of the component built from the custom resource
+func Manifests(cr *loggingService.LoggingService, dynamicParameters util.DynamicParameters) ([]client.Object, error) {
+	if err := validate(cr); err != nil {
+		return nil, err
+	}
+	aggregatorCM, err := aggregatorConfigMap(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	statefulSet, err := aggregatorStatefulSet(cr)
+	if err != nil {
+		return nil, err
+	}
+	aggregatorSvc, err := aggregatorService(cr)
+	if err != nil {
+		return nil, err
+	}
+	forwarderCM, err := forwarderConfigMap(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	daemonSet, err := forwarderDaemonSet(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	forwarderSvc, err := forwarderService(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	return []client.Object{aggregatorCM, statefulSet, aggregatorSvc, forwarderCM, daemonSet, forwarderSvc}, nil
+}
+
+func validate(cr *loggingService.LoggingService) error {
+	if cr.Spec.Fluentbit.Aggregator.GraylogOutput && (cr.Spec.Fluentbit.Aggregator.GraylogHost == "" || cr.Spec.Fluentbit.Aggregator.GraylogPort == 0) {
+		return errors.New("configuration error: fluentbit.aggregator.graylogHost and fluentbit.aggregator.graylogPort are required with Graylog output")
+	}
+	return nil
+}
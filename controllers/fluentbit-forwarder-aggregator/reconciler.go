@@ -1,8 +1,6 @@
 package fluentbit_forwarder_aggregator
 
 import (
-	"errors"
-
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -15,13 +13,14 @@ type HAFluentReconciler struct {
 	DynamicParameters util.DynamicParameters
 }
 
-func NewHAFluentReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, pendingComponents *[]util.Component, dynamicParameters util.DynamicParameters) HAFluentReconciler {
+func NewHAFluentReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, recorder util.EventRecorder, pendingComponents *[]util.Component, dynamicParameters util.DynamicParameters) HAFluentReconciler {
 	return HAFluentReconciler{
 		ComponentReconciler: &util.ComponentReconciler{
 			Client:        client,
 			Scheme:        scheme,
 			Log:           util.Logger("fluentbit-forwarder-aggregator"),
 			StatusUpdater: updater,
+			EventRecorder: recorder,
 		},
 		ComponentList:     pendingComponents,
 		DynamicParameters: dynamicParameters,
@@ -37,9 +36,9 @@ func (r *HAFluentReconciler) Run(cr *loggingService.LoggingService) error {
 	r.Log.Info("Start Fluentbit-Forwarder-Aggregator reconciliation")
 
 	if cr.Spec.Fluentbit != nil && cr.Spec.Fluentbit.IsInstall() && cr.Spec.Fluentbit.Aggregator != nil && cr.Spec.Fluentbit.Aggregator.Install {
-		if cr.Spec.Fluentbit.Aggregator.GraylogOutput && (cr.Spec.Fluentbit.Aggregator.GraylogHost == "" || cr.Spec.Fluentbit.Aggregator.GraylogPort == 0) {
-			err := errors.New("configuration error: fluentbit.aggregator.graylogHost and fluentbit.aggregator.graylogPort are required with Graylog output")
+		if err := validate(cr); err != nil {
 			r.Log.Error(err, "configuration of fluentbit aggregator is incorrect")
+			r.EventRecorder.Warning(util.ReasonValidationFailed, err.Error())
 			return err
 		}
 		if err := r.handleAggregatorConfigMap(cr); err != nil {
@@ -77,15 +76,15 @@ func (r *HAFluentReconciler) Run(cr *loggingService.LoggingService) error {
 		)
 	} else {
 		r.Log.Info("Uninstalling component if exists")
-		r.uninstall(cr)
+		r.Uninstall(cr)
 		r.StatusUpdater.RemoveStatus(util.HAFluentStatus)
 	}
 	r.Log.Info("Component reconciled")
 	return nil
 }
 
-// uninstall deletes all resources related to the component
-func (r *HAFluentReconciler) uninstall(cr *loggingService.LoggingService) {
+// Uninstall deletes all resources related to the component
+func (r *HAFluentReconciler) Uninstall(cr *loggingService.LoggingService) {
 	if err := r.deleteDaemonSet(cr, util.ForwarderFluentbitComponentName); err != nil {
 		r.Log.Error(err, "Can not delete Daemon Set")
 	}
@@ -11,6 +11,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //go:embed  assets/*.yaml
@@ -161,3 +162,20 @@ func fluentbitConfigMap(cr *loggingService.LoggingService, dynamicParameters uti
 
 	return &configMap, nil
 }
+
+// Manifests returns all Kubernetes resources of the component built from the custom resource
+func Manifests(cr *loggingService.LoggingService, dynamicParameters util.DynamicParameters) ([]client.Object, error) {
+	configMap, err := fluentbitConfigMap(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	daemonSet, err := fluentbitDaemonSet(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	service, err := fluentbitService(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	return []client.Object{configMap, daemonSet, service}, nil
+}
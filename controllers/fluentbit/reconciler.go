@@ -13,13 +13,14 @@ type FluentbitReconciler struct {
 	DynamicParameters util.DynamicParameters
 }
 
-func NewFluentbitReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, pendingComponents *[]util.Component, dynamicParameters util.DynamicParameters) FluentbitReconciler {
+func NewFluentbitReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, recorder util.EventRecorder, pendingComponents *[]util.Component, dynamicParameters util.DynamicParameters) FluentbitReconciler {
 	return FluentbitReconciler{
 		ComponentReconciler: &util.ComponentReconciler{
 			Client:        client,
 			Scheme:        scheme,
 			Log:           util.Logger("fluentbit"),
 			StatusUpdater: updater,
+			EventRecorder: recorder,
 		},
 		ComponentList:     pendingComponents,
 		DynamicParameters: dynamicParameters,
@@ -54,15 +55,15 @@ func (r *FluentbitReconciler) Run(cr *loggingService.LoggingService) error {
 		)
 	} else {
 		r.Log.Info("Uninstalling component if exists")
-		r.uninstall(cr)
+		r.Uninstall(cr)
 		r.StatusUpdater.RemoveStatus(util.FluentbitStatus)
 	}
 	r.Log.Info("Component reconciled")
 	return nil
 }
 
-// uninstall deletes all resources related to the component
-func (r *FluentbitReconciler) uninstall(cr *loggingService.LoggingService) {
+// Uninstall deletes all resources related to the component
+func (r *FluentbitReconciler) Uninstall(cr *loggingService.LoggingService) {
 	if err := r.deleteDaemonSet(cr); err != nil {
 		r.Log.Error(err, "Can not delete DaemonSet")
 	}
@@ -10,6 +10,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //go:embed  assets/*.yaml
@@ -119,3 +120,20 @@ func fluentdService(cr *loggingService.LoggingService, dynamicParameters util.Dy
 	service.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(cr.Spec.Fluentd.DockerImage)
 	return &service, nil
 }
+
+// Manifests returns all Kubernetes resources of the component built from the custom resource
+func Manifests(cr *loggingService.LoggingService, dynamicParameters util.DynamicParameters) ([]client.Object, error) {
+	configMap, err := fluentdConfigMap(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	daemonSet, err := fluentdDaemonSet(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	service, err := fluentdService(cr, dynamicParameters)
+	if err != nil {
+		return nil, err
+	}
+	return []client.Object{configMap, daemonSet, service}, nil
+}
@@ -13,13 +13,14 @@ type FluentdReconciler struct {
 	DynamicParameters util.DynamicParameters
 }
 
-func NewFluentdReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, pendingComponents *[]util.Component, dynamicParameters util.DynamicParameters) FluentdReconciler {
+func NewFluentdReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, recorder util.EventRecorder, pendingComponents *[]util.Component, dynamicParameters util.DynamicParameters) FluentdReconciler {
 	return FluentdReconciler{
 		ComponentReconciler: &util.ComponentReconciler{
 			Client:        client,
 			Scheme:        scheme,
 			Log:           util.Logger("fluentd"),
 			StatusUpdater: updater,
+			EventRecorder: recorder,
 		},
 		ComponentList:     pendingComponents,
 		DynamicParameters: dynamicParameters,
@@ -53,15 +54,15 @@ func (r *FluentdReconciler) Run(cr *loggingService.LoggingService) error {
 		)
 	} else {
 		r.Log.Info("Uninstalling component if exists")
-		r.uninstall(cr)
+		r.Uninstall(cr)
 		r.StatusUpdater.RemoveStatus(util.FluentdStatus)
 	}
 	r.Log.Info("Component reconciled")
 	return nil
 }
 
-// uninstall deletes all resources related to the component
-func (r *FluentdReconciler) uninstall(cr *loggingService.LoggingService) {
+// Uninstall deletes all resources related to the component
+func (r *FluentdReconciler) Uninstall(cr *loggingService.LoggingService) {
 	if err := r.deleteDaemonSet(cr); err != nil {
 		r.Log.Error(err, "Can not delete DaemonSet")
 	}
@@ -0,0 +1,127 @@
+package graylog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// backupAnnotation keeps the name of the backup restored by the Job
+const backupAnnotation = "logging.qubership.org/backup"
+
+// handleBackup creates the CronJob with MongoDB backups
+func (r *GraylogReconciler) handleBackup(cr *loggingService.LoggingService) error {
+	if !cr.Spec.Graylog.IsBackupEnabled() {
+		return r.deleteBackup(cr)
+	}
+	objects, err := backupManifests(cr)
+	if err != nil {
+		r.Log.Error(err, "Failed creating MongoDB backup manifests")
+		return err
+	}
+	if !cr.Spec.Graylog.IsEmbeddedMongoDB() {
+		if err = r.deleteResourceIfExists(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogEmbeddedMongoServiceName, Namespace: cr.GetNamespace()}}); err != nil {
+			return err
+		}
+	}
+	for _, object := range objects {
+		if err = r.createOrUpdateResource(cr, object.(util.K8sResource)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *GraylogReconciler) deleteBackup(cr *loggingService.LoggingService) error {
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogMongoBackupName, Namespace: cr.GetNamespace()}}
+	if err := r.Client.Delete(context.TODO(), cronJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !api_errors.IsNotFound(err) {
+		return err
+	}
+	if err := r.deleteResourceIfExists(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogEmbeddedMongoServiceName, Namespace: cr.GetNamespace()}}); err != nil {
+		return err
+	}
+	return r.deleteRestoreJob(cr)
+}
+
+// deleteRestoreJob deletes the restore Job with its pods and waits for the deletion, so the Job can be created again
+func (r *GraylogReconciler) deleteRestoreJob(cr *loggingService.LoggingService) error {
+	e := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogMongoRestoreJobName, Namespace: cr.GetNamespace()}}
+	if err := r.Client.Delete(context.TODO(), e, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		if api_errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return wait.PollUntilContextTimeout(context.TODO(), util.Interval, util.GraylogMongoRestoreJobTimeout, true, func(ctx context.Context) (bool, error) {
+		err := r.Client.Get(ctx, client.ObjectKeyFromObject(e), &batchv1.Job{})
+		if api_errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// handleRestore restores the backup before the Graylog StatefulSet starts. The backup is restored once,
+// the Job is run again only when the name of the backup is changed
+func (r *GraylogReconciler) handleRestore(cr *loggingService.LoggingService) error {
+	if !cr.Spec.Graylog.IsRestoreRequested() {
+		return r.deleteRestoreJob(cr)
+	}
+	m, err := graylogMongoJob(cr, util.GraylogMongoRestoreJob)
+	if err != nil {
+		r.Log.Error(err, "Failed creating Job for MongoDB restore manifest")
+		return err
+	}
+	backup := cr.Spec.Graylog.Backup.Restore.Backup
+
+	e := &batchv1.Job{ObjectMeta: m.ObjectMeta}
+	if err = r.GetResource(e); err == nil {
+		if e.Annotations[backupAnnotation] == backup {
+			if e.Status.Succeeded == 0 && e.Status.Active == 0 {
+				r.Log.Info(fmt.Sprintf("Restore of the backup %s failed. Delete Job %s to try again", backup, e.GetName()))
+			}
+			return nil
+		}
+		if err = r.deleteRestoreJob(cr); err != nil {
+			return err
+		}
+	} else if !api_errors.IsNotFound(err) {
+		return err
+	}
+
+	// Graylog must not use MongoDB while the backup is restored
+	if err = r.scaleDownStatefulset(cr); err != nil {
+		return err
+	}
+	if err = r.CreateResource(cr, m); err != nil {
+		return err
+	}
+	r.EventRecorder.Normal(util.ReasonMongoRestoreStarted, fmt.Sprintf("Restore of MongoDB backup %s started", backup))
+
+	// Delay to allow time for the job finished successfully
+	time.Sleep(util.InitialDelay)
+
+	podManager := util.NewPodManager(r.Client, cr.GetNamespace(), r.Log)
+	timeout := util.GraylogMongoRestoreJobTimeout
+	succeeded, err := podManager.WaitForJobSucceeded(m.GetName(), timeout)
+	if err != nil {
+		return err
+	}
+	if !succeeded {
+		r.EventRecorder.Warning(util.ReasonMongoRestoreFailed, fmt.Sprintf("Restore of MongoDB backup %s is not finished in %s", backup, timeout))
+		r.StatusUpdater.UpdateStatus(util.GraylogStatus, util.Failed, false, "MongoDB restore failed")
+		return errors.New("mongo restore job failed")
+	}
+	r.EventRecorder.Normal(util.ReasonMongoRestoreFinished, fmt.Sprintf("Restore of MongoDB backup %s finished", backup))
+	return nil
+}
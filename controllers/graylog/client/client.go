@@ -0,0 +1,149 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultBackoff is used for the retries of the requests while Graylog is starting or restarting
+var DefaultBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Cap:      15 * time.Second,
+}
+
+// Client sends requests to the Graylog REST API
+type Client struct {
+	restClient *util.RestClient
+	baseUrl    *url.URL
+	log        logr.Logger
+	// Backoff limits the number of the attempts and the delays between them
+	Backoff wait.Backoff
+}
+
+// New returns the client of the Graylog REST API. The host of the rest client contains the path of the API
+func New(restClient *util.RestClient, tlsEnabled bool) (*Client, error) {
+	protocol := "http"
+	if tlsEnabled {
+		protocol = "https"
+	}
+	baseUrl, err := url.Parse(protocol + "://" + restClient.Host)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		restClient: restClient,
+		baseUrl:    baseUrl,
+		log:        util.Logger("graylog-client"),
+		Backoff:    DefaultBackoff,
+	}, nil
+}
+
+// Raw sends the request with the body as is and returns the response without checking its status code.
+// The request is retried if Graylog is not available
+func (client *Client) Raw(ctx context.Context, method string, path string, body string) (string, int, error) {
+	backoff := client.Backoff
+	for {
+		response, statusCode, err := client.send(ctx, method, path, body)
+		if !isRetryable(method, statusCode, err) || backoff.Steps <= 1 {
+			return response, statusCode, err
+		}
+		delay := backoff.Step()
+		client.log.V(util.Debug).Info("Graylog is not available, retry " + method + " request to " + path + " in " + delay.String())
+		select {
+		case <-ctx.Done():
+			return response, statusCode, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (client *Client) send(ctx context.Context, method string, path string, body string) (string, int, error) {
+	relative, err := url.Parse(path)
+	if err != nil {
+		return "", -1, err
+	}
+	uri := client.baseUrl.ResolveReference(relative)
+
+	request, err := http.NewRequestWithContext(ctx, method, uri.String(), strings.NewReader(body))
+	if err != nil {
+		return "", -1, err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-Requested-By", "Graylog API Browser")
+	client.restClient.SetAuthHeader(request)
+
+	client.log.V(util.Debug).Info("Send " + method + " request to: " + uri.String() + " with body: " + body)
+
+	start := time.Now()
+	response, err := client.restClient.Client.Do(request)
+	if err != nil {
+		util.ObserveGraylogRequest(method, path, -1, start)
+		return "", -1, err
+	}
+	defer response.Body.Close()
+	util.ObserveGraylogRequest(method, path, response.StatusCode, start)
+
+	buf := new(bytes.Buffer)
+	if _, err = buf.ReadFrom(response.Body); err != nil {
+		return "", -1, err
+	}
+	client.log.V(util.Debug).Info("Status code: " + response.Status)
+	client.log.V(util.Debug).Info("Response: " + buf.String())
+	return buf.String(), response.StatusCode, nil
+}
+
+// isRetryable checks whether the request can be sent again. The request is not processed by Graylog
+// if the connection is refused or Graylog is unavailable, so any request is retried. The connection reset
+// and other server errors may happen after Graylog processed the request, so they are retried
+// only for the idempotent methods
+func isRetryable(method string, statusCode int, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED) || (isIdempotent(method) && errors.Is(err, syscall.ECONNRESET))
+	}
+	if statusCode == http.StatusServiceUnavailable {
+		return true
+	}
+	return statusCode >= http.StatusInternalServerError && isIdempotent(method)
+}
+
+// isIdempotent checks that the repeated request has the same effect as the single one
+func isIdempotent(method string) bool {
+	return method != http.MethodPost && method != http.MethodPatch
+}
+
+// do sends the object as JSON and decodes the response to the result if it is not nil.
+// The response with the status code other than 2xx is returned as APIError
+func (client *Client) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	response, statusCode, err := client.Raw(ctx, method, path, string(data))
+	if err != nil {
+		return err
+	}
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		return newAPIError(method, path, statusCode, response)
+	}
+	if result == nil || response == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(response), result)
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// testBackoff has short delays, so the retries don't slow down the tests
+var testBackoff = wait.Backoff{Steps: 3, Duration: time.Millisecond, Factor: 1}
+
+// countingServer responds with the status codes in order, the last one is repeated
+type countingServer struct {
+	mu          sync.Mutex
+	statusCodes []int
+	body        string
+	requests    int
+}
+
+func (server *countingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	statusCode := server.statusCodes[min(server.requests, len(server.statusCodes)-1)]
+	server.requests++
+	w.WriteHeader(statusCode)
+	_, _ = w.Write([]byte(server.body))
+}
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := New(&util.RestClient{Client: server.Client(), Host: strings.TrimPrefix(server.URL, "http://") + "/api/"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Backoff = testBackoff
+	return client
+}
+
+var retryTests = []struct {
+	description string
+	method      string
+	statusCodes []int
+	statusCode  int
+	requests    int
+}{
+	{
+		description: "GET is retried until Graylog is available",
+		method:      http.MethodGet,
+		statusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+		statusCode:  http.StatusOK,
+		requests:    3,
+	},
+	{
+		description: "GET is not retried after the backoff limit",
+		method:      http.MethodGet,
+		statusCodes: []int{http.StatusInternalServerError},
+		statusCode:  http.StatusInternalServerError,
+		requests:    testBackoff.Steps,
+	},
+	{
+		description: "PUT is retried on the server error",
+		method:      http.MethodPut,
+		statusCodes: []int{http.StatusGatewayTimeout, http.StatusNoContent},
+		statusCode:  http.StatusNoContent,
+		requests:    2,
+	},
+	{
+		description: "POST is retried when Graylog is unavailable",
+		method:      http.MethodPost,
+		statusCodes: []int{http.StatusServiceUnavailable, http.StatusCreated},
+		statusCode:  http.StatusCreated,
+		requests:    2,
+	},
+	{
+		// Graylog may create the object before the proxy times out, so POST is not sent again
+		description: "POST is not retried on the gateway timeout",
+		method:      http.MethodPost,
+		statusCodes: []int{http.StatusGatewayTimeout, http.StatusCreated},
+		statusCode:  http.StatusGatewayTimeout,
+		requests:    1,
+	},
+	{
+		description: "POST is not retried on the server error",
+		method:      http.MethodPost,
+		statusCodes: []int{http.StatusInternalServerError, http.StatusCreated},
+		statusCode:  http.StatusInternalServerError,
+		requests:    1,
+	},
+	{
+		description: "Client errors are not retried",
+		method:      http.MethodGet,
+		statusCodes: []int{http.StatusNotFound, http.StatusOK},
+		statusCode:  http.StatusNotFound,
+		requests:    1,
+	},
+}
+
+func TestRawRetries(t *testing.T) {
+	for _, test := range retryTests {
+		t.Run(test.description, func(t *testing.T) {
+			server := &countingServer{statusCodes: test.statusCodes}
+			client := newTestClient(t, server)
+			_, statusCode, err := client.Raw(context.Background(), test.method, "streams", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != test.statusCode {
+				t.Errorf("expected status code %v, got %v", test.statusCode, statusCode)
+			}
+			if server.requests != test.requests {
+				t.Errorf("expected %v requests, got %v", test.requests, server.requests)
+			}
+		})
+	}
+}
+
+func TestRawStopsOnCanceledContext(t *testing.T) {
+	server := &countingServer{statusCodes: []int{http.StatusServiceUnavailable}}
+	client := newTestClient(t, server)
+	client.Backoff = wait.Backoff{Steps: 10, Duration: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, _, err := client.Raw(ctx, http.MethodGet, "streams", "")
+	if err != context.Canceled {
+		t.Errorf("expected error %v, got %v", context.Canceled, err)
+	}
+	if server.requests != 1 {
+		t.Errorf("expected 1 request before the cancellation, got %v", server.requests)
+	}
+}
+
+func TestRawRetriesRefusedConnection(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	client, err := New(&util.RestClient{Client: server.Client(), Host: strings.TrimPrefix(server.URL, "http://")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	client.Backoff = testBackoff
+
+	start := time.Now()
+	if _, _, err = client.Raw(context.Background(), http.MethodPost, "streams", ""); err == nil {
+		t.Fatal("expected error of the refused connection")
+	}
+	// The refused request isn't processed by Graylog, so even POST is retried with the delays
+	if elapsed := time.Since(start); elapsed < time.Duration(testBackoff.Steps-1)*testBackoff.Duration {
+		t.Errorf("expected retries with the delays, the request took %v", elapsed)
+	}
+}
+
+var apiErrorTests = []struct {
+	description string
+	statusCode  int
+	body        string
+	message     string
+	notFound    bool
+}{
+	{
+		description: "Message of the Graylog error is used",
+		statusCode:  http.StatusNotFound,
+		body:        `{"type": "ApiError", "message": "Stream <1> not found!"}`,
+		message:     "Stream <1> not found!",
+		notFound:    true,
+	},
+	{
+		description: "Body is used if the response is not the Graylog error",
+		statusCode:  http.StatusBadRequest,
+		body:        "bad request",
+		message:     "bad request",
+	},
+}
+
+func TestAPIError(t *testing.T) {
+	for _, test := range apiErrorTests {
+		t.Run(test.description, func(t *testing.T) {
+			client := newTestClient(t, &countingServer{statusCodes: []int{test.statusCode}, body: test.body})
+			_, err := client.ListStreams(context.Background())
+			if err == nil {
+				t.Fatal("expected APIError")
+			}
+			apiError, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("expected APIError, got %T: %v", err, err)
+			}
+			if apiError.StatusCode != test.statusCode || apiError.Message != test.message || apiError.Method != http.MethodGet {
+				t.Errorf("unexpected APIError %+v", apiError)
+			}
+			if StatusCode(err) != test.statusCode {
+				t.Errorf("expected status code %v, got %v", test.statusCode, StatusCode(err))
+			}
+			if IsNotFound(err) != test.notFound {
+				t.Errorf("expected IsNotFound %v, got %v", test.notFound, IsNotFound(err))
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is the response of Graylog with the unexpected status code
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Message is the message of the Graylog error or the body of the response if it is not the Graylog error
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("graylog request %s %s failed. Status code: %v. Response: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+func newAPIError(method string, path string, statusCode int, response string) *APIError {
+	apiError := &APIError{Method: method, Path: path, StatusCode: statusCode, Message: response}
+	var graylogError struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal([]byte(response), &graylogError) == nil && graylogError.Message != "" {
+		apiError.Message = graylogError.Message
+	}
+	return apiError
+}
+
+// StatusCode returns the status code of APIError or 0 for other errors
+func StatusCode(err error) int {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return apiError.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound checks that the object requested from Graylog doesn't exist
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
@@ -0,0 +1,93 @@
+package client
+
+import "context"
+
+// Interface is the part of the Graylog REST API used by the operator. It is implemented by Client,
+// tests can replace it with a fake
+type Interface interface {
+	// Raw sends the request with the body as is and returns the response without checking its status code
+	Raw(ctx context.Context, method string, path string, body string) (string, int, error)
+
+	ListStreams(ctx context.Context) ([]Stream, error)
+	CreateStream(ctx context.Context, stream Stream) (string, error)
+	UpdateStream(ctx context.Context, id string, stream Stream) error
+	ResumeStream(ctx context.Context, id string) error
+	DeleteStream(ctx context.Context, id string) error
+	ListStreamRules(ctx context.Context, streamId string) ([]StreamRule, error)
+	CreateStreamRule(ctx context.Context, streamId string, rule StreamRule) error
+	DeleteStreamRule(ctx context.Context, streamId string, id string) error
+	ListStreamOutputs(ctx context.Context, streamId string) ([]Output, error)
+	AddStreamOutputs(ctx context.Context, streamId string, outputIds []string) error
+	RemoveStreamOutput(ctx context.Context, streamId string, outputId string) error
+
+	ListIndexSets(ctx context.Context) ([]IndexSet, error)
+	CreateIndexSet(ctx context.Context, indexSet IndexSet) (*IndexSet, error)
+	UpdateIndexSet(ctx context.Context, id string, indexSet IndexSet) (*IndexSet, error)
+	DeleteIndexSet(ctx context.Context, id string) error
+
+	ListInputs(ctx context.Context) ([]Input, error)
+	CreateInput(ctx context.Context, input Input) (string, error)
+	UpdateInput(ctx context.Context, id string, input Input) error
+
+	ListOutputs(ctx context.Context) ([]Output, error)
+	CreateOutput(ctx context.Context, output Output) (*Output, error)
+	UpdateOutput(ctx context.Context, id string, output Output) error
+	DeleteOutput(ctx context.Context, id string) error
+
+	GetLookupDataAdapter(ctx context.Context, idOrName string) (*LookupDataAdapter, error)
+	CreateLookupDataAdapter(ctx context.Context, adapter LookupDataAdapter) (*LookupDataAdapter, error)
+	UpdateLookupDataAdapter(ctx context.Context, adapter LookupDataAdapter) error
+	DeleteLookupDataAdapter(ctx context.Context, idOrName string) error
+	GetLookupCache(ctx context.Context, idOrName string) (*LookupCache, error)
+	CreateLookupCache(ctx context.Context, cache LookupCache) (*LookupCache, error)
+	UpdateLookupCache(ctx context.Context, cache LookupCache) error
+	DeleteLookupCache(ctx context.Context, idOrName string) error
+	GetLookupTable(ctx context.Context, idOrName string) (*LookupTable, error)
+	CreateLookupTable(ctx context.Context, table LookupTable) (*LookupTable, error)
+	UpdateLookupTable(ctx context.Context, table LookupTable) error
+	DeleteLookupTable(ctx context.Context, idOrName string) error
+
+	ListPipelineRules(ctx context.Context) ([]PipelineRule, error)
+	CreatePipelineRule(ctx context.Context, rule PipelineRule) (*PipelineRule, error)
+	UpdatePipelineRule(ctx context.Context, id string, rule PipelineRule) (*PipelineRule, error)
+	DeletePipelineRule(ctx context.Context, id string) error
+
+	ListPipelines(ctx context.Context) ([]Pipeline, error)
+	CreatePipeline(ctx context.Context, pipeline Pipeline) (*Pipeline, error)
+	UpdatePipeline(ctx context.Context, id string, pipeline Pipeline) (*Pipeline, error)
+	DeletePipeline(ctx context.Context, id string) error
+	ConnectPipelines(ctx context.Context, connection PipelineConnection) error
+
+	ListUsers(ctx context.Context) ([]User, error)
+	CreateUser(ctx context.Context, user User) error
+	UpdateUser(ctx context.Context, id string, user User) error
+	DeleteUser(ctx context.Context, id string) error
+	ChangeUserPassword(ctx context.Context, id string, password string) error
+	ListUserTokens(ctx context.Context, userId string) ([]UserToken, error)
+	CreateUserToken(ctx context.Context, userId string, name string) (*UserToken, error)
+	DeleteUserToken(ctx context.Context, userId string, id string) error
+
+	ListRoles(ctx context.Context) ([]Role, error)
+	GetRole(ctx context.Context, name string) (*Role, error)
+	CreateRole(ctx context.Context, role Role) error
+	UpdateRole(ctx context.Context, name string, role Role) error
+	DeleteRole(ctx context.Context, name string) error
+
+	ListViews(ctx context.Context) ([]View, error)
+	GetView(ctx context.Context, id string) (*View, error)
+	CreateView(ctx context.Context, view View) (*View, error)
+	UpdateView(ctx context.Context, id string, view View) (*View, error)
+	DeleteView(ctx context.Context, id string) error
+	GetSearch(ctx context.Context, id string) (*Search, error)
+	CreateSearch(ctx context.Context, search Search) (*Search, error)
+
+	ListContentPacks(ctx context.Context) ([]ContentPack, error)
+	GetContentPackRevisions(ctx context.Context, id string) (map[int]ContentPack, error)
+	ListContentPackInstallations(ctx context.Context, id string) ([]ContentPackInstallation, error)
+	InstallContentPack(ctx context.Context, id string, revision int, request ContentPackInstallRequest) (*ContentPackInstallation, error)
+	DeleteContentPackInstallation(ctx context.Context, id string, installationId string) error
+	DeleteContentPack(ctx context.Context, id string) error
+	DeleteContentPackRevision(ctx context.Context, id string, revision int) error
+}
+
+var _ Interface = &Client{}
@@ -0,0 +1,467 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	streamsUrl         = "streams"
+	indexSetsUrl       = "system/indices/index_sets"
+	inputsUrl          = "system/inputs"
+	pipelineRulesUrl   = "system/pipelines/rule"
+	pipelinesUrl       = "system/pipelines/pipeline"
+	pipelineConnectUrl = "system/pipelines/connections/to_stream"
+	usersUrl           = "users"
+	rolesUrl           = "roles"
+	viewsUrl           = "views"
+	searchesUrl        = "views/search"
+	contentPacksUrl    = "system/content_packs"
+	outputsUrl         = "system/outputs"
+	lookupAdaptersUrl  = "system/lookup/adapters"
+	lookupCachesUrl    = "system/lookup/caches"
+	lookupTablesUrl    = "system/lookup/tables"
+)
+
+func (client *Client) ListStreams(ctx context.Context) ([]Stream, error) {
+	var response struct {
+		Streams []Stream `json:"streams"`
+	}
+	err := client.do(ctx, http.MethodGet, streamsUrl, nil, &response)
+	return response.Streams, err
+}
+
+// CreateStream creates the stream and returns its id
+func (client *Client) CreateStream(ctx context.Context, stream Stream) (string, error) {
+	var response struct {
+		StreamId string `json:"stream_id"`
+	}
+	err := client.do(ctx, http.MethodPost, streamsUrl, stream, &response)
+	return response.StreamId, err
+}
+
+func (client *Client) UpdateStream(ctx context.Context, id string, stream Stream) error {
+	return client.do(ctx, http.MethodPut, streamsUrl+"/"+url.PathEscape(id), stream, nil)
+}
+
+func (client *Client) ResumeStream(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodPost, streamsUrl+"/"+url.PathEscape(id)+"/resume", nil, nil)
+}
+
+func (client *Client) DeleteStream(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, streamsUrl+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (client *Client) ListStreamRules(ctx context.Context, streamId string) ([]StreamRule, error) {
+	var response struct {
+		StreamRules []StreamRule `json:"stream_rules"`
+	}
+	err := client.do(ctx, http.MethodGet, streamsUrl+"/"+url.PathEscape(streamId)+"/rules", nil, &response)
+	return response.StreamRules, err
+}
+
+func (client *Client) CreateStreamRule(ctx context.Context, streamId string, rule StreamRule) error {
+	return client.do(ctx, http.MethodPost, streamsUrl+"/"+url.PathEscape(streamId)+"/rules", rule, nil)
+}
+
+func (client *Client) DeleteStreamRule(ctx context.Context, streamId string, id string) error {
+	return client.do(ctx, http.MethodDelete, streamsUrl+"/"+url.PathEscape(streamId)+"/rules/"+url.PathEscape(id), nil, nil)
+}
+
+func (client *Client) ListStreamOutputs(ctx context.Context, streamId string) ([]Output, error) {
+	var response struct {
+		Outputs []Output `json:"outputs"`
+	}
+	err := client.do(ctx, http.MethodGet, streamsUrl+"/"+url.PathEscape(streamId)+"/outputs", nil, &response)
+	return response.Outputs, err
+}
+
+// AddStreamOutputs attaches the outputs to the stream, the outputs attached to the stream before are kept
+func (client *Client) AddStreamOutputs(ctx context.Context, streamId string, outputIds []string) error {
+	request := struct {
+		Outputs []string `json:"outputs"`
+	}{Outputs: outputIds}
+	return client.do(ctx, http.MethodPost, streamsUrl+"/"+url.PathEscape(streamId)+"/outputs", request, nil)
+}
+
+func (client *Client) RemoveStreamOutput(ctx context.Context, streamId string, outputId string) error {
+	return client.do(ctx, http.MethodDelete, streamsUrl+"/"+url.PathEscape(streamId)+"/outputs/"+url.PathEscape(outputId), nil, nil)
+}
+
+func (client *Client) ListIndexSets(ctx context.Context) ([]IndexSet, error) {
+	var response struct {
+		IndexSets []IndexSet `json:"index_sets"`
+	}
+	err := client.do(ctx, http.MethodGet, indexSetsUrl, nil, &response)
+	return response.IndexSets, err
+}
+
+func (client *Client) CreateIndexSet(ctx context.Context, indexSet IndexSet) (*IndexSet, error) {
+	created := &IndexSet{}
+	if err := client.do(ctx, http.MethodPost, indexSetsUrl, indexSet, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) UpdateIndexSet(ctx context.Context, id string, indexSet IndexSet) (*IndexSet, error) {
+	updated := &IndexSet{}
+	if err := client.do(ctx, http.MethodPut, indexSetsUrl+"/"+url.PathEscape(id), indexSet, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (client *Client) DeleteIndexSet(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, indexSetsUrl+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (client *Client) ListInputs(ctx context.Context) ([]Input, error) {
+	var response struct {
+		Inputs []Input `json:"inputs"`
+	}
+	err := client.do(ctx, http.MethodGet, inputsUrl, nil, &response)
+	return response.Inputs, err
+}
+
+// CreateInput creates the input and returns its id
+func (client *Client) CreateInput(ctx context.Context, input Input) (string, error) {
+	var response struct {
+		Id string `json:"id"`
+	}
+	err := client.do(ctx, http.MethodPost, inputsUrl, input, &response)
+	return response.Id, err
+}
+
+func (client *Client) UpdateInput(ctx context.Context, id string, input Input) error {
+	return client.do(ctx, http.MethodPut, inputsUrl+"/"+url.PathEscape(id), input, nil)
+}
+
+func (client *Client) ListOutputs(ctx context.Context) ([]Output, error) {
+	var response struct {
+		Outputs []Output `json:"outputs"`
+	}
+	err := client.do(ctx, http.MethodGet, outputsUrl, nil, &response)
+	return response.Outputs, err
+}
+
+func (client *Client) CreateOutput(ctx context.Context, output Output) (*Output, error) {
+	created := &Output{}
+	if err := client.do(ctx, http.MethodPost, outputsUrl, output, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) UpdateOutput(ctx context.Context, id string, output Output) error {
+	return client.do(ctx, http.MethodPut, outputsUrl+"/"+url.PathEscape(id), output, nil)
+}
+
+func (client *Client) DeleteOutput(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, outputsUrl+"/"+url.PathEscape(id), nil, nil)
+}
+
+// GetLookupDataAdapter returns the adapter by its id or name
+func (client *Client) GetLookupDataAdapter(ctx context.Context, idOrName string) (*LookupDataAdapter, error) {
+	adapter := &LookupDataAdapter{}
+	if err := client.do(ctx, http.MethodGet, lookupAdaptersUrl+"/"+url.PathEscape(idOrName), nil, adapter); err != nil {
+		return nil, err
+	}
+	return adapter, nil
+}
+
+func (client *Client) CreateLookupDataAdapter(ctx context.Context, adapter LookupDataAdapter) (*LookupDataAdapter, error) {
+	created := &LookupDataAdapter{}
+	if err := client.do(ctx, http.MethodPost, lookupAdaptersUrl, adapter, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateLookupDataAdapter updates the adapter found by its name, the id of the adapter must be set
+func (client *Client) UpdateLookupDataAdapter(ctx context.Context, adapter LookupDataAdapter) error {
+	return client.do(ctx, http.MethodPut, lookupAdaptersUrl+"/"+url.PathEscape(adapter.Name), adapter, nil)
+}
+
+func (client *Client) DeleteLookupDataAdapter(ctx context.Context, idOrName string) error {
+	return client.do(ctx, http.MethodDelete, lookupAdaptersUrl+"/"+url.PathEscape(idOrName), nil, nil)
+}
+
+// GetLookupCache returns the cache by its id or name
+func (client *Client) GetLookupCache(ctx context.Context, idOrName string) (*LookupCache, error) {
+	cache := &LookupCache{}
+	if err := client.do(ctx, http.MethodGet, lookupCachesUrl+"/"+url.PathEscape(idOrName), nil, cache); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func (client *Client) CreateLookupCache(ctx context.Context, cache LookupCache) (*LookupCache, error) {
+	created := &LookupCache{}
+	if err := client.do(ctx, http.MethodPost, lookupCachesUrl, cache, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) UpdateLookupCache(ctx context.Context, cache LookupCache) error {
+	return client.do(ctx, http.MethodPut, lookupCachesUrl+"/"+url.PathEscape(cache.Name), cache, nil)
+}
+
+func (client *Client) DeleteLookupCache(ctx context.Context, idOrName string) error {
+	return client.do(ctx, http.MethodDelete, lookupCachesUrl+"/"+url.PathEscape(idOrName), nil, nil)
+}
+
+// GetLookupTable returns the table by its id or name
+func (client *Client) GetLookupTable(ctx context.Context, idOrName string) (*LookupTable, error) {
+	table := &LookupTable{}
+	if err := client.do(ctx, http.MethodGet, lookupTablesUrl+"/"+url.PathEscape(idOrName), nil, table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (client *Client) CreateLookupTable(ctx context.Context, table LookupTable) (*LookupTable, error) {
+	created := &LookupTable{}
+	if err := client.do(ctx, http.MethodPost, lookupTablesUrl, table, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) UpdateLookupTable(ctx context.Context, table LookupTable) error {
+	return client.do(ctx, http.MethodPut, lookupTablesUrl+"/"+url.PathEscape(table.Name), table, nil)
+}
+
+func (client *Client) DeleteLookupTable(ctx context.Context, idOrName string) error {
+	return client.do(ctx, http.MethodDelete, lookupTablesUrl+"/"+url.PathEscape(idOrName), nil, nil)
+}
+
+func (client *Client) ListPipelineRules(ctx context.Context) ([]PipelineRule, error) {
+	var rules []PipelineRule
+	err := client.do(ctx, http.MethodGet, pipelineRulesUrl, nil, &rules)
+	return rules, err
+}
+
+func (client *Client) CreatePipelineRule(ctx context.Context, rule PipelineRule) (*PipelineRule, error) {
+	created := &PipelineRule{}
+	if err := client.do(ctx, http.MethodPost, pipelineRulesUrl, rule, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) UpdatePipelineRule(ctx context.Context, id string, rule PipelineRule) (*PipelineRule, error) {
+	updated := &PipelineRule{}
+	if err := client.do(ctx, http.MethodPut, pipelineRulesUrl+"/"+url.PathEscape(id), rule, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (client *Client) DeletePipelineRule(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, pipelineRulesUrl+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (client *Client) ListPipelines(ctx context.Context) ([]Pipeline, error) {
+	var pipelines []Pipeline
+	err := client.do(ctx, http.MethodGet, pipelinesUrl, nil, &pipelines)
+	return pipelines, err
+}
+
+func (client *Client) CreatePipeline(ctx context.Context, pipeline Pipeline) (*Pipeline, error) {
+	created := &Pipeline{}
+	if err := client.do(ctx, http.MethodPost, pipelinesUrl, pipeline, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) UpdatePipeline(ctx context.Context, id string, pipeline Pipeline) (*Pipeline, error) {
+	updated := &Pipeline{}
+	if err := client.do(ctx, http.MethodPut, pipelinesUrl+"/"+url.PathEscape(id), pipeline, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (client *Client) DeletePipeline(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, pipelinesUrl+"/"+url.PathEscape(id), nil, nil)
+}
+
+// ConnectPipelines replaces the pipelines connected to the stream
+func (client *Client) ConnectPipelines(ctx context.Context, connection PipelineConnection) error {
+	return client.do(ctx, http.MethodPost, pipelineConnectUrl, connection, nil)
+}
+
+func (client *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var response struct {
+		Users []User `json:"users"`
+	}
+	err := client.do(ctx, http.MethodGet, usersUrl, nil, &response)
+	return response.Users, err
+}
+
+func (client *Client) CreateUser(ctx context.Context, user User) error {
+	return client.do(ctx, http.MethodPost, usersUrl, user, nil)
+}
+
+func (client *Client) UpdateUser(ctx context.Context, id string, user User) error {
+	return client.do(ctx, http.MethodPut, usersUrl+"/"+url.PathEscape(id), user, nil)
+}
+
+func (client *Client) DeleteUser(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, usersUrl+"/id/"+url.PathEscape(id), nil, nil)
+}
+
+// ChangeUserPassword sets the password of the user. The old password is not required
+// if the password is changed by the admin for another user
+func (client *Client) ChangeUserPassword(ctx context.Context, id string, password string) error {
+	return client.do(ctx, http.MethodPut, usersUrl+"/"+url.PathEscape(id)+"/password", PasswordChange{Password: password}, nil)
+}
+
+func (client *Client) ListUserTokens(ctx context.Context, userId string) ([]UserToken, error) {
+	var response struct {
+		Tokens []UserToken `json:"tokens"`
+	}
+	err := client.do(ctx, http.MethodGet, usersUrl+"/"+url.PathEscape(userId)+"/tokens", nil, &response)
+	return response.Tokens, err
+}
+
+// CreateUserToken mints the access token of the user. The value of the token is returned only once
+func (client *Client) CreateUserToken(ctx context.Context, userId string, name string) (*UserToken, error) {
+	var token UserToken
+	if err := client.do(ctx, http.MethodPost, usersUrl+"/"+url.PathEscape(userId)+"/tokens/"+url.PathEscape(name), struct{}{}, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (client *Client) DeleteUserToken(ctx context.Context, userId string, id string) error {
+	return client.do(ctx, http.MethodDelete, usersUrl+"/"+url.PathEscape(userId)+"/tokens/"+url.PathEscape(id), nil, nil)
+}
+
+func (client *Client) ListRoles(ctx context.Context) ([]Role, error) {
+	var response struct {
+		Roles []Role `json:"roles"`
+	}
+	err := client.do(ctx, http.MethodGet, rolesUrl, nil, &response)
+	return response.Roles, err
+}
+
+func (client *Client) GetRole(ctx context.Context, name string) (*Role, error) {
+	role := &Role{}
+	if err := client.do(ctx, http.MethodGet, rolesUrl+"/"+url.PathEscape(name), nil, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (client *Client) CreateRole(ctx context.Context, role Role) error {
+	return client.do(ctx, http.MethodPost, rolesUrl, role, nil)
+}
+
+func (client *Client) UpdateRole(ctx context.Context, name string, role Role) error {
+	return client.do(ctx, http.MethodPut, rolesUrl+"/"+url.PathEscape(name), role, nil)
+}
+
+func (client *Client) DeleteRole(ctx context.Context, name string) error {
+	return client.do(ctx, http.MethodDelete, rolesUrl+"/"+url.PathEscape(name), nil, nil)
+}
+
+func (client *Client) ListViews(ctx context.Context) ([]View, error) {
+	var response struct {
+		Views []View `json:"views"`
+	}
+	err := client.do(ctx, http.MethodGet, viewsUrl, nil, &response)
+	return response.Views, err
+}
+
+func (client *Client) GetView(ctx context.Context, id string) (*View, error) {
+	view := &View{}
+	if err := client.do(ctx, http.MethodGet, viewsUrl+"/"+url.PathEscape(id), nil, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (client *Client) CreateView(ctx context.Context, view View) (*View, error) {
+	created := &View{}
+	if err := client.do(ctx, http.MethodPost, viewsUrl, view, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) UpdateView(ctx context.Context, id string, view View) (*View, error) {
+	updated := &View{}
+	if err := client.do(ctx, http.MethodPut, viewsUrl+"/"+url.PathEscape(id), view, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (client *Client) DeleteView(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, viewsUrl+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (client *Client) GetSearch(ctx context.Context, id string) (*Search, error) {
+	search := &Search{}
+	if err := client.do(ctx, http.MethodGet, searchesUrl+"/"+url.PathEscape(id), nil, search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// CreateSearch creates the search. Searches are immutable, so the changed search is created with the new id
+func (client *Client) CreateSearch(ctx context.Context, search Search) (*Search, error) {
+	created := &Search{}
+	if err := client.do(ctx, http.MethodPost, searchesUrl, search, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) ListContentPacks(ctx context.Context) ([]ContentPack, error) {
+	var response struct {
+		ContentPacks []ContentPack `json:"content_packs"`
+	}
+	err := client.do(ctx, http.MethodGet, contentPacksUrl, nil, &response)
+	return response.ContentPacks, err
+}
+
+func (client *Client) GetContentPackRevisions(ctx context.Context, id string) (map[int]ContentPack, error) {
+	var response struct {
+		Revisions map[int]ContentPack `json:"content_pack_revisions"`
+	}
+	err := client.do(ctx, http.MethodGet, contentPacksUrl+"/"+url.PathEscape(id), nil, &response)
+	return response.Revisions, err
+}
+
+func (client *Client) ListContentPackInstallations(ctx context.Context, id string) ([]ContentPackInstallation, error) {
+	var response struct {
+		Installations []ContentPackInstallation `json:"installations"`
+	}
+	err := client.do(ctx, http.MethodGet, contentPacksUrl+"/"+url.PathEscape(id)+"/installations", nil, &response)
+	return response.Installations, err
+}
+
+func (client *Client) InstallContentPack(ctx context.Context, id string, revision int, request ContentPackInstallRequest) (*ContentPackInstallation, error) {
+	installation := &ContentPackInstallation{}
+	err := client.do(ctx, http.MethodPost, contentPacksUrl+"/"+url.PathEscape(id)+"/"+strconv.Itoa(revision)+"/installations", request, installation)
+	return installation, err
+}
+
+func (client *Client) DeleteContentPackInstallation(ctx context.Context, id string, installationId string) error {
+	return client.do(ctx, http.MethodDelete, contentPacksUrl+"/"+url.PathEscape(id)+"/installations/"+url.PathEscape(installationId), nil, nil)
+}
+
+func (client *Client) DeleteContentPack(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, contentPacksUrl+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (client *Client) DeleteContentPackRevision(ctx context.Context, id string, revision int) error {
+	return client.do(ctx, http.MethodDelete, contentPacksUrl+"/"+url.PathEscape(id)+"/"+strconv.Itoa(revision), nil, nil)
+}
@@ -0,0 +1,205 @@
+package client
+
+import "encoding/json"
+
+// Stream is the Graylog stream which routes the messages to the index set
+type Stream struct {
+	Id                             string       `json:"id,omitempty"`
+	Title                          string       `json:"title"`
+	Description                    string       `json:"description"`
+	IndexSetId                     string       `json:"index_set_id"`
+	MatchingType                   string       `json:"matching_type,omitempty"`
+	Rules                          []StreamRule `json:"rules,omitempty"`
+	RemoveMatchesFromDefaultStream bool         `json:"remove_matches_from_default_stream"`
+	Disabled                       bool         `json:"disabled,omitempty"`
+}
+
+// StreamRule is the condition on the message field which routes the message to the stream
+type StreamRule struct {
+	Id          string `json:"id,omitempty"`
+	Field       string `json:"field"`
+	Type        int    `json:"type"`
+	Value       string `json:"value"`
+	Inverted    bool   `json:"inverted"`
+	Description string `json:"description"`
+}
+
+// IndexSet is the Graylog index set. Rotation and retention strategies are kept as is,
+// because their configs depend on the strategy class
+type IndexSet struct {
+	Id                              string                 `json:"id,omitempty"`
+	Title                           string                 `json:"title"`
+	Description                     string                 `json:"description"`
+	IndexPrefix                     string                 `json:"index_prefix"`
+	Shards                          int                    `json:"shards"`
+	Replicas                        int                    `json:"replicas"`
+	RotationStrategyClass           string                 `json:"rotation_strategy_class"`
+	RotationStrategy                map[string]interface{} `json:"rotation_strategy"`
+	RetentionStrategyClass          string                 `json:"retention_strategy_class"`
+	RetentionStrategy               map[string]interface{} `json:"retention_strategy"`
+	CreationDate                    string                 `json:"creation_date,omitempty"`
+	IndexAnalyzer                   string                 `json:"index_analyzer"`
+	IndexOptimizationMaxNumSegments int                    `json:"index_optimization_max_num_segments"`
+	IndexOptimizationDisabled       bool                   `json:"index_optimization_disabled"`
+	Writable                        bool                   `json:"writable"`
+	Default                         bool                   `json:"default"`
+	FieldTypeRefreshInterval        int64                  `json:"field_type_refresh_interval"`
+}
+
+// Input is the Graylog input which receives the messages
+type Input struct {
+	Id            string                 `json:"id,omitempty"`
+	Title         string                 `json:"title"`
+	Type          string                 `json:"type"`
+	Global        bool                   `json:"global"`
+	Node          string                 `json:"node,omitempty"`
+	Configuration map[string]interface{} `json:"configuration"`
+}
+
+// PipelineRule is the processing rule of the pipelines
+type PipelineRule struct {
+	Id          string `json:"id,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Source      string `json:"source"`
+}
+
+// Pipeline is the processing pipeline. Graylog builds its stages from the source
+type Pipeline struct {
+	Id          string          `json:"id,omitempty"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Source      string          `json:"source"`
+	Stages      []PipelineStage `json:"stages,omitempty"`
+}
+
+// PipelineStage is the stage of the pipeline with its rules
+type PipelineStage struct {
+	Stage int      `json:"stage"`
+	Match string   `json:"match"`
+	Rules []string `json:"rules"`
+}
+
+// PipelineConnection connects the pipelines to the stream
+type PipelineConnection struct {
+	StreamId    string   `json:"stream_id"`
+	PipelineIds []string `json:"pipeline_ids"`
+}
+
+// User is the Graylog user. The password is sent only on the creation and the change of the user
+type User struct {
+	Id               string   `json:"id,omitempty"`
+	Username         string   `json:"username"`
+	Password         string   `json:"password,omitempty"`
+	Email            string   `json:"email"`
+	FirstName        string   `json:"first_name"`
+	LastName         string   `json:"last_name"`
+	Permissions      []string `json:"permissions"`
+	Roles            []string `json:"roles"`
+	SessionTimeoutMs int64    `json:"session_timeout_ms"`
+}
+
+// PasswordChange is the request to change the password of the user
+type PasswordChange struct {
+	Password    string `json:"password"`
+	OldPassword string `json:"old_password,omitempty"`
+}
+
+// UserToken is the access token of the user. Token is set only in the response to the creation of the token
+type UserToken struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Token      string `json:"token,omitempty"`
+	LastAccess string `json:"last_access,omitempty"`
+}
+
+// Role is the Graylog role with the permissions
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	ReadOnly    bool     `json:"read_only"`
+}
+
+// View is the saved search or the dashboard. The state of the view is not parsed
+type View struct {
+	Id          string          `json:"id"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	Summary     string          `json:"summary,omitempty"`
+	Description string          `json:"description,omitempty"`
+	SearchId    string          `json:"search_id,omitempty"`
+	State       json.RawMessage `json:"state,omitempty"`
+}
+
+// Search is the search of the view with its queries. The queries and the parameters are not parsed
+type Search struct {
+	Id         string          `json:"id,omitempty"`
+	Queries    json.RawMessage `json:"queries"`
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+}
+
+// Output forwards the messages of the streams which it is attached to
+type Output struct {
+	Id            string                 `json:"id,omitempty"`
+	Title         string                 `json:"title"`
+	Type          string                 `json:"type"`
+	Configuration map[string]interface{} `json:"configuration"`
+}
+
+// LookupDataAdapter provides the values of the lookup tables. The fields of the configuration depend on its type
+type LookupDataAdapter struct {
+	Id          string                 `json:"id,omitempty"`
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Config      map[string]interface{} `json:"config"`
+}
+
+// LookupCache caches the values of the lookup tables. The fields of the configuration depend on its type
+type LookupCache struct {
+	Id          string                 `json:"id,omitempty"`
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Config      map[string]interface{} `json:"config"`
+}
+
+// LookupTable looks up the values by the keys with the data adapter and the cache
+type LookupTable struct {
+	Id                     string `json:"id,omitempty"`
+	Name                   string `json:"name"`
+	Title                  string `json:"title"`
+	Description            string `json:"description"`
+	CacheId                string `json:"cache_id"`
+	DataAdapterId          string `json:"data_adapter_id"`
+	DefaultSingleValue     string `json:"default_single_value"`
+	DefaultSingleValueType string `json:"default_single_value_type"`
+	DefaultMultiValue      string `json:"default_multi_value"`
+	DefaultMultiValueType  string `json:"default_multi_value_type"`
+}
+
+// ContentPack is the revision of the content pack. The entities of the content pack are not parsed
+type ContentPack struct {
+	Id       string          `json:"id"`
+	Revision int             `json:"rev"`
+	Name     string          `json:"name"`
+	Summary  string          `json:"summary,omitempty"`
+	Entities json.RawMessage `json:"entities,omitempty"`
+}
+
+// ContentPackInstallRequest contains the values of the parameters of the content pack for its installation
+type ContentPackInstallRequest struct {
+	Parameters map[string]interface{} `json:"parameters"`
+	Comment    string                 `json:"comment,omitempty"`
+}
+
+// ContentPackInstallation is the installation of the content pack revision
+type ContentPackInstallation struct {
+	Id                string `json:"_id"`
+	ContentPackId     string `json:"content_pack_id"`
+	ContentPackRev    int    `json:"content_pack_revision"`
+	Comment           string `json:"comment,omitempty"`
+	CreatedBy         string `json:"created_by,omitempty"`
+	CreatedAtDateTime string `json:"created_at,omitempty"`
+}
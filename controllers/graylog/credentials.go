@@ -0,0 +1,224 @@
+package graylog
+
+import (
+	"context"
+	"fmt"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	"github.com/Netcracker/qubership-logging-operator/controllers/graylog/utils"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// rotationTriggerAnnotation keeps the trigger of the rotation which generated the passwords in the Graylog Secret,
+// so the passwords are not generated again when the reconciliation is retried
+const rotationTriggerAnnotation = "logging.qubership.org/rotation-trigger"
+
+// graylogUserPasswordKeys are the users created by the operator and the keys of their passwords in the Graylog Secret
+var graylogUserPasswordKeys = [][2]string{
+	{"operator", "operatorPassword"},
+	{"auditViewer", "auditViewerPassword"},
+	{"graylog_api_th_user", "trustedHeaderUserPassword"},
+}
+
+// generateCredentials writes new passwords of the admin user and the users created by the operator
+// to the Graylog Secret. Graylog is restarted with the new root password, because its configuration is changed
+func (r *GraylogReconciler) generateCredentials(cr *loggingService.LoggingService, trigger string) error {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{
+		Name: cr.Spec.Graylog.GraylogSecretName, Namespace: cr.GetNamespace(),
+	}, secret); err != nil {
+		return err
+	}
+	if secret.GetAnnotations()[rotationTriggerAnnotation] == trigger {
+		r.Log.Info(fmt.Sprintf("Credentials for the rotation %s are already generated", trigger))
+		return nil
+	}
+
+	keys := []string{"password"}
+	for _, user := range graylogUserPasswordKeys {
+		keys = append(keys, user[1])
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	for _, key := range keys {
+		password, err := util.GeneratePassword(cr.Spec.Graylog.RotationPasswordLength())
+		if err != nil {
+			return err
+		}
+		secret.Data[key] = []byte(password)
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[rotationTriggerAnnotation] = trigger
+	if err := r.Client.Update(context.TODO(), secret); err != nil {
+		return err
+	}
+	r.EventRecorder.Normal(util.ReasonCredentialsGenerated, fmt.Sprintf("New Graylog credentials are generated in the Secret %s", secret.GetName()))
+	return nil
+}
+
+// completeCredentialsRotation changes passwords of the users created by the operator, confirms the login
+// with the new credentials and updates Secrets of Graylog clients. Graylog must be already restarted
+// with the new root password
+func (r *GraylogReconciler) completeCredentialsRotation(cr *loggingService.LoggingService, connector *utils.GraylogConnector, trigger string) error {
+	var users []string
+	for _, user := range graylogUserPasswordKeys {
+		password, found := connector.UserPasswords[user[0]]
+		if !found {
+			continue
+		}
+		// Missing users are created later with the new password
+		exists, err := connector.ChangeUserPassword(user[0], password)
+		if err != nil {
+			return err
+		}
+		if exists {
+			users = append(users, user[0])
+		}
+	}
+
+	if err := connector.ConfirmLogin(cr.Spec.Graylog.User, cr.Spec.Graylog.Password); err != nil {
+		return err
+	}
+	for _, user := range users {
+		if err := connector.ConfirmLogin(user, connector.UserPasswords[user]); err != nil {
+			return err
+		}
+	}
+
+	if cr.Spec.Graylog.CredentialsRotation != nil {
+		for _, rotated := range cr.Spec.Graylog.CredentialsRotation.Secrets {
+			if err := r.updateRotatedSecret(cr, rotated, connector.UserPasswords); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := metav1.Now()
+	r.StatusUpdater.UpdateCredentialsRotationStatus(&loggingService.CredentialsRotationStatus{Trigger: trigger, LastRotationTime: &now})
+	r.EventRecorder.Normal(util.ReasonCredentialsRotated, fmt.Sprintf("Graylog credentials are rotated by the trigger %s", trigger))
+	return nil
+}
+
+// updateRotatedSecret writes the credentials of the Graylog user to the Secret of the Graylog client
+func (r *GraylogReconciler) updateRotatedSecret(cr *loggingService.LoggingService, rotated loggingService.RotatedSecret, userPasswords map[string]string) error {
+	user := rotated.User
+	password := userPasswords[user]
+	if user == "" || user == cr.Spec.Graylog.User {
+		user = cr.Spec.Graylog.User
+		password = cr.Spec.Graylog.Password
+	}
+	if password == "" {
+		return fmt.Errorf("can't update the Secret %s, the password of Graylog user %s is not rotated", rotated.Name, user)
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: rotated.Name, Namespace: cr.GetNamespace()}, secret); err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[rotated.PasswordKey] = []byte(password)
+	if rotated.UserKey != "" {
+		secret.Data[rotated.UserKey] = []byte(user)
+	}
+	if err := r.Client.Update(context.TODO(), secret); err != nil {
+		return err
+	}
+	r.Log.Info(fmt.Sprintf("Credentials of Graylog user %s are updated in the Secret %s", user, rotated.Name))
+	return nil
+}
+
+// handleAccessToken switches the connector from the root user to the access token of the operator service user.
+// The root credentials are used only to create the service user and to mint the token
+// if the token in the Secret is missing or revoked
+func (r *GraylogReconciler) handleAccessToken(cr *loggingService.LoggingService, connector *utils.GraylogConnector) error {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogTokenSecretName, Namespace: cr.GetNamespace()}}
+	secret.SetGroupVersionKind(schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"})
+	err := r.GetResource(secret)
+	if err != nil && !api_errors.IsNotFound(err) {
+		return err
+	}
+	secretExists := err == nil
+
+	if token := string(secret.Data["token"]); token != "" {
+		valid, err := connector.IsAccessTokenValid(token)
+		if err != nil {
+			return err
+		}
+		if valid {
+			connector.UseAccessToken(token)
+			_, err = connector.EnsureServiceUser()
+			return err
+		}
+		r.Log.Info("Access token of Graylog user " + util.GraylogServiceUserName + " is revoked, create a new one")
+	}
+
+	userId, err := connector.EnsureServiceUser()
+	if err != nil {
+		return err
+	}
+	token, err := connector.CreateAccessToken(userId)
+	if err != nil {
+		return err
+	}
+	secret.Data = map[string][]byte{"user": []byte(util.GraylogServiceUserName), "token": []byte(token)}
+	if secretExists {
+		err = r.UpdateResource(secret)
+	} else {
+		err = r.CreateResource(cr, secret)
+	}
+	if err != nil {
+		return err
+	}
+	connector.UseAccessToken(token)
+	r.Log.Info("Access token of Graylog user " + util.GraylogServiceUserName + " is stored in the Secret " + secret.GetName())
+	return nil
+}
+
+// CreateContentConnector returns the connector to Graylog of the LoggingService for the controllers
+// of the Graylog content. It uses the access token of the operator service user,
+// the credentials of the admin user are used until the token is created
+func (r *GraylogReconciler) CreateContentConnector(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) (*utils.GraylogConnector, error) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogTokenSecretName, Namespace: cr.GetNamespace()}}
+	secret.SetGroupVersionKind(schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"})
+	if err := r.GetResource(secret); err != nil && !api_errors.IsNotFound(err) {
+		return nil, err
+	}
+	token := string(secret.Data["token"])
+	if token == "" {
+		if _, err := r.setCredentials(cr); err != nil {
+			return nil, err
+		}
+	}
+	connector, err := utils.CreateConnector(ctx, cr, configs, clientSet)
+	if err != nil {
+		return nil, err
+	}
+	connector.EventRecorder = r.EventRecorder
+	if token != "" {
+		connector.UseAccessToken(token)
+	}
+	return connector, nil
+}
+
+// deleteAccessToken deletes the Secret with the access token of the operator service user
+func (r *GraylogReconciler) deleteAccessToken(cr *loggingService.LoggingService) error {
+	e := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogTokenSecretName, Namespace: cr.GetNamespace()}}
+	if err := r.GetResource(e); err != nil {
+		if api_errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return r.DeleteResource(e)
+}
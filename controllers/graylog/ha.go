@@ -0,0 +1,207 @@
+package graylog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	"github.com/Netcracker/qubership-logging-operator/controllers/graylog/utils"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// createOrUpdateResource creates the resource or replaces the existing one
+func (r *GraylogReconciler) createOrUpdateResource(cr *loggingService.LoggingService, m util.K8sResource) error {
+	err := r.CreateResource(cr, m)
+	if err == nil || !api_errors.IsAlreadyExists(err) {
+		return err
+	}
+	e, ok := m.DeepCopyObject().(util.K8sResource)
+	if !ok {
+		return fmt.Errorf("can't copy %s", m.GetName())
+	}
+	if err = r.GetResource(e); err != nil {
+		return err
+	}
+	m.SetResourceVersion(e.GetResourceVersion())
+	// Cluster IP of the Service can't be changed
+	if service, isService := m.(*corev1.Service); isService {
+		existing := e.(*corev1.Service)
+		service.Spec.ClusterIP = existing.Spec.ClusterIP
+		service.Spec.ClusterIPs = existing.Spec.ClusterIPs
+	}
+	return r.UpdateResource(m)
+}
+
+// deleteResourceIfExists deletes the resource which is not needed in the current mode
+func (r *GraylogReconciler) deleteResourceIfExists(e util.K8sResource) error {
+	if err := r.GetResource(e); err != nil {
+		if api_errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return r.DeleteResource(e)
+}
+
+func (r *GraylogReconciler) startupTimeout(cr *loggingService.LoggingService) time.Duration {
+	if cr.Spec.Graylog.StartupTimeout != 0 {
+		return time.Duration(cr.Spec.Graylog.StartupTimeout) * time.Minute
+	}
+	return util.GraylogStartupTimeout
+}
+
+// recreateStatefulset deletes the StatefulSet with its pods and waits for the deletion,
+// so pods of the old StatefulSet are not adopted by the new one
+func (r *GraylogReconciler) recreateStatefulset(e *appsv1.StatefulSet) error {
+	if err := r.Client.Delete(context.TODO(), e, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+		return err
+	}
+	return wait.PollUntilContextTimeout(context.TODO(), util.Interval, util.GraylogStartupTimeout, true, func(ctx context.Context) (bool, error) {
+		err := r.Client.Get(ctx, client.ObjectKeyFromObject(e), &appsv1.StatefulSet{})
+		if api_errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// handleMongoReplicaSet deploys the MongoDB replica set before Graylog, because Graylog nodes can't start without it
+func (r *GraylogReconciler) handleMongoReplicaSet(cr *loggingService.LoggingService) error {
+	if !cr.Spec.Graylog.IsManagedMongoDB() {
+		return r.deleteMongoReplicaSet(cr)
+	}
+	service, err := graylogHAService(cr, util.GraylogMongoService)
+	if err != nil {
+		r.Log.Error(err, "Failed creating MongoDB Service manifest")
+		return err
+	}
+	if err = r.createOrUpdateResource(cr, service); err != nil {
+		return err
+	}
+	statefulset, err := graylogMongoStatefulset(cr)
+	if err != nil {
+		r.Log.Error(err, "Failed creating MongoDB Statefulset manifest")
+		return err
+	}
+	if err = r.createOrUpdateResource(cr, statefulset); err != nil {
+		return err
+	}
+	pdb, err := graylogPodDisruptionBudget(cr, util.GraylogMongoPodDisruptionBudget)
+	if err != nil {
+		r.Log.Error(err, "Failed creating MongoDB PodDisruptionBudget manifest")
+		return err
+	}
+	if err = r.createOrUpdateResource(cr, pdb); err != nil {
+		return err
+	}
+
+	podManager := util.NewPodManager(r.Client, cr.GetNamespace(), r.Log)
+	started, err := podManager.WaitForStatefulsetUpdated(util.GraylogMongoName, r.startupTimeout(cr))
+	if err != nil {
+		return err
+	}
+	if !started {
+		r.StatusUpdater.UpdateStatus(util.GraylogStatus, util.Failed, false, "MongoDB replica set is not started")
+		return errors.New("mongodb replica set is not started")
+	}
+	return nil
+}
+
+func (r *GraylogReconciler) deleteMongoReplicaSet(cr *loggingService.LoggingService) error {
+	objectMeta := metav1.ObjectMeta{Name: util.GraylogMongoName, Namespace: cr.GetNamespace()}
+	for _, e := range []util.K8sResource{
+		&policyv1.PodDisruptionBudget{ObjectMeta: objectMeta},
+		&appsv1.StatefulSet{ObjectMeta: objectMeta},
+		&corev1.Service{ObjectMeta: objectMeta},
+	} {
+		if err := r.deleteResourceIfExists(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleHAResources creates Services and PodDisruptionBudget of the Graylog cluster
+// and waits for all nodes have joined the cluster
+func (r *GraylogReconciler) handleHAResources(cr *loggingService.LoggingService, connector *utils.GraylogConnector) error {
+	if !cr.Spec.Graylog.IsHA() {
+		return r.deleteHAResources(cr)
+	}
+	for _, assetPath := range []string{util.GraylogNodesService, util.GraylogInputService} {
+		service, err := graylogHAService(cr, assetPath)
+		if err != nil {
+			r.Log.Error(err, "Failed creating Service manifest")
+			return err
+		}
+		if err = r.createOrUpdateResource(cr, service); err != nil {
+			return err
+		}
+	}
+	pdb, err := graylogPodDisruptionBudget(cr, util.GraylogPodDisruptionBudget)
+	if err != nil {
+		r.Log.Error(err, "Failed creating PodDisruptionBudget manifest")
+		return err
+	}
+	if err = r.createOrUpdateResource(cr, pdb); err != nil {
+		return err
+	}
+
+	joined, err := connector.WaitForClusterNodes(cr.Spec.Graylog.Replicas, r.startupTimeout(cr))
+	if err != nil {
+		return err
+	}
+	if !joined {
+		r.StatusUpdater.UpdateStatus(util.GraylogStatus, util.Failed, false, "Not all Graylog nodes have joined the cluster")
+		return errors.New("not all graylog nodes have joined the cluster")
+	}
+	return nil
+}
+
+func (r *GraylogReconciler) deleteHAResources(cr *loggingService.LoggingService) error {
+	for _, e := range []util.K8sResource{
+		&policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogStatefulsetName, Namespace: cr.GetNamespace()}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogNodesServiceName, Namespace: cr.GetNamespace()}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: util.GraylogInputServiceName, Namespace: cr.GetNamespace()}},
+	} {
+		if err := r.deleteResourceIfExists(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteHAClaims deletes volumes created from the volume claim templates of Graylog and MongoDB StatefulSets
+func (r *GraylogReconciler) deleteHAClaims(cr *loggingService.LoggingService) error {
+	if cr.Spec.Graylog == nil {
+		return nil
+	}
+	var names []string
+	for i := 0; i < cr.Spec.Graylog.Replicas; i++ {
+		names = append(names, "data-"+util.GraylogStatefulsetName+"-"+strconv.Itoa(i))
+	}
+	if cr.Spec.Graylog.IsManagedMongoDB() {
+		replicas := cr.Spec.Graylog.MongoDB.ReplicaSet.Replicas
+		if replicas == 0 {
+			replicas = 3
+		}
+		for i := 0; i < replicas; i++ {
+			names = append(names, "mongodb-"+util.GraylogMongoName+"-"+strconv.Itoa(i))
+		}
+	}
+	for _, name := range names {
+		if err := r.deletePVC(name, cr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package graylog
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
@@ -38,9 +40,6 @@ func (r *GraylogReconciler) handleServiceAccount(cr *loggingService.LoggingServi
 }
 
 func (r *GraylogReconciler) handleConfigMap(cr *loggingService.LoggingService) error {
-	if err := r.setCredentials(cr); err != nil {
-		return err
-	}
 	m, err := graylogConfigMap(cr)
 	if err != nil {
 		r.Log.Error(err, "Failed creating ConfigMap manifest")
@@ -61,20 +60,67 @@ func (r *GraylogReconciler) handleConfigMap(cr *loggingService.LoggingService) e
 	return nil
 }
 
-func (r *GraylogReconciler) handleMongoUpgradeJob(cr *loggingService.LoggingService, jobName, assetPath string) error {
-	m, err := graylogMongoUpgradeJob(cr, assetPath)
+// handleLookupTablesConfigMap creates or updates the ConfigMap with the entries of the inmemory data adapters
+// and deletes it if there are no such data adapters
+func (r *GraylogReconciler) handleLookupTablesConfigMap(cr *loggingService.LoggingService) error {
+	if err := r.checkLookupTableFiles(cr); err != nil {
+		r.EventRecorder.Warning(util.ReasonValidationFailed, err.Error())
+		return err
+	}
+	m, err := graylogLookupTablesConfigMap(cr)
 	if err != nil {
-		r.Log.Error(err, "Failed creating Job for MongoDB upgrade manifest")
+		r.Log.Error(err, "Failed creating ConfigMap manifest with lookup tables")
 		return err
 	}
+	if m == nil {
+		return r.deleteLookupTablesConfigMap(cr)
+	}
 
 	if err = r.CreateResource(cr, m); err != nil {
 		if api_errors.IsAlreadyExists(err) {
-			r.Log.Info("Job for MongoDB upgrade already exists, skip creating")
-			return nil
-		} else {
+			return r.UpdateResource(m)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkLookupTableFiles checks that the ConfigMaps of the csv data adapters exist and contain the files,
+// because the optional volumes of the missing ConfigMaps are mounted empty
+func (r *GraylogReconciler) checkLookupTableFiles(cr *loggingService.LoggingService) error {
+	if cr.Spec.Graylog.LookupTables == nil {
+		return nil
+	}
+	for _, adapter := range cr.Spec.Graylog.LookupTables.DataAdapters {
+		if adapter.Type != "csv" || adapter.CSV == nil {
+			continue
+		}
+		selector := adapter.CSV.ConfigMap
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: selector.Name, Namespace: cr.GetNamespace()}}
+		if err := r.GetResource(configMap); err != nil {
+			if api_errors.IsNotFound(err) {
+				return fmt.Errorf("ConfigMap %s of lookup data adapter %s not found", selector.Name, adapter.Name)
+			}
 			return err
 		}
+		if _, found := configMap.Data[selector.Key]; !found {
+			return fmt.Errorf("can't find key %s in ConfigMap %s for lookup data adapter %s", selector.Key, selector.Name, adapter.Name)
+		}
+	}
+	return nil
+}
+
+// handleMongoUpgradeJob runs the Job of the upgrade step and waits for its completion.
+// The Job left by the interrupted reconciliation is not created again, the operator waits for it
+func (r *GraylogReconciler) handleMongoUpgradeJob(cr *loggingService.LoggingService, m *batchv1.Job) error {
+	jobName := m.GetName()
+	if err := r.CreateResource(cr, m); err != nil {
+		if !api_errors.IsAlreadyExists(err) {
+			return err
+		}
+		r.Log.Info(fmt.Sprintf("Job %s for MongoDB upgrade already exists, wait for its completion", jobName))
+	} else {
+		r.EventRecorder.Normal(util.ReasonMongoUpgradeStarted, fmt.Sprintf("MongoDB upgrade step %s started", jobName))
 	}
 
 	// Delay to allow time for the job finished successfully
@@ -88,9 +134,11 @@ func (r *GraylogReconciler) handleMongoUpgradeJob(cr *loggingService.LoggingServ
 	}
 
 	if !succeeded {
+		r.EventRecorder.Warning(util.ReasonMongoUpgradeFailed, fmt.Sprintf("MongoDB upgrade step %s is not finished in %s", jobName, timeout))
 		r.StatusUpdater.UpdateStatus(util.GraylogStatus, util.Failed, false, "Job failed")
 		return errors.New("mongo upgrade job failed")
 	}
+	r.EventRecorder.Normal(util.ReasonMongoUpgradeFinished, fmt.Sprintf("MongoDB upgrade step %s finished", jobName))
 
 	// Delete pods when the job is done
 	_, err = podManager.DeletePods(util.GraylogMongoUpgradeLabels)
@@ -101,7 +149,7 @@ func (r *GraylogReconciler) handleMongoUpgradeJob(cr *loggingService.LoggingServ
 	return nil
 }
 
-func (r *GraylogReconciler) handleStatefulset(cr *loggingService.LoggingService) error {
+func (r *GraylogReconciler) handleStatefulset(cr *loggingService.LoggingService, majorVersion int) error {
 	m, err := graylogStatefulset(cr)
 	if err != nil {
 		r.Log.Error(err, "Failed creating Statefulset manifest")
@@ -109,17 +157,9 @@ func (r *GraylogReconciler) handleStatefulset(cr *loggingService.LoggingService)
 	}
 	for i, container := range m.Spec.Template.Spec.InitContainers {
 		if container.Name == "download-plugins" {
-			var graylogVersionEnv corev1.EnvVar
-			if r.checkGraylog5(cr) {
-				graylogVersionEnv = corev1.EnvVar{
-					Name:  "GRAYLOG_VERSION",
-					Value: "5",
-				}
-			} else {
-				graylogVersionEnv = corev1.EnvVar{
-					Name:  "GRAYLOG_VERSION",
-					Value: "4",
-				}
+			graylogVersionEnv := corev1.EnvVar{
+				Name:  "GRAYLOG_VERSION",
+				Value: strconv.Itoa(majorVersion),
 			}
 			m.Spec.Template.Spec.InitContainers[i].Env = append(m.Spec.Template.Spec.InitContainers[i].Env, graylogVersionEnv)
 		}
@@ -131,6 +171,15 @@ func (r *GraylogReconciler) handleStatefulset(cr *loggingService.LoggingService)
 			if err = r.GetResource(e); err != nil {
 				return err
 			}
+			// Volume claim templates and service name can't be changed, so the StatefulSet
+			// is recreated when Graylog is switched between the single node and the cluster
+			if e.Spec.ServiceName != m.Spec.ServiceName || len(e.Spec.VolumeClaimTemplates) != len(m.Spec.VolumeClaimTemplates) {
+				r.Log.Info("Graylog deployment mode is changed, recreate Statefulset")
+				if err = r.recreateStatefulset(e); err != nil {
+					return err
+				}
+				return r.handleStatefulset(cr, majorVersion)
+			}
 
 			//Set parameters
 			if e.Labels == nil && m.Labels != nil {
@@ -143,6 +192,13 @@ func (r *GraylogReconciler) handleStatefulset(cr *loggingService.LoggingService)
 			e.Spec.Replicas = m.Spec.Replicas
 			e.Spec.Selector = m.Spec.Selector
 			e.Spec.Template.SetLabels(m.Spec.Template.GetLabels())
+			// Other annotations are kept, e.g. the restart time set by kubectl
+			if e.Spec.Template.Annotations == nil {
+				e.Spec.Template.Annotations = map[string]string{}
+			}
+			for k, v := range m.Spec.Template.Annotations {
+				e.Spec.Template.Annotations[k] = v
+			}
 			e.Spec.Template.Spec.SecurityContext = m.Spec.Template.Spec.SecurityContext
 			e.Spec.Template.Spec.Containers = m.Spec.Template.Spec.Containers
 			e.Spec.Template.Spec.InitContainers = m.Spec.Template.Spec.InitContainers
@@ -311,10 +367,10 @@ func (r *GraylogReconciler) deleteConfigMap(cr *loggingService.LoggingService) e
 	return nil
 }
 
-func (r *GraylogReconciler) deleteServiceAccount(cr *loggingService.LoggingService) error {
-	e := &corev1.ServiceAccount{
+func (r *GraylogReconciler) deleteLookupTablesConfigMap(cr *loggingService.LoggingService) error {
+	e := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      util.GraylogServiceAccountName,
+			Name:      util.GraylogLookupTablesConfigMap,
 			Namespace: cr.GetNamespace(),
 		},
 	}
@@ -324,16 +380,13 @@ func (r *GraylogReconciler) deleteServiceAccount(cr *loggingService.LoggingServi
 		}
 		return err
 	}
-	if err := r.DeleteResource(e); err != nil {
-		return err
-	}
-	return nil
+	return r.DeleteResource(e)
 }
 
-func (r *GraylogReconciler) deleteJob(cr *loggingService.LoggingService, jobName string) error {
-	e := &batchv1.Job{
+func (r *GraylogReconciler) deleteServiceAccount(cr *loggingService.LoggingService) error {
+	e := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      jobName,
+			Name:      util.GraylogServiceAccountName,
 			Namespace: cr.GetNamespace(),
 		},
 	}
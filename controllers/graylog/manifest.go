@@ -1,16 +1,24 @@
 package graylog
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	"github.com/Netcracker/qubership-logging-operator/controllers/graylog/utils"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //go:embed  assets/*.yaml
@@ -19,6 +27,11 @@ var assets embed.FS
 //go:embed  config/*
 var configs embed.FS
 
+// configChecksumAnnotation keeps the checksum of the Graylog configuration in the pod template.
+// The configuration is mounted by subPath and is not refreshed in the running pods,
+// so the pods are restarted by the StatefulSet when it is changed, e.g. with the new root password
+const configChecksumAnnotation = "logging.qubership.org/config-checksum"
+
 func graylogServiceAccount(cr *loggingService.LoggingService) (*corev1.ServiceAccount, error) {
 	sa := corev1.ServiceAccount{}
 	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, util.GraylogServiceAccount), util.GraylogServiceAccount, cr.ToParams())
@@ -59,7 +72,60 @@ func graylogConfigMap(cr *loggingService.LoggingService) (*corev1.ConfigMap, err
 	return &configMap, nil
 }
 
-func graylogMongoUpgradeJob(cr *loggingService.LoggingService, assetPath string) (*batchv1.Job, error) {
+// graylogLookupTablesConfigMap builds the ConfigMap with the CSV files of the inmemory data adapters of the lookup tables.
+// It returns nil if there are no such data adapters
+func graylogLookupTablesConfigMap(cr *loggingService.LoggingService) (*corev1.ConfigMap, error) {
+	data, err := utils.InMemoryLookupData(cr)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	configMap := corev1.ConfigMap{}
+	configMap.SetGroupVersionKind(schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"})
+	configMap.SetName(util.GraylogLookupTablesConfigMap)
+	configMap.SetNamespace(cr.GetNamespace())
+	configMap.Data = data
+	configMap.SetLabels(map[string]string{
+		"name":                         util.GraylogLookupTablesConfigMap,
+		"app.kubernetes.io/name":       util.GraylogLookupTablesConfigMap,
+		"app.kubernetes.io/instance":   util.GetInstanceLabel(configMap.GetName(), configMap.GetNamespace()),
+		"app.kubernetes.io/version":    util.GetTagFromImage(cr.Spec.Graylog.DockerImage),
+		"app.kubernetes.io/component":  "graylog",
+		"app.kubernetes.io/part-of":    "logging",
+		"app.kubernetes.io/managed-by": "logging-operator",
+	})
+	return &configMap, nil
+}
+
+// addLookupTableVolumes mounts the ConfigMaps with the files of the data adapters into the Graylog container.
+// The ConfigMaps are mounted without subPath, so the changed files are delivered to the running pods
+// and are reloaded by the data adapters without the restart of Graylog. The ConfigMaps are optional,
+// so Graylog is started also when the ConfigMap with the CSV file is deleted, only its data adapter fails then
+func addLookupTableVolumes(cr *loggingService.LoggingService, statefulset *appsv1.StatefulSet) {
+	podSpec := &statefulset.Spec.Template.Spec
+	optional := true
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != util.GraylogStatefulsetName {
+			continue
+		}
+		for j, configMap := range utils.LookupTableConfigMaps(cr) {
+			name := fmt.Sprintf("lookup-tables-%d", j)
+			podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+				Name: name,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}, Optional: &optional},
+				},
+			})
+			podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      name,
+				MountPath: utils.LookupTableFile(configMap, ""),
+				ReadOnly:  true,
+			})
+		}
+	}
+}
+
+// graylogMongoJob builds Jobs which work with the MongoDB database: the pre-upgrade backup and restore of the backup
+func graylogMongoJob(cr *loggingService.LoggingService, assetPath string) (*batchv1.Job, error) {
 	job := batchv1.Job{}
 	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, assetPath), assetPath, cr.ToParams())
 	if err != nil {
@@ -76,6 +142,98 @@ func graylogMongoUpgradeJob(cr *loggingService.LoggingService, assetPath string)
 	return &job, nil
 }
 
+// mongoPreUpgradeBackupParameters are parameters of the Job which copies the database files before the upgrade
+type mongoPreUpgradeBackupParameters struct {
+	loggingService.LoggingServiceParameters
+	JobName string
+	// TargetVersion is the version of the last pending step, the backup is taken once for it
+	TargetVersion string
+}
+
+func graylogMongoPreUpgradeBackupJob(cr *loggingService.LoggingService, targetVersion string) (*batchv1.Job, error) {
+	job := batchv1.Job{}
+	parameters := mongoPreUpgradeBackupParameters{
+		LoggingServiceParameters: cr.ToParams(),
+		JobName:                  mongoPreUpgradeBackupJobName(targetVersion),
+		TargetVersion:            targetVersion,
+	}
+	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, util.GraylogMongoPreUpgradeBackup), util.GraylogMongoPreUpgradeBackup, parameters)
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.NewYAMLOrJSONDecoder(strings.NewReader(fileContent), util.BufferSize).Decode(&job); err != nil {
+		return nil, err
+	}
+	//Add required labels
+	job.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(job.GetName(), job.GetNamespace())
+	job.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(job.Spec.Template.Spec.Containers[0].Image)
+	job.Spec.Template.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(job.GetName(), job.GetNamespace())
+	job.Spec.Template.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(job.Spec.Template.Spec.Containers[0].Image)
+	return &job, nil
+}
+
+// mongoProbeParameters are parameters of the Job which reads featureCompatibilityVersion of the database
+type mongoProbeParameters struct {
+	loggingService.LoggingServiceParameters
+	JobName string
+	// Images are tried from the first one until mongod of the image opens the database
+	Images []string
+}
+
+func graylogMongoProbeJob(cr *loggingService.LoggingService, images []string) (*batchv1.Job, error) {
+	job := batchv1.Job{}
+	parameters := mongoProbeParameters{
+		LoggingServiceParameters: cr.ToParams(),
+		JobName:                  util.GraylogMongoProbeJobName,
+		Images:                   images,
+	}
+	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, util.GraylogMongoProbeJob), util.GraylogMongoProbeJob, parameters)
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.NewYAMLOrJSONDecoder(strings.NewReader(fileContent), util.BufferSize).Decode(&job); err != nil {
+		return nil, err
+	}
+	//Add required labels
+	job.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(job.GetName(), job.GetNamespace())
+	job.Spec.Template.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(job.GetName(), job.GetNamespace())
+	return &job, nil
+}
+
+// mongoUpgradeParameters are parameters of the Job of the MongoDB upgrade step
+type mongoUpgradeParameters struct {
+	loggingService.LoggingServiceParameters
+	JobName         string
+	Step            loggingService.MongoDBUpgradeStep
+	PreviousVersion string
+	// Confirm is required by setFeatureCompatibilityVersion since MongoDB 7.0
+	Confirm bool
+}
+
+func graylogMongoUpgradeJob(cr *loggingService.LoggingService, step loggingService.MongoDBUpgradeStep, previousVersion string) (*batchv1.Job, error) {
+	job := batchv1.Job{}
+	parameters := mongoUpgradeParameters{
+		LoggingServiceParameters: cr.ToParams(),
+		JobName:                  mongoUpgradeJobName(step.Version),
+		Step:                     step,
+		PreviousVersion:          previousVersion,
+		Confirm:                  mongoMajorVersion(step.Version) >= 7,
+	}
+	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, util.GraylogMongoUpgradeJob), util.GraylogMongoUpgradeJob, parameters)
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.NewYAMLOrJSONDecoder(strings.NewReader(fileContent), util.BufferSize).Decode(&job); err != nil {
+		return nil, err
+	}
+	//Add required labels
+	job.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(job.GetName(), job.GetNamespace())
+	job.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(step.Image)
+	job.Spec.Template.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(job.GetName(), job.GetNamespace())
+	job.Spec.Template.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(step.Image)
+	return &job, nil
+}
+
 func graylogStatefulset(cr *loggingService.LoggingService) (*appsv1.StatefulSet, error) {
 	statefulset := appsv1.StatefulSet{}
 	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, util.GraylogStatefulset), util.GraylogStatefulset, cr.ToParams())
@@ -87,10 +245,18 @@ func graylogStatefulset(cr *loggingService.LoggingService) (*appsv1.StatefulSet,
 	}
 
 	if cr.Spec.Graylog != nil {
+		configMap, err := graylogConfigMap(cr)
+		if err != nil {
+			return nil, err
+		}
+		templateAnnotations := map[string]string{configChecksumAnnotation: configChecksum(configMap.Data)}
 		if cr.Spec.Graylog.Annotations != nil {
 			statefulset.SetAnnotations(cr.Spec.Graylog.Annotations)
-			statefulset.Spec.Template.SetAnnotations(cr.Spec.Graylog.Annotations)
+			for key, val := range cr.Spec.Graylog.Annotations {
+				templateAnnotations[key] = val
+			}
 		}
+		statefulset.Spec.Template.SetAnnotations(templateAnnotations)
 		//Add required labels
 		statefulset.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(statefulset.GetName(), statefulset.GetNamespace())
 		statefulset.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(cr.Spec.Graylog.DockerImage)
@@ -110,10 +276,29 @@ func graylogStatefulset(cr *loggingService.LoggingService) (*appsv1.StatefulSet,
 		if len(strings.TrimSpace(cr.Spec.Graylog.PriorityClassName)) > 0 {
 			statefulset.Spec.Template.Spec.PriorityClassName = cr.Spec.Graylog.PriorityClassName
 		}
+
+		addLookupTableVolumes(cr, &statefulset)
 	}
 	return &statefulset, nil
 }
 
+// configChecksum returns the SHA-256 checksum of the ConfigMap data sorted by the file names
+func configChecksum(data map[string]string) string {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(data[name]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func graylogService(cr *loggingService.LoggingService) (*corev1.Service, error) {
 	service := corev1.Service{}
 	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, util.GraylogService), util.GraylogService, cr.ToParams())
@@ -128,3 +313,220 @@ func graylogService(cr *loggingService.LoggingService) (*corev1.Service, error)
 	service.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(cr.Spec.Graylog.DockerImage)
 	return &service, nil
 }
+
+// graylogHAService builds Services of the Graylog cluster and MongoDB which are not needed for the single Graylog pod
+func graylogHAService(cr *loggingService.LoggingService, assetPath string) (*corev1.Service, error) {
+	service := corev1.Service{}
+	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, assetPath), assetPath, cr.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.NewYAMLOrJSONDecoder(strings.NewReader(fileContent), util.BufferSize).Decode(&service); err != nil {
+		return nil, err
+	}
+	//Add required labels
+	service.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(service.GetName(), service.GetNamespace())
+	service.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(cr.Spec.Graylog.DockerImage)
+	return &service, nil
+}
+
+func graylogPodDisruptionBudget(cr *loggingService.LoggingService, assetPath string) (*policyv1.PodDisruptionBudget, error) {
+	pdb := policyv1.PodDisruptionBudget{}
+	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, assetPath), assetPath, cr.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.NewYAMLOrJSONDecoder(strings.NewReader(fileContent), util.BufferSize).Decode(&pdb); err != nil {
+		return nil, err
+	}
+	//Add required labels
+	pdb.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(pdb.GetName(), pdb.GetNamespace())
+	pdb.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(cr.Spec.Graylog.DockerImage)
+	return &pdb, nil
+}
+
+func graylogMongoBackupCronJob(cr *loggingService.LoggingService) (*batchv1.CronJob, error) {
+	cronJob := batchv1.CronJob{}
+	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, util.GraylogMongoBackupCronJob), util.GraylogMongoBackupCronJob, cr.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.NewYAMLOrJSONDecoder(strings.NewReader(fileContent), util.BufferSize).Decode(&cronJob); err != nil {
+		return nil, err
+	}
+	image := cr.Spec.Graylog.MongoDBImage
+	if cr.Spec.Graylog.Backup.Image != "" {
+		image = cr.Spec.Graylog.Backup.Image
+	}
+	//Add required labels
+	cronJob.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(cronJob.GetName(), cronJob.GetNamespace())
+	cronJob.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(image)
+	cronJob.Spec.JobTemplate.Spec.Template.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(cronJob.GetName(), cronJob.GetNamespace())
+	cronJob.Spec.JobTemplate.Spec.Template.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(image)
+	if cr.Spec.Graylog.Affinity != nil {
+		cronJob.Spec.JobTemplate.Spec.Template.Spec.Affinity = cr.Spec.Graylog.Affinity
+	}
+	return &cronJob, nil
+}
+
+func graylogMongoStatefulset(cr *loggingService.LoggingService) (*appsv1.StatefulSet, error) {
+	statefulset := appsv1.StatefulSet{}
+	fileContent, err := util.ParseTemplate(util.MustAssetReader(assets, util.GraylogMongoStatefulset), util.GraylogMongoStatefulset, cr.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.NewYAMLOrJSONDecoder(strings.NewReader(fileContent), util.BufferSize).Decode(&statefulset); err != nil {
+		return nil, err
+	}
+	//Add required labels
+	statefulset.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(statefulset.GetName(), statefulset.GetNamespace())
+	statefulset.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(cr.Spec.Graylog.MongoDBImage)
+	statefulset.Spec.Template.Labels["app.kubernetes.io/instance"] = util.GetInstanceLabel(statefulset.GetName(), statefulset.GetNamespace())
+	statefulset.Spec.Template.Labels["app.kubernetes.io/version"] = util.GetTagFromImage(cr.Spec.Graylog.MongoDBImage)
+	if cr.Spec.Graylog.Affinity != nil {
+		statefulset.Spec.Template.Spec.Affinity = cr.Spec.Graylog.Affinity
+	}
+	if len(strings.TrimSpace(cr.Spec.Graylog.PriorityClassName)) > 0 {
+		statefulset.Spec.Template.Spec.PriorityClassName = cr.Spec.Graylog.PriorityClassName
+	}
+	return &statefulset, nil
+}
+
+// haManifests returns resources of the Graylog cluster and the MongoDB replica set deployed by the operator
+func haManifests(cr *loggingService.LoggingService) ([]client.Object, error) {
+	var objects []client.Object
+	if cr.Spec.Graylog.IsManagedMongoDB() {
+		service, err := graylogHAService(cr, util.GraylogMongoService)
+		if err != nil {
+			return nil, err
+		}
+		statefulset, err := graylogMongoStatefulset(cr)
+		if err != nil {
+			return nil, err
+		}
+		pdb, err := graylogPodDisruptionBudget(cr, util.GraylogMongoPodDisruptionBudget)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, service, statefulset, pdb)
+	}
+	if cr.Spec.Graylog.IsHA() {
+		for _, assetPath := range []string{util.GraylogNodesService, util.GraylogInputService} {
+			service, err := graylogHAService(cr, assetPath)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, service)
+		}
+		pdb, err := graylogPodDisruptionBudget(cr, util.GraylogPodDisruptionBudget)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, pdb)
+	}
+	return objects, nil
+}
+
+// backupManifests returns the CronJob with MongoDB backups and the Service which makes the MongoDB container
+// of the Graylog pod available for it
+func backupManifests(cr *loggingService.LoggingService) ([]client.Object, error) {
+	if !cr.Spec.Graylog.IsBackupEnabled() {
+		return nil, nil
+	}
+	var objects []client.Object
+	if cr.Spec.Graylog.IsEmbeddedMongoDB() {
+		service, err := graylogHAService(cr, util.GraylogEmbeddedMongoService)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, service)
+	}
+	cronJob, err := graylogMongoBackupCronJob(cr)
+	if err != nil {
+		return nil, err
+	}
+	return append(objects, cronJob), nil
+}
+
+func validateBackup(backup *loggingService.GraylogBackup) error {
+	if backup.PersistentVolumeClaim == "" && backup.S3 == nil {
+		return errors.New("configuration error: graylog.backup.persistentVolumeClaim or graylog.backup.s3 is required")
+	}
+	if backup.PersistentVolumeClaim != "" && backup.S3 != nil {
+		return errors.New("configuration error: graylog.backup.persistentVolumeClaim and graylog.backup.s3 can't be used together")
+	}
+	if s3 := backup.S3; s3 != nil {
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.Image == "" || s3.AccessKeySecret == nil || s3.SecretKeySecret == nil {
+			return errors.New("configuration error: graylog.backup.s3 requires endpoint, bucket, image, accessKeySecret and secretKeySecret")
+		}
+	}
+	return nil
+}
+
+func validate(cr *loggingService.LoggingService) error {
+	graylog := cr.Spec.Graylog
+	if graylog.IsExternalMongoDB() && graylog.IsManagedMongoDB() {
+		return errors.New("configuration error: graylog.mongoDB.uriSecret and graylog.mongoDB.replicaSet can't be used together")
+	}
+	if graylog.IsHA() && graylog.IsEmbeddedMongoDB() {
+		return errors.New("configuration error: graylog.mongoDB.uriSecret or graylog.mongoDB.replicaSet is required if graylog.replicas is more than 1")
+	}
+	if graylog.MongoDBUpgrade != nil {
+		for _, step := range graylog.MongoDBUpgrade.UpgradeSteps(graylog.MongoDBImage) {
+			if mongoMajorVersion(step.Version) == 0 || step.Image == "" {
+				return fmt.Errorf("configuration error: step %q of graylog.mongoDBUpgrade requires version like 6.0 and image", step.Version)
+			}
+		}
+	}
+	for i, contentPack := range graylog.ContentPacks {
+		// The content from the cluster doesn't need the checksum and the OCI artifact is verified by its digest
+		if contentPack != nil && contentPack.Checksum != "" && contentPack.URL == "" {
+			return fmt.Errorf("configuration error: graylog.contentPacks[%d].checksum is supported only with url, pin the OCI artifact by the digest instead", i)
+		}
+	}
+	if graylog.IsBackupEnabled() {
+		return validateBackup(graylog.Backup)
+	}
+	return nil
+}
+
+// Manifests returns all Kubernetes resources of the component built from the custom resource.
+// MongoDB upgrade and restore Jobs are not included because they depend on the state of the running Graylog.
+func Manifests(cr *loggingService.LoggingService) ([]client.Object, error) {
+	if err := validate(cr); err != nil {
+		return nil, err
+	}
+	serviceAccount, err := graylogServiceAccount(cr)
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := graylogConfigMap(cr)
+	if err != nil {
+		return nil, err
+	}
+	statefulset, err := graylogStatefulset(cr)
+	if err != nil {
+		return nil, err
+	}
+	service, err := graylogService(cr)
+	if err != nil {
+		return nil, err
+	}
+	ha, err := haManifests(cr)
+	if err != nil {
+		return nil, err
+	}
+	backup, err := backupManifests(cr)
+	if err != nil {
+		return nil, err
+	}
+	objects := append([]client.Object{serviceAccount, configMap, statefulset, service}, ha...)
+	lookupTablesConfigMap, err := graylogLookupTablesConfigMap(cr)
+	if err != nil {
+		return nil, err
+	}
+	if lookupTablesConfigMap != nil {
+		objects = append(objects, lookupTablesConfigMap)
+	}
+	return append(objects, backup...), nil
+}
@@ -0,0 +1,272 @@
+package graylog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// mongoPreviousVersions are the featureCompatibilityVersions MongoDB can be upgraded from to the version.
+// MongoDB can't skip major versions, so the step checks the database has the previous version before the upgrade
+var mongoPreviousVersions = map[string]string{
+	"4.0": "3.6",
+	"4.2": "4.0",
+	"4.4": "4.2",
+	"5.0": "4.4",
+	"6.0": "5.0",
+	"7.0": "6.0",
+	"8.0": "7.0",
+}
+
+func mongoUpgradeJobName(version string) string {
+	return "mongo-upgrade-job-" + strings.ReplaceAll(version, ".", "")
+}
+
+// mongoPreUpgradeBackupJobName returns the name of the backup Job before the upgrade to the version,
+// so the backup is taken again when the steps to the newer version are added
+func mongoPreUpgradeBackupJobName(version string) string {
+	return util.GraylogMongoPreUpgradeBackupJob + "-" + strings.ReplaceAll(version, ".", "")
+}
+
+// mongoMajorVersion returns the major part of the version like "6.0" or 0 if the version is incorrect
+func mongoMajorVersion(version string) int {
+	major, err := strconv.Atoi(strings.Split(version, ".")[0])
+	if err != nil {
+		return 0
+	}
+	return major
+}
+
+// compareMongoVersions compares the versions like "6.0" by the major and the minor parts
+func compareMongoVersions(a string, b string) int {
+	majorA, minorA := mongoVersionParts(a)
+	majorB, minorB := mongoVersionParts(b)
+	if majorA != majorB {
+		return majorA - majorB
+	}
+	return minorA - minorB
+}
+
+func mongoVersionParts(version string) (int, int) {
+	parts := strings.SplitN(version, ".", 3)
+	minor := 0
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+	return mongoMajorVersion(version), minor
+}
+
+// pendingMongoUpgradeSteps returns the steps above featureCompatibilityVersion which is set by the last completed
+// step or read from the database. The steps at or below it are completed, e.g. by the previous version of the operator
+func pendingMongoUpgradeSteps(steps []loggingService.MongoDBUpgradeStep, status *loggingService.MongoDBUpgradeStatus) []loggingService.MongoDBUpgradeStep {
+	if status == nil || status.CompletedVersion == "" {
+		return steps
+	}
+	var pending []loggingService.MongoDBUpgradeStep
+	for _, step := range steps {
+		if compareMongoVersions(step.Version, status.CompletedVersion) > 0 {
+			pending = append(pending, step)
+		}
+	}
+	return pending
+}
+
+// lastGoodMongoDBImage returns the image which is known to open the database after the failed step: the image
+// of the last completed step or the image which read featureCompatibilityVersion of the database.
+// It is empty if no image is known to open the database, so MongoDB must not be rolled back
+func lastGoodMongoDBImage(cr *loggingService.LoggingService) string {
+	if status := cr.Status.MongoDBUpgrade; status != nil {
+		return status.Image
+	}
+	return ""
+}
+
+// mongoProbeImages returns the images of the steps from the newest one without duplicates
+func mongoProbeImages(steps []loggingService.MongoDBUpgradeStep) []string {
+	var images []string
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Image != "" && !slices.Contains(images, steps[i].Image) {
+			images = append(images, steps[i].Image)
+		}
+	}
+	return images
+}
+
+// parseMongoProbeResult parses the termination message of the probe Job with featureCompatibilityVersion
+// and the image which opened the database
+func parseMongoProbeResult(message string) (*loggingService.MongoDBUpgradeStatus, error) {
+	fields := strings.Fields(message)
+	if len(fields) != 2 || mongoMajorVersion(fields[0]) == 0 {
+		return nil, fmt.Errorf("unexpected result of MongoDB probe: %q", message)
+	}
+	return &loggingService.MongoDBUpgradeStatus{CompletedVersion: fields[0], Image: fields[1]}, nil
+}
+
+// probeMongoDB runs the Job which reads featureCompatibilityVersion of the database with the newest image
+// of the steps which opens it. The Job is deleted after the run, so the database is read again
+// if the result is not recorded in the status
+func (r *GraylogReconciler) probeMongoDB(cr *loggingService.LoggingService, steps []loggingService.MongoDBUpgradeStep) (*loggingService.MongoDBUpgradeStatus, error) {
+	m, err := graylogMongoProbeJob(cr, mongoProbeImages(steps))
+	if err != nil {
+		r.Log.Error(err, "Failed creating Job for MongoDB probe manifest")
+		return nil, err
+	}
+	if err = r.CreateResource(cr, m); err != nil && !api_errors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Client.Delete(context.TODO(), m, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !api_errors.IsNotFound(err) {
+			r.Log.Error(err, "Can not delete Job "+m.GetName())
+		}
+	}()
+
+	time.Sleep(util.InitialDelay)
+	podManager := util.NewPodManager(r.Client, cr.GetNamespace(), r.Log)
+	succeeded, err := podManager.WaitForJobSucceeded(m.GetName(), util.GraylogMongoUpgradeJobTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if !succeeded {
+		return nil, fmt.Errorf("job %s can't open MongoDB database with images %s", m.GetName(), strings.Join(mongoProbeImages(steps), ", "))
+	}
+	pods, err := podManager.FindPods(map[string]string{"app.kubernetes.io/name": m.GetName()})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.State.Terminated != nil && container.State.Terminated.Message != "" {
+				return parseMongoProbeResult(container.State.Terminated.Message)
+			}
+		}
+	}
+	return nil, fmt.Errorf("job %s didn't return featureCompatibilityVersion of MongoDB", m.GetName())
+}
+
+// mongoUpgrade runs the pending steps of the MongoDB upgrade. Before the first step featureCompatibilityVersion
+// of the database is read, so the steps completed before are skipped. Before the pending steps the database files
+// are copied once for each target version of the upgrade. Completed steps are recorded in the status,
+// so the upgrade is resumed from the next step. The upgrade stops on the failed step,
+// the step is run again when its Job is deleted
+func (r *GraylogReconciler) mongoUpgrade(cr *loggingService.LoggingService) error {
+	steps := cr.Spec.Graylog.MongoDBUpgrade.UpgradeSteps(cr.Spec.Graylog.MongoDBImage)
+	status := cr.Status.MongoDBUpgrade
+	probe := status == nil
+	if probe {
+		// The database is opened only by one MongoDB, so Graylog is scaled down before the probe
+		if err := r.scaleDownStatefulset(cr); err != nil {
+			return err
+		}
+		var err error
+		if status, err = r.probeMongoDB(cr, steps); err != nil {
+			return err
+		}
+		r.Log.Info(fmt.Sprintf("MongoDB database has featureCompatibilityVersion %s and is opened by the image %s", status.CompletedVersion, status.Image))
+	}
+	pending := pendingMongoUpgradeSteps(steps, status)
+	if len(pending) == 0 {
+		if probe {
+			r.StatusUpdater.UpdateMongoDBUpgradeStatus(status)
+		}
+		return nil
+	}
+	targetVersion := pending[len(pending)-1].Version
+	backup := cr.Spec.Graylog.IsBackupEnabled() && status.BackupVersion != targetVersion
+
+	jobNames := []string{mongoUpgradeJobName(pending[0].Version)}
+	if backup {
+		jobNames = append(jobNames, mongoPreUpgradeBackupJobName(targetVersion))
+	}
+	for _, jobName := range jobNames {
+		failed, err := r.isJobFailed(cr, jobName)
+		if err != nil {
+			return err
+		}
+		if failed {
+			return fmt.Errorf("job %s of MongoDB upgrade failed, delete it to run the upgrade again", jobName)
+		}
+	}
+
+	// Scale down the Graylog statefulset before starting upgrade jobs to avoid conflicts between MongoDB instances
+	if !probe {
+		if err := r.scaleDownStatefulset(cr); err != nil {
+			return err
+		}
+	}
+	if backup {
+		// Keep the copy of the database files, because the upgrade steps change them in place
+		m, err := graylogMongoPreUpgradeBackupJob(cr, targetVersion)
+		if err != nil {
+			r.Log.Error(err, "Failed creating Job for MongoDB backup manifest")
+			return err
+		}
+		if err = r.handleMongoUpgradeJob(cr, m); err != nil {
+			return err
+		}
+		status = &loggingService.MongoDBUpgradeStatus{
+			CompletedVersion: status.CompletedVersion,
+			Image:            status.Image,
+			FailedVersion:    status.FailedVersion,
+			BackupVersion:    targetVersion,
+		}
+	}
+	if probe || backup {
+		// The result of the probe is recorded after the backup, so the backup is done on the next run if it fails
+		r.StatusUpdater.UpdateMongoDBUpgradeStatus(status)
+	}
+	// Run jobs in particular order
+	for _, step := range pending {
+		m, err := graylogMongoUpgradeJob(cr, step, mongoPreviousVersions[step.Version])
+		if err != nil {
+			r.Log.Error(err, "Failed creating Job for MongoDB upgrade manifest")
+			return err
+		}
+		if err = r.handleMongoUpgradeJob(cr, m); err != nil {
+			r.StatusUpdater.UpdateMongoDBUpgradeStatus(&loggingService.MongoDBUpgradeStatus{
+				CompletedVersion: status.CompletedVersion,
+				Image:            status.Image,
+				FailedVersion:    step.Version,
+				BackupVersion:    status.BackupVersion,
+			})
+			return err
+		}
+		status = &loggingService.MongoDBUpgradeStatus{CompletedVersion: step.Version, Image: step.Image, BackupVersion: status.BackupVersion}
+		r.StatusUpdater.UpdateMongoDBUpgradeStatus(status)
+	}
+	return nil
+}
+
+func (r *GraylogReconciler) isJobFailed(cr *loggingService.LoggingService, jobName string) (bool, error) {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: jobName, Namespace: cr.GetNamespace()}}
+	if err := r.GetResource(job); err != nil {
+		if api_errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deleteUpgradeJobs deletes Jobs of all upgrade steps and the pre-upgrade backup
+func (r *GraylogReconciler) deleteUpgradeJobs(cr *loggingService.LoggingService) error {
+	return r.Client.DeleteAllOf(context.TODO(), &batchv1.Job{},
+		client.InNamespace(cr.GetNamespace()),
+		client.MatchingLabels(util.GraylogMongoUpgradeLabels),
+		client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
@@ -0,0 +1,147 @@
+package graylog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var mongoUpgradeSteps = (&loggingService.MongoDBUpgrade{
+	MongoDBImage40: "mongo:4.0",
+	MongoDBImage42: "mongo:4.2",
+	MongoDBImage44: "mongo:4.4",
+}).UpgradeSteps("mongo:5.0")
+
+var pendingMongoUpgradeStepsTests = []struct {
+	description string
+	status      *loggingService.MongoDBUpgradeStatus
+	pending     []string
+}{
+	{
+		description: "All steps are pending without status",
+		pending:     []string{"4.0", "4.2", "4.4", "5.0"},
+	},
+	{
+		description: "All steps are pending for the database with featureCompatibilityVersion 3.6",
+		status:      &loggingService.MongoDBUpgradeStatus{CompletedVersion: "3.6", Image: "mongo:3.6"},
+		pending:     []string{"4.0", "4.2", "4.4", "5.0"},
+	},
+	{
+		description: "Steps after the completed one are pending",
+		status:      &loggingService.MongoDBUpgradeStatus{CompletedVersion: "4.2", Image: "mongo:4.2"},
+		pending:     []string{"4.4", "5.0"},
+	},
+	{
+		description: "Failed step is pending",
+		status:      &loggingService.MongoDBUpgradeStatus{CompletedVersion: "4.2", Image: "mongo:4.2", FailedVersion: "4.4"},
+		pending:     []string{"4.4", "5.0"},
+	},
+	{
+		description: "No steps are pending for the database upgraded before",
+		status:      &loggingService.MongoDBUpgradeStatus{CompletedVersion: "5.0", Image: "mongo:5.0"},
+	},
+	{
+		description: "No steps are pending for the database with the newer featureCompatibilityVersion",
+		status:      &loggingService.MongoDBUpgradeStatus{CompletedVersion: "6.0", Image: "mongo:6.0"},
+	},
+}
+
+func TestPendingMongoUpgradeSteps(t *testing.T) {
+	for _, test := range pendingMongoUpgradeStepsTests {
+		var pending []string
+		for _, step := range pendingMongoUpgradeSteps(mongoUpgradeSteps, test.status) {
+			pending = append(pending, step.Version)
+		}
+		if !reflect.DeepEqual(pending, test.pending) {
+			t.Errorf("%s: expected pending steps %v, got %v", test.description, test.pending, pending)
+		}
+	}
+}
+
+var lastGoodMongoDBImageTests = []struct {
+	description string
+	status      *loggingService.MongoDBUpgradeStatus
+	image       string
+}{
+	{
+		// The database may be upgraded before, so the image of the first step can't open it
+		description: "No image is known without status",
+	},
+	{
+		description: "Image which read featureCompatibilityVersion is used before the first completed step",
+		status:      &loggingService.MongoDBUpgradeStatus{CompletedVersion: "5.0", Image: "mongo:5.0", FailedVersion: "6.0"},
+		image:       "mongo:5.0",
+	},
+	{
+		description: "Image of the last completed step is used",
+		status:      &loggingService.MongoDBUpgradeStatus{CompletedVersion: "4.2", Image: "mongo:4.2", FailedVersion: "4.4"},
+		image:       "mongo:4.2",
+	},
+}
+
+func TestLastGoodMongoDBImage(t *testing.T) {
+	for _, test := range lastGoodMongoDBImageTests {
+		cr := &loggingService.LoggingService{Status: loggingService.LoggingServiceStatus{MongoDBUpgrade: test.status}}
+		if image := lastGoodMongoDBImage(cr); image != test.image {
+			t.Errorf("%s: expected image %q, got %q", test.description, test.image, image)
+		}
+	}
+}
+
+var parseMongoProbeResultTests = []struct {
+	description string
+	message     string
+	status      *loggingService.MongoDBUpgradeStatus
+}{
+	{
+		description: "Version and image are read",
+		message:     "5.0\nmongo:5.0\n",
+		status:      &loggingService.MongoDBUpgradeStatus{CompletedVersion: "5.0", Image: "mongo:5.0"},
+	},
+	{
+		description: "Empty version is rejected",
+		message:     "\nmongo:5.0\n",
+	},
+}
+
+func TestParseMongoProbeResult(t *testing.T) {
+	for _, test := range parseMongoProbeResultTests {
+		status, err := parseMongoProbeResult(test.message)
+		if (err != nil) != (test.status == nil) || !reflect.DeepEqual(status, test.status) {
+			t.Errorf("%s: expected %+v, got %+v (error: %v)", test.description, test.status, status, err)
+		}
+	}
+}
+
+func TestMongoProbeImages(t *testing.T) {
+	steps := append(mongoUpgradeSteps, loggingService.MongoDBUpgradeStep{Version: "5.0", Image: "mongo:5.0"})
+	expected := []string{"mongo:5.0", "mongo:4.4", "mongo:4.2", "mongo:4.0"}
+	if images := mongoProbeImages(steps); !reflect.DeepEqual(images, expected) {
+		t.Errorf("expected images %v, got %v", expected, images)
+	}
+}
+
+func TestGraylogMongoPreUpgradeBackupJob(t *testing.T) {
+	cr := &loggingService.LoggingService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "logging"},
+		Spec: loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				MongoDBImage: "mongo:7.0",
+				Backup:       &loggingService.GraylogBackup{Install: true, PersistentVolumeClaim: "graylog-backup"},
+			},
+		},
+	}
+	job, err := graylogMongoPreUpgradeBackupJob(cr, "7.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.GetName() != "graylog-mongo-pre-upgrade-backup-70" {
+		t.Errorf("expected the Job for the target version 7.0, got %s", job.GetName())
+	}
+	if command := job.Spec.Template.Spec.Containers[0].Command[2]; !strings.Contains(command, "graylog-pre-upgrade-7.0-") {
+		t.Errorf("expected the archive with the target version, got %s", command)
+	}
+}
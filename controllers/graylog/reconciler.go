@@ -3,8 +3,7 @@ package graylog
 import (
 	"context"
 	"errors"
-	"regexp"
-	"strings"
+	"fmt"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
 	"github.com/Netcracker/qubership-logging-operator/controllers/graylog/utils"
@@ -13,7 +12,6 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -21,13 +19,14 @@ type GraylogReconciler struct {
 	*util.ComponentReconciler
 }
 
-func NewGraylogReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater) GraylogReconciler {
+func NewGraylogReconciler(client client.Client, scheme *runtime.Scheme, updater util.StatusUpdater, recorder util.EventRecorder) GraylogReconciler {
 	return GraylogReconciler{
 		ComponentReconciler: &util.ComponentReconciler{
 			Client:        client,
 			Scheme:        scheme,
 			Log:           util.Logger("graylog"),
 			StatusUpdater: updater,
+			EventRecorder: recorder,
 		},
 	}
 }
@@ -41,10 +40,31 @@ func (r *GraylogReconciler) Run(ctx context.Context, cr *loggingService.LoggingS
 	r.Log.Info("Start Graylog reconciliation")
 
 	if cr.Spec.Graylog != nil && cr.Spec.Graylog.IsInstall() {
+		if err := validate(cr); err != nil {
+			r.Log.Error(err, "configuration of Graylog is incorrect")
+			r.EventRecorder.Warning(util.ReasonValidationFailed, err.Error())
+			return err
+		}
+		rotationTrigger := cr.CredentialsRotationTrigger()
+		if rotationTrigger != "" {
+			if err := r.generateCredentials(cr, rotationTrigger); err != nil {
+				r.EventRecorder.Warning(util.ReasonCredentialsFailed, err.Error())
+				return err
+			}
+		}
+		// Credentials are read before the connector is created, so the rotated password is used
+		// by the connector and rendered in the configuration in the same reconcile
+		userPasswords, err := r.setCredentials(cr)
+		if err != nil {
+			r.EventRecorder.Warning(util.ReasonValidationFailed, err.Error())
+			return err
+		}
 		connector, err := utils.CreateConnector(ctx, cr, configs, clientSet)
 		if err != nil {
 			return err
 		}
+		connector.EventRecorder = r.EventRecorder
+		connector.UserPasswords = userPasswords
 
 		if err = r.handleServiceAccount(cr); err != nil {
 			return err
@@ -52,69 +72,181 @@ func (r *GraylogReconciler) Run(ctx context.Context, cr *loggingService.LoggingS
 		if err = r.handleConfigMap(cr); err != nil {
 			return err
 		}
+		if err = r.handleLookupTablesConfigMap(cr); err != nil {
+			return err
+		}
 		if err = r.deleteDeployment(cr); err != nil {
 			r.Log.Error(err, "Can not delete Deployment")
 		}
-		if cr.Spec.Graylog.MongoDBUpgrade != nil && r.checkGraylog5(cr) {
-			if err = r.mongoUpgrade(cr); err != nil {
-				r.Log.Error(err, "MongoDB upgrade failed. Try to continue the reconciliation anyway")
+		majorVersion := r.graylogMajorVersion(cr, connector)
+		var upgradeErr error
+		if cr.Spec.Graylog.MongoDBUpgrade != nil && majorVersion >= 5 && cr.Spec.Graylog.IsEmbeddedMongoDB() {
+			if upgradeErr = r.mongoUpgrade(cr); upgradeErr != nil {
+				// The database can't be opened by the new MongoDB, so Graylog is started with the last good image.
+				// The image is not changed if no image is known to open the database
+				if image := lastGoodMongoDBImage(cr); image != "" {
+					cr.Spec.Graylog.MongoDBImage = image
+					r.Log.Error(upgradeErr, "MongoDB upgrade failed. MongoDB is rolled back to the image "+image)
+				} else {
+					r.Log.Error(upgradeErr, "MongoDB upgrade failed. No image is known to open the database, MongoDB is not rolled back")
+				}
 			}
 		} else {
 			if err = r.deleteUpgradeJobs(cr); err != nil {
 				r.Log.Error(err, "Can not delete MongoDB upgrade jobs")
 			}
 		}
-		if err = r.handleStatefulset(cr); err != nil {
+		if err = r.handleMongoReplicaSet(cr); err != nil {
+			return err
+		}
+		if err = r.handleRestore(cr); err != nil {
+			return err
+		}
+		if err = r.handleStatefulset(cr, majorVersion); err != nil {
 			return err
 		}
 		if err = r.handleService(cr); err != nil {
 			return err
 		}
-
-		if cr.Spec.Graylog.ContentDeployPolicy != "skip" {
-			if err = r.configureGraylog(ctx, connector, cr, clientSet); err != nil {
+		if upgradeErr != nil {
+			return upgradeErr
+		}
+		if err = r.handleHAResources(cr, connector); err != nil {
+			return err
+		}
+		if err = r.handleBackup(cr); err != nil {
+			return err
+		}
+		if rotationTrigger != "" {
+			if err = r.completeCredentialsRotation(cr, connector, rotationTrigger); err != nil {
+				r.EventRecorder.Warning(util.ReasonCredentialsFailed, err.Error())
 				return err
 			}
 		}
-		if err = r.watchSecret(cr); err != nil {
-			r.Log.Error(err, "Error occurred while starting watch Secret")
+		if err = r.handleAccessToken(cr, connector); err != nil {
+			r.Log.Error(err, "Can not use the access token of Graylog user "+util.GraylogServiceUserName)
+			return err
 		}
 
+		if cr.IsPaused(loggingService.PausedGraylogContent) {
+			r.Log.Info("Management of Graylog content is paused by the annotation " + loggingService.PausedAnnotation)
+		} else if cr.Spec.Graylog.ContentDeployPolicy != "skip" {
+			err = r.configureGraylog(ctx, connector, cr, clientSet)
+			// The created objects are recorded also when the configuration fails in the middle
+			r.StatusUpdater.UpdateExternalObjectsStatus(connector.ExternalObjects)
+			if err != nil {
+				return err
+			}
+		}
 	} else {
 		r.Log.Info("Uninstalling component if exists")
-		r.uninstall(cr)
+		r.Uninstall(cr)
 	}
 	r.Log.Info("Component reconciled")
 	r.StatusUpdater.RemoveStatus(util.GraylogStatus)
 	return nil
 }
 
-// uninstall deletes all resources related to the component
-func (r *GraylogReconciler) uninstall(cr *loggingService.LoggingService) {
+// Uninstall deletes all resources related to the component
+func (r *GraylogReconciler) Uninstall(cr *loggingService.LoggingService) {
 	if err := r.deletePVC(util.GraylogClaimName, cr); err != nil {
 		r.Log.Error(err, "Can not delete graylog PVC")
 	}
 	if err := r.deletePVC(util.MongoClaimName, cr); err != nil {
 		r.Log.Error(err, "Can not delete mongo PVC")
 	}
+	if err := r.deleteHAClaims(cr); err != nil {
+		r.Log.Error(err, "Can not delete PVCs of Graylog nodes")
+	}
 	if err := r.deleteStatefulset(cr); err != nil {
 		r.Log.Error(err, "Can not delete Statefulset")
 	}
+	if err := r.deleteHAResources(cr); err != nil {
+		r.Log.Error(err, "Can not delete resources of Graylog cluster")
+	}
+	if err := r.deleteMongoReplicaSet(cr); err != nil {
+		r.Log.Error(err, "Can not delete MongoDB replica set")
+	}
 	if err := r.deleteService(cr); err != nil {
 		r.Log.Error(err, "Can not delete Service")
 	}
 	if err := r.deleteConfigMap(cr); err != nil {
 		r.Log.Error(err, "Can not delete ConfigMap")
 	}
+	if err := r.deleteLookupTablesConfigMap(cr); err != nil {
+		r.Log.Error(err, "Can not delete ConfigMap with lookup tables")
+	}
 	if err := r.deleteServiceAccount(cr); err != nil {
 		r.Log.Error(err, "Can not delete ServiceAccount")
 	}
 	if err := r.deleteUpgradeJobs(cr); err != nil {
 		r.Log.Error(err, "Can not delete MongoDB upgrade jobs")
 	}
+	if err := r.deleteBackup(cr); err != nil {
+		r.Log.Error(err, "Can not delete MongoDB backup resources")
+	}
+	if err := r.deleteAccessToken(cr); err != nil {
+		r.Log.Error(err, "Can not delete Secret with the access token")
+	}
+}
+
+// DeleteExternalObjects deletes objects created by the operator in Graylog and OpenSearch.
+// Graylog must be still running, so it has to be called before Uninstall.
+func (r *GraylogReconciler) DeleteExternalObjects(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+	var userPasswords map[string]string
+	if cr.Spec.Graylog.GraylogSecretName != "" {
+		var err error
+		if userPasswords, err = r.setCredentials(cr); err != nil {
+			return err
+		}
+	}
+	connector, err := utils.CreateConnector(ctx, cr, configs, clientSet)
+	if err != nil {
+		return err
+	}
+	connector.EventRecorder = r.EventRecorder
+	connector.UserPasswords = userPasswords
+
+	if err = connector.DeleteAuthentication(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteAlerts(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteOutputs(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteLookupTables(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteCustomUserAccounts(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteGraylogObjects(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteSnapshotPolicies(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteOpenSearchObjects(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteOpensearchConfigs(cr); err != nil {
+		return err
+	}
+	if err = connector.DeleteArchivesDirectory(cr); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (r *GraylogReconciler) configureGraylog(ctx context.Context, connector *utils.GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+	capabilities, err := connector.ProbeVersion()
+	if err != nil {
+		return err
+	}
+	r.Log.Info("Detected Graylog version " + capabilities.Version.String())
+
 	if cr.Spec.Graylog.AuthProxy.Install {
 		if err := connector.ManageAuthHeaderConfig(cr); err != nil {
 			return err
@@ -133,7 +265,7 @@ func (r *GraylogReconciler) configureGraylog(ctx context.Context, connector *uti
 		return err
 	}
 
-	if err := connector.ManageExtractors(cr, r.checkGraylog5(cr)); err != nil {
+	if err := connector.ManageExtractors(cr, capabilities); err != nil {
 		return err
 	}
 
@@ -141,6 +273,11 @@ func (r *GraylogReconciler) configureGraylog(ctx context.Context, connector *uti
 		return err
 	}
 
+	// Lookup tables are created before the processing rules, so the rules can use them in the lookup functions
+	if err := connector.ManageLookupTables(cr); err != nil {
+		return err
+	}
+
 	if err := connector.ManageProcessingRules(cr); err != nil {
 		return err
 	}
@@ -153,8 +290,10 @@ func (r *GraylogReconciler) configureGraylog(ctx context.Context, connector *uti
 		return err
 	}
 
-	if cr.Spec.Graylog.ContentPackPaths != "" {
-		if err := connector.ManageContentPacks(cr); err != nil {
+	if cr.Spec.Graylog.ContentPackPaths != "" || cr.Spec.Graylog.ContentPacks != nil || cr.Status.ContentPacks != nil {
+		contentPacks, err := connector.ManageContentPacks(ctx, cr, clientSet)
+		r.StatusUpdater.UpdateContentPacksStatus(contentPacks)
+		if err != nil {
 			return err
 		}
 
@@ -163,37 +302,89 @@ func (r *GraylogReconciler) configureGraylog(ctx context.Context, connector *uti
 		}
 	}
 
-	if cr.Spec.Graylog.ContentPacks != nil {
-		if err := connector.ManageContentPackTLS(ctx, cr, clientSet); err != nil {
-			return err
-		}
+	if err := connector.ManageArchivesDirectory(cr); err != nil {
+		return err
+	}
 
-		if err := connector.ManageOpensearchConfigs(cr); err != nil {
-			return err
-		}
+	if err := connector.ManageSnapshotPolicies(cr); err != nil {
+		return err
 	}
 
-	if err := connector.ManageArchivesDirectory(cr); err != nil {
+	r.updateOpenSearchHealth(connector)
+
+	if err := connector.ManageOpenSearchObjects(cr); err != nil {
 		return err
 	}
 
-	if err := connector.ManageSavedSearches(cr); err != nil {
+	if err := connector.ManageSavedSearches(ctx, cr, clientSet); err != nil {
 		return err
 	}
 
-	if err := connector.ManageUserAccounts(cr, r.checkGraylog5(cr)); err != nil {
+	if err := connector.ManageUserAccounts(cr, capabilities); err != nil {
 		return err
 	}
 
+	if err := connector.ManageCustomUserAccounts(ctx, cr, clientSet, capabilities); err != nil {
+		return err
+	}
+
+	if err := connector.ManageAlerts(ctx, cr, clientSet); err != nil {
+		return err
+	}
+
+	if err := connector.ManageOutputs(ctx, cr, clientSet); err != nil {
+		return err
+	}
+
+	if cr.Spec.Graylog.Authentication != nil {
+		result, err := connector.ManageAuthentication(ctx, cr, clientSet)
+		if err != nil {
+			return err
+		}
+		if result.Activated {
+			r.StatusUpdater.UpdateStatus(util.GraylogAuthenticationStatus, util.Success, true, result.Message)
+		} else {
+			r.StatusUpdater.UpdateStatus(util.GraylogAuthenticationStatus, util.Failed, false, result.Message)
+		}
+	} else if connector.ExternalObjects != nil && len(connector.ExternalObjects.AuthBackends) > 0 {
+		// The section is removed from the spec, so the backends created by the operator are deleted
+		if err := connector.DeleteAuthentication(cr); err != nil {
+			return err
+		}
+		r.StatusUpdater.RemoveStatus(util.GraylogAuthenticationStatus)
+	}
+
 	return nil
 }
 
-func (r *GraylogReconciler) setCredentials(cr *loggingService.LoggingService) error {
+// updateOpenSearchHealth shows the health of the OpenSearch cluster in the status. Graylog keeps working
+// with the yellow cluster, so the reconciliation is not failed because of the health
+func (r *GraylogReconciler) updateOpenSearchHealth(connector *utils.GraylogConnector) {
+	health, err := connector.GetOpenSearchHealth()
+	if err != nil {
+		r.Log.Error(err, "Can not get OpenSearch cluster health")
+		r.StatusUpdater.UpdateStatus(util.OpenSearchHealthStatus, util.Failed, false, "OpenSearch cluster health is unknown")
+		return
+	}
+	message := fmt.Sprintf("OpenSearch cluster health is %s. Nodes: %d, unassigned shards: %d", health.Status, health.NumberOfNodes, health.UnassignedShards)
+	switch health.Status {
+	case "green":
+		r.StatusUpdater.UpdateStatus(util.OpenSearchHealthStatus, util.Success, true, message)
+	case "yellow":
+		r.StatusUpdater.UpdateStatus(util.OpenSearchHealthStatus, util.Success, false, message)
+	default:
+		r.StatusUpdater.UpdateStatus(util.OpenSearchHealthStatus, util.Failed, false, message)
+	}
+}
+
+// setCredentials reads the credentials of the admin user from the Graylog Secret.
+// It returns the passwords of the users created by the operator which are set in the Secret
+func (r *GraylogReconciler) setCredentials(cr *loggingService.LoggingService) (map[string]string, error) {
 	secret := &corev1.Secret{}
 	if err := r.Client.Get(context.TODO(), types.NamespacedName{
 		Name: cr.Spec.Graylog.GraylogSecretName, Namespace: cr.GetNamespace(),
 	}, secret); err != nil {
-		return err
+		return nil, err
 	}
 
 	var usr string
@@ -201,7 +392,7 @@ func (r *GraylogReconciler) setCredentials(cr *loggingService.LoggingService) er
 		usr = string(secret.Data["user"])
 	} else {
 		err := errors.New("can not find user for Graylog in the secret " + cr.Spec.Graylog.GraylogSecretName + " in the namespace " + cr.GetNamespace())
-		return err
+		return nil, err
 	}
 	cr.Spec.Graylog.User = usr
 
@@ -210,56 +401,34 @@ func (r *GraylogReconciler) setCredentials(cr *loggingService.LoggingService) er
 		pwd = string(secret.Data["password"])
 	} else {
 		err := errors.New("can not find password for Graylog in the secret " + cr.Spec.Graylog.GraylogSecretName + " in the namespace " + cr.GetNamespace())
-		return err
+		return nil, err
 	}
 	cr.Spec.Graylog.Password = pwd
-	return nil
-}
 
-func (r *GraylogReconciler) watchSecret(cr *loggingService.LoggingService) error {
-	config, err := ctrl.GetConfig()
-	if err != nil {
-		return err
-	}
-	k8sClient, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return err
-	}
-	w := utils.SecretEventWatcher{
-		Log:       util.Logger("watch-secret"),
-		Clientset: k8sClient,
+	userPasswords := map[string]string{}
+	for _, user := range graylogUserPasswordKeys {
+		if password := secret.Data[user[1]]; len(password) > 0 {
+			userPasswords[user[0]] = string(password)
+		}
 	}
-	go w.Watch(cr, r.Client)
-	return nil
+	return userPasswords, nil
 }
 
-func (r *GraylogReconciler) mongoUpgrade(cr *loggingService.LoggingService) error {
-	// Scale down the Graylog statefulset before starting upgrade jobs to avoid conflicts between MongoDB instances
-	if err := r.scaleDownStatefulset(cr); err != nil {
-		return err
+// graylogMajorVersion returns the major version of Graylog from the spec or the tag of its image, because Graylog
+// may be not started yet. The version of the running Graylog is used for the images without it, e.g. pinned by digest.
+// It is wrong during the upgrade of such image, so graylog.version has to be set for them
+func (r *GraylogReconciler) graylogMajorVersion(cr *loggingService.LoggingService, connector *utils.GraylogConnector) int {
+	if version, ok := utils.ParseGraylogVersion(cr.Spec.Graylog.Version); ok {
+		return version.Major
 	}
-	// Run jobs in particular order
-	for _, jobName := range util.GraylogMongoUpgradeOrderedJobs {
-		assetPath := util.GraylogMongoUpgradeAssets[jobName]
-		if err := r.handleMongoUpgradeJob(cr, jobName, assetPath); err != nil {
-			return err
-		}
+	if version, ok := utils.ImageVersion(cr.Spec.Graylog.DockerImage); ok {
+		return version.Major
 	}
-	return nil
-}
-
-func (r *GraylogReconciler) deleteUpgradeJobs(cr *loggingService.LoggingService) error {
-	for jobName := range util.GraylogMongoUpgradeAssets {
-		if err := r.deleteJob(cr, jobName); err != nil {
-			return err
-		}
+	if capabilities, err := connector.ProbeVersion(); err == nil {
+		r.Log.Info(fmt.Sprintf("Version of Graylog image %s is not known, the version %s of the running Graylog is used. Set graylog.version for the images pinned by digest",
+			cr.Spec.Graylog.DockerImage, capabilities.Version))
+		return capabilities.Version.Major
 	}
-	return nil
-}
-
-func (r *GraylogReconciler) checkGraylog5(cr *loggingService.LoggingService) bool {
-	// Parsing of the major version of the Graylog image
-	re := regexp.MustCompile(`([0-9]+)\.([0-9]+)\.([0-9]+)`)
-	majorVersion := strings.Split(re.FindString(cr.Spec.Graylog.DockerImage), ".")[0]
-	return majorVersion == "5"
+	r.Log.Info(fmt.Sprintf("Can't detect Graylog version of the image %s. Graylog %d is assumed", cr.Spec.Graylog.DockerImage, utils.LatestGraylogMajorVersion))
+	return utils.LatestGraylogMajorVersion
 }
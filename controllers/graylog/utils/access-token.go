@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+)
+
+const (
+	// accessTokenName is the name of the access token of the operator service user
+	accessTokenName = "logging-operator"
+	// accessTokenPassword is sent as the password with the access token as the username
+	accessTokenPassword = "token"
+)
+
+// EnsureServiceUser creates the role and the user which the operator uses instead of the root user
+// and returns the id of the user. The permissions of the role are updated if they are changed in the template.
+// The user has a random password, it's used only with access tokens
+func (connector *GraylogConnector) EnsureServiceUser() (string, error) {
+	var role graylogClient.Role
+	if err := json.Unmarshal([]byte(util.MustAssetReader(connector.Assets, util.GraylogServiceUserRole)), &role); err != nil {
+		return "", err
+	}
+	existing, err := connector.Client.GetRole(connector.context(), role.Name)
+	switch {
+	case graylogClient.IsNotFound(err):
+		if err = connector.Client.CreateRole(connector.context(), role); err != nil {
+			return "", fmt.Errorf("can't create role %s: %w", role.Name, err)
+		}
+		connector.recordCreated("role", role.Name)
+	case err != nil:
+		return "", err
+	case !reflect.DeepEqual(existing.Permissions, role.Permissions):
+		if err = connector.Client.UpdateRole(connector.context(), role.Name, role); err != nil {
+			return "", fmt.Errorf("can't update role %s: %w", role.Name, err)
+		}
+		connector.recordUpdated("role", role.Name)
+	}
+
+	if id := connector.GetUserIdByName(util.GraylogServiceUserName); id != "" {
+		return id, nil
+	}
+	var user graylogClient.User
+	if err = json.Unmarshal([]byte(util.MustAssetReader(connector.Assets, util.GraylogServiceUser)), &user); err != nil {
+		return "", err
+	}
+	if user.Password, err = util.GeneratePassword(32); err != nil {
+		return "", err
+	}
+	if err = connector.Client.CreateUser(connector.context(), user); err != nil {
+		return "", fmt.Errorf("can't create user %s: %w", user.Username, err)
+	}
+	connector.recordCreated("user", user.Username)
+	id := connector.GetUserIdByName(user.Username)
+	if id == "" {
+		return "", fmt.Errorf("user %s is not found after its creation", user.Username)
+	}
+	return id, nil
+}
+
+// CreateAccessToken mints the new access token of the user and revokes its previous tokens of the operator
+func (connector *GraylogConnector) CreateAccessToken(userId string) (string, error) {
+	tokens, err := connector.Client.ListUserTokens(connector.context(), userId)
+	if err != nil {
+		return "", err
+	}
+	for _, token := range tokens {
+		if token.Name == accessTokenName {
+			if err = connector.Client.DeleteUserToken(connector.context(), userId, token.Id); err != nil && !graylogClient.IsNotFound(err) {
+				return "", err
+			}
+		}
+	}
+	token, err := connector.Client.CreateUserToken(connector.context(), userId, accessTokenName)
+	if err != nil {
+		return "", fmt.Errorf("can't create access token of user %s: %w", util.GraylogServiceUserName, err)
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("access token of user %s is empty", util.GraylogServiceUserName)
+	}
+	return token.Token, nil
+}
+
+// IsAccessTokenValid checks that the token is accepted by Graylog. It returns false if the token is revoked
+func (connector *GraylogConnector) IsAccessTokenValid(token string) (bool, error) {
+	statusCode, err := connector.login(token, accessTokenPassword)
+	if err != nil {
+		return false, err
+	}
+	return statusCode == http.StatusOK, nil
+}
+
+// UseAccessToken authenticates all following requests to Graylog with the access token instead of the root user
+func (connector *GraylogConnector) UseAccessToken(token string) {
+	connector.RestClient.Auth = &util.Сreds{Name: token, Password: accessTokenPassword}
+}
@@ -0,0 +1,430 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	eventDefinitionsUrl   = "events/definitions"
+	eventNotificationsUrl = "events/notifications"
+	// allEventsStreamId is the id of the default Graylog stream where all events are stored
+	allEventsStreamId = "000000000000000000000002"
+
+	defaultAlertSearchWithinMinutes = 5
+	defaultAlertPriority            = 2
+
+	defaultNotificationBody = `--- [Event Definition] ---------------------------
+Title:       ${event_definition_title}
+Description: ${event_definition_description}
+--- [Event] --------------------------------------
+Timestamp:   ${event.timestamp}
+Message:     ${event.message}
+Source:      ${event.source}
+${if backlog}--- [Backlog] ------------------------------------
+${foreach backlog message}${message.timestamp}  ::  ${message.source}  ::  ${message.message}
+${end}${end}`
+)
+
+var notificationTypes = map[string]string{
+	"http":  "http-notification-v1",
+	"email": "email-notification-v1",
+	"slack": "slack-notification-v1",
+}
+
+type notification struct {
+	Id          string                 `json:"id,omitempty"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Config      map[string]interface{} `json:"config"`
+}
+
+type eventDefinition struct {
+	Id                   string                  `json:"id,omitempty"`
+	Title                string                  `json:"title"`
+	Description          string                  `json:"description"`
+	Priority             int                     `json:"priority"`
+	Alert                bool                    `json:"alert"`
+	Config               eventDefinitionConfig   `json:"config"`
+	FieldSpec            map[string]interface{}  `json:"field_spec"`
+	KeySpec              []string                `json:"key_spec"`
+	NotificationSettings notificationSettings    `json:"notification_settings"`
+	Notifications        []notificationReference `json:"notifications"`
+	Storage              []eventStorage          `json:"storage"`
+}
+
+type eventDefinitionConfig struct {
+	Type            string                 `json:"type"`
+	Query           string                 `json:"query"`
+	QueryParameters []interface{}          `json:"query_parameters"`
+	Streams         []string               `json:"streams"`
+	GroupBy         []string               `json:"group_by"`
+	Series          []aggregationSeries    `json:"series"`
+	Conditions      *aggregationConditions `json:"conditions"`
+	SearchWithinMs  int64                  `json:"search_within_ms"`
+	ExecuteEveryMs  int64                  `json:"execute_every_ms"`
+}
+
+type aggregationSeries struct {
+	Id       string  `json:"id"`
+	Function string  `json:"function"`
+	Field    *string `json:"field"`
+}
+
+type aggregationConditions struct {
+	Expression expression `json:"expression"`
+}
+
+type expression struct {
+	Expr  string      `json:"expr"`
+	Left  *expression `json:"left,omitempty"`
+	Right *expression `json:"right,omitempty"`
+	Ref   string      `json:"ref,omitempty"`
+	Value *int        `json:"value,omitempty"`
+}
+
+type notificationSettings struct {
+	GracePeriodMs int64 `json:"grace_period_ms"`
+	BacklogSize   int   `json:"backlog_size"`
+}
+
+type notificationReference struct {
+	NotificationId string `json:"notification_id"`
+}
+
+type eventStorage struct {
+	Type    string   `json:"type"`
+	Streams []string `json:"streams"`
+}
+
+// GetDefaultAlerts returns event definitions for the Audit logs stream installed with alerts.installDefault
+func GetDefaultAlerts(notifications []string) []loggingService.GraylogEventDefinition {
+	return []loggingService.GraylogEventDefinition{
+		{
+			Title:         "Audit: cluster-admin binding created",
+			Description:   "ClusterRoleBinding or RoleBinding to the cluster-admin role is created",
+			Priority:      3,
+			Streams:       []string{util.GraylogAuditStream},
+			Query:         `message:(clusterrolebindings OR rolebindings) AND message:"cluster-admin" AND message:"\"verb\":\"create\""`,
+			BacklogSize:   5,
+			Notifications: notifications,
+		},
+		{
+			Title:       "Audit: forbidden requests spike",
+			Description: "More than 100 requests to the Kubernetes API are forbidden within 5 minutes",
+			Priority:    2,
+			Streams:     []string{util.GraylogAuditStream},
+			Query:       `message:"\"code\":403"`,
+			Aggregation: &loggingService.GraylogAggregation{
+				Function:  "count",
+				Condition: ">",
+				Threshold: 100,
+			},
+			BacklogSize:   5,
+			Notifications: notifications,
+		},
+	}
+}
+
+func (connector *GraylogConnector) GetAllEventNotifications() ([]Entity, error) {
+	return connector.GetData(eventNotificationsUrl+"?per_page=1000", "notifications", nil)
+}
+
+func (connector *GraylogConnector) GetAllEventDefinitions() ([]Entity, error) {
+	return connector.GetData(eventDefinitionsUrl+"?per_page=1000", "event_definitions", nil)
+}
+
+// ManageAlerts creates notifications and event definitions described in the custom resource.
+// Existing objects are updated only with the force-update content deploy policy. The objects created
+// by the operator before and removed from the custom resource are deleted
+func (connector *GraylogConnector) ManageAlerts(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+	alerts := cr.Spec.Graylog.Alerts
+	if alerts == nil {
+		alerts = &loggingService.GraylogAlerts{}
+	}
+	objects := connector.externalObjects()
+
+	var notificationTitles []string
+	if len(alerts.Notifications) > 0 {
+		notifications, err := connector.GetAllEventNotifications()
+		if err != nil {
+			return err
+		}
+		for _, n := range alerts.Notifications {
+			body, err := connector.notificationBody(ctx, n, cr.GetNamespace(), clientSet)
+			if err != nil {
+				return err
+			}
+			notificationTitles = append(notificationTitles, n.Title)
+			trackObject(&objects.Notifications, n.Title)
+			if err = connector.createOrUpdateAlertObject(cr, eventNotificationsUrl, "", "notification", n.Title, GetIdByTitle(notifications, n.Title), body); err != nil {
+				return err
+			}
+		}
+	}
+
+	definitions := alerts.EventDefinitions
+	var definitionTitles []string
+	if alerts.InstallDefault || len(definitions) > 0 {
+		streams, err := connector.GetAllStreams()
+		if err != nil {
+			return err
+		}
+		if alerts.InstallDefault {
+			// The default event definitions search the Audit logs stream, so they are skipped without it
+			if GetIdByTitle(streams, util.GraylogAuditStream) == "" {
+				connector.EventRecorder.Warning(util.ReasonValidationFailed, "Default event definitions are not installed: stream "+util.GraylogAuditStream+" not found")
+			} else {
+				definitions = append(GetDefaultAlerts(alerts.DefaultNotifications), definitions...)
+			}
+		}
+
+		// Reload notifications to get ids of created ones
+		notifications, err := connector.GetAllEventNotifications()
+		if err != nil {
+			return err
+		}
+		eventDefinitions, err := connector.GetAllEventDefinitions()
+		if err != nil {
+			return err
+		}
+		for _, d := range definitions {
+			body, err := eventDefinitionBody(d, streams, notifications)
+			if err != nil {
+				return err
+			}
+			definitionTitles = append(definitionTitles, d.Title)
+			trackObject(&objects.EventDefinitions, d.Title)
+			if err = connector.createOrUpdateAlertObject(cr, eventDefinitionsUrl, "?schedule=true", "event definition", d.Title, GetIdByTitle(eventDefinitions, d.Title), body); err != nil {
+				return err
+			}
+		}
+	}
+
+	return connector.deleteRemovedAlerts(definitionTitles, notificationTitles)
+}
+
+func (connector *GraylogConnector) createOrUpdateAlertObject(cr *loggingService.LoggingService, url string, query string, objectType string, title string, id string, body interface{}) error {
+	if id != "" && !cr.Spec.Graylog.IsForceUpdate() {
+		return nil
+	}
+
+	method := http.MethodPost
+	if id != "" {
+		method = http.MethodPut
+		url = url + "/" + id
+		switch b := body.(type) {
+		case *notification:
+			b.Id = id
+		case *eventDefinition:
+			b.Id = id
+		}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	response, statusCode, err := connector.Send(url+query, method, string(data))
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK && statusCode != http.StatusCreated {
+		return fmt.Errorf("can't save %s %s. Status code: %v. Response: %s", objectType, title, statusCode, response)
+	}
+	if id == "" {
+		connector.recordCreated(objectType, title)
+	} else {
+		connector.recordUpdated(objectType, title)
+	}
+	return nil
+}
+
+func (connector *GraylogConnector) notificationBody(ctx context.Context, n loggingService.GraylogNotification, namespace string, clientSet kubernetes.Interface) (*notification, error) {
+	notificationType, found := notificationTypes[n.Type]
+	if !found {
+		return nil, fmt.Errorf("unknown type %s of notification %s", n.Type, n.Title)
+	}
+
+	url := n.URL
+	if n.URLSecret != nil && n.URLSecret.Name != "" {
+		secret, err := clientSet.CoreV1().Secrets(namespace).Get(ctx, n.URLSecret.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		value, found := secret.Data[n.URLSecret.Key]
+		if !found {
+			return nil, fmt.Errorf("can't find key %s in Secret %s for notification %s", n.URLSecret.Key, n.URLSecret.Name, n.Title)
+		}
+		url = string(value)
+	}
+
+	message := n.CustomMessage
+	if message == "" {
+		message = defaultNotificationBody
+	}
+
+	config := map[string]interface{}{"type": notificationType}
+	switch n.Type {
+	case "http":
+		config["url"] = url
+	case "email":
+		config["sender"] = n.Sender
+		config["subject"] = n.Subject
+		config["body_template"] = message
+		config["email_recipients"] = n.Recipients
+		config["user_recipients"] = []string{}
+		config["time_zone"] = "UTC"
+	case "slack":
+		config["webhook_url"] = url
+		config["channel"] = n.Channel
+		config["custom_message"] = message
+		config["color"] = "#FF0000"
+		config["backlog_size"] = 0
+		config["notify_channel"] = false
+		config["link_names"] = false
+		config["time_zone"] = "UTC"
+	}
+	return &notification{Title: n.Title, Description: n.Description, Config: config}, nil
+}
+
+func eventDefinitionBody(d loggingService.GraylogEventDefinition, streams []Entity, notifications []Entity) (*eventDefinition, error) {
+	streamIds := []string{}
+	for _, title := range d.Streams {
+		id := GetIdByTitle(streams, title)
+		if id == "" {
+			return nil, fmt.Errorf("stream %s of event definition %s not found", title, d.Title)
+		}
+		streamIds = append(streamIds, id)
+	}
+	notificationRefs := []notificationReference{}
+	for _, title := range d.Notifications {
+		id := GetIdByTitle(notifications, title)
+		if id == "" {
+			return nil, fmt.Errorf("notification %s of event definition %s not found", title, d.Title)
+		}
+		notificationRefs = append(notificationRefs, notificationReference{NotificationId: id})
+	}
+
+	searchWithin := d.SearchWithinMinutes
+	if searchWithin == 0 {
+		searchWithin = defaultAlertSearchWithinMinutes
+	}
+	executeEvery := d.ExecuteEveryMinutes
+	if executeEvery == 0 {
+		executeEvery = searchWithin
+	}
+	priority := d.Priority
+	if priority == 0 {
+		priority = defaultAlertPriority
+	}
+
+	config := eventDefinitionConfig{
+		Type:            "aggregation-v1",
+		Query:           d.Query,
+		QueryParameters: []interface{}{},
+		Streams:         streamIds,
+		GroupBy:         []string{},
+		Series:          []aggregationSeries{},
+		SearchWithinMs:  int64(searchWithin) * 60 * 1000,
+		ExecuteEveryMs:  int64(executeEvery) * 60 * 1000,
+	}
+	if a := d.Aggregation; a != nil {
+		seriesId := a.Function + "-" + a.Field
+		var field *string
+		if a.Field != "" {
+			field = &a.Field
+		}
+		if a.GroupBy != nil {
+			config.GroupBy = a.GroupBy
+		}
+		threshold := a.Threshold
+		config.Series = []aggregationSeries{{Id: seriesId, Function: a.Function, Field: field}}
+		config.Conditions = &aggregationConditions{
+			Expression: expression{
+				Expr:  a.Condition,
+				Left:  &expression{Expr: "number-ref", Ref: seriesId},
+				Right: &expression{Expr: "number", Value: &threshold},
+			},
+		}
+	}
+
+	return &eventDefinition{
+		Title:         d.Title,
+		Description:   d.Description,
+		Priority:      priority,
+		Alert:         len(notificationRefs) > 0,
+		Config:        config,
+		FieldSpec:     map[string]interface{}{},
+		KeySpec:       config.GroupBy,
+		Notifications: notificationRefs,
+		NotificationSettings: notificationSettings{
+			GracePeriodMs: int64(d.GracePeriodMinutes) * 60 * 1000,
+			BacklogSize:   d.BacklogSize,
+		},
+		Storage: []eventStorage{{Type: "persist-to-streams-v1", Streams: []string{allEventsStreamId}}},
+	}, nil
+}
+
+// DeleteAlerts deletes event definitions and notifications created by the operator
+func (connector *GraylogConnector) DeleteAlerts(cr *loggingService.LoggingService) error {
+	// The objects may be created before their titles are recorded in the status
+	if alerts := cr.Spec.Graylog.Alerts; alerts != nil {
+		objects := connector.externalObjects()
+		definitions := alerts.EventDefinitions
+		if alerts.InstallDefault {
+			definitions = append(GetDefaultAlerts(nil), definitions...)
+		}
+		for _, d := range definitions {
+			trackObject(&objects.EventDefinitions, d.Title)
+		}
+		for _, n := range alerts.Notifications {
+			trackObject(&objects.Notifications, n.Title)
+		}
+	}
+	return connector.deleteRemovedAlerts(nil, nil)
+}
+
+// deleteRemovedAlerts deletes the event definitions and the notifications created by the operator
+// except the ones with the titles from the custom resource. The event definitions are deleted first,
+// because they refer to the notifications
+func (connector *GraylogConnector) deleteRemovedAlerts(definitionTitles []string, notificationTitles []string) error {
+	objects := connector.externalObjects()
+	if len(removedObjects(objects.EventDefinitions, definitionTitles)) > 0 {
+		eventDefinitions, err := connector.GetAllEventDefinitions()
+		if err != nil {
+			return err
+		}
+		if err = deleteRemovedObjects(&objects.EventDefinitions, definitionTitles, func(title string) error {
+			if id := GetIdByTitle(eventDefinitions, title); id != "" {
+				return connector.deleteObject(eventDefinitionsUrl+"/"+id, "event definition", title)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(removedObjects(objects.Notifications, notificationTitles)) > 0 {
+		notifications, err := connector.GetAllEventNotifications()
+		if err != nil {
+			return err
+		}
+		if err = deleteRemovedObjects(&objects.Notifications, notificationTitles, func(title string) error {
+			if id := GetIdByTitle(notifications, title); id != "" {
+				return connector.deleteObject(eventNotificationsUrl+"/"+id, "notification", title)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,474 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	authBackendsUrl       = "system/authentication/services/backends"
+	activeAuthBackendUrl  = "system/authentication/services/backends/active"
+	authConfigurationUrl  = "system/authentication/services/configuration"
+	authConnectionTestUrl = "system/authentication/services/test/backend/connection"
+	authzRolesUrl         = "authz/roles?per_page=1000"
+	pluginsUrl            = "system/plugins"
+
+	ldapBackend            = "ldap"
+	activeDirectoryBackend = "active-directory"
+	oidcBackend            = "oidc"
+
+	// enterprisePlugin is in the name of the plugin of Graylog Enterprise which provides the oidc backend
+	enterprisePlugin = "Enterprise"
+)
+
+type ldapAttributes struct {
+	searchPattern string
+	uniqueId      string
+	name          string
+	fullName      string
+	emails        []string
+}
+
+// ldapDefaults contains the attributes which are used by Graylog by default for the LDAP backends
+var ldapDefaults = map[string]ldapAttributes{
+	ldapBackend: {
+		searchPattern: "(&(|(objectClass=inetOrgPerson))(|(uid={0})(mail={0})))",
+		uniqueId:      "entryUUID",
+		name:          "uid",
+		fullName:      "cn",
+		emails:        []string{"mail"},
+	},
+	activeDirectoryBackend: {
+		searchPattern: "(&(objectClass=user)(|(sAMAccountName={0})(userPrincipalName={0})))",
+		name:          "userPrincipalName",
+		fullName:      "displayName",
+		emails:        []string{"mail", "userPrincipalName"},
+	},
+}
+
+// AuthenticationResult describes the activation of the authentication backend which is reported in the status
+type AuthenticationResult struct {
+	Activated bool
+	Message   string
+}
+
+type AuthBackendPattern struct {
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	DefaultRoles []string               `json:"default_roles"`
+	Config       map[string]interface{} `json:"config"`
+}
+
+// replaceFuncRoles is needed to unmarshall json response to Entity struct.
+// GET response of roles has titles of roles under key `name`
+func replaceFuncRoles(data string) string {
+	return strings.ReplaceAll(data, `"name":`, `"title":`)
+}
+
+func authBackendTitle(auth *loggingService.GraylogAuthentication) string {
+	if auth.Title != "" {
+		return auth.Title
+	}
+	return "Logging operator " + auth.Type
+}
+
+func readSecretKey(ctx context.Context, clientSet kubernetes.Interface, namespace string, selector *v1.SecretKeySelector) (string, error) {
+	secret, err := clientSet.CoreV1().Secrets(namespace).Get(ctx, selector.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	value, found := secret.Data[selector.Key]
+	if !found {
+		return "", fmt.Errorf("can't find key %s in Secret %s", selector.Key, selector.Name)
+	}
+	return string(value), nil
+}
+
+func ldapServers(servers []string, transportSecurity string) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	for _, server := range servers {
+		host, port := server, 389
+		if transportSecurity == "tls" {
+			port = 636
+		}
+		// The server without the port, including the IPv6 address, is used with the default port
+		if h, p, err := net.SplitHostPort(server); err == nil {
+			if port, err = strconv.Atoi(p); err != nil {
+				return nil, fmt.Errorf("invalid port of LDAP server %s: %w", server, err)
+			}
+			host = h
+		} else {
+			host = strings.TrimSuffix(strings.TrimPrefix(server, "["), "]")
+		}
+		result = append(result, map[string]interface{}{"host": host, "port": port})
+	}
+	return result, nil
+}
+
+// authBackendConfig returns the config of the backend with the secrets read from the Secrets
+func authBackendConfig(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) (map[string]interface{}, error) {
+	auth := cr.Spec.Graylog.Authentication
+	switch auth.Type {
+	case ldapBackend, activeDirectoryBackend:
+		ldap := auth.LDAP
+		if ldap == nil {
+			return nil, fmt.Errorf("ldap settings are required for the %s authentication backend", auth.Type)
+		}
+		transportSecurity := ldap.TransportSecurity
+		if transportSecurity == "" {
+			transportSecurity = "none"
+		}
+		servers, err := ldapServers(ldap.Servers, transportSecurity)
+		if err != nil {
+			return nil, err
+		}
+		password := ""
+		if ldap.SystemUserPasswordSecret != nil {
+			if password, err = readSecretKey(ctx, clientSet, cr.GetNamespace(), ldap.SystemUserPasswordSecret); err != nil {
+				return nil, err
+			}
+		}
+		defaults := ldapDefaults[auth.Type]
+		config := map[string]interface{}{
+			"type":                     auth.Type,
+			"servers":                  servers,
+			"transport_security":       transportSecurity,
+			"verify_certificates":      ldap.VerifyCertificates,
+			"system_user_dn":           ldap.SystemUserDN,
+			"system_user_password":     map[string]interface{}{"set_value": password},
+			"user_search_base":         ldap.UserSearchBase,
+			"user_search_pattern":      valueOrDefault(ldap.UserSearchPattern, defaults.searchPattern),
+			"user_name_attribute":      valueOrDefault(ldap.UserNameAttribute, defaults.name),
+			"user_full_name_attribute": valueOrDefault(ldap.UserFullNameAttribute, defaults.fullName),
+			"email_attributes":         defaults.emails,
+		}
+		if len(ldap.EmailAttributes) > 0 {
+			config["email_attributes"] = ldap.EmailAttributes
+		}
+		if auth.Type == ldapBackend {
+			config["user_unique_id_attribute"] = valueOrDefault(ldap.UserUniqueIDAttribute, defaults.uniqueId)
+		}
+		return config, nil
+	case oidcBackend:
+		oidc := auth.OIDC
+		if oidc == nil {
+			return nil, fmt.Errorf("oidc settings are required for the %s authentication backend", auth.Type)
+		}
+		clientSecret := ""
+		if oidc.ClientSecretSecret != nil {
+			var err error
+			if clientSecret, err = readSecretKey(ctx, clientSet, cr.GetNamespace(), oidc.ClientSecretSecret); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]interface{}{
+			"type":          oidcBackend,
+			"base_url":      oidc.BaseURL,
+			"client_id":     oidc.ClientID,
+			"client_secret": map[string]interface{}{"set_value": clientSecret},
+			"claims":        oidc.Claims,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown type %s of the authentication backend", auth.Type)
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// CreateAuthBackendData resolves names of the default roles to their ids
+func (connector *GraylogConnector) CreateAuthBackendData(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) (string, error) {
+	auth := cr.Spec.Graylog.Authentication
+	config, err := authBackendConfig(ctx, cr, clientSet)
+	if err != nil {
+		return "", err
+	}
+
+	roles, err := connector.GetData(authzRolesUrl, "roles", replaceFuncRoles)
+	if err != nil {
+		return "", err
+	}
+	defaultRoles := auth.DefaultRoles
+	if len(defaultRoles) == 0 {
+		defaultRoles = []string{readerRole}
+	}
+	var roleIds []string
+	for _, role := range defaultRoles {
+		id := GetIdByTitle(roles, role)
+		if id == "" {
+			return "", fmt.Errorf("role %s of the authentication backend not found", role)
+		}
+		roleIds = append(roleIds, id)
+	}
+
+	data, err := json.Marshal(AuthBackendPattern{
+		Title:        authBackendTitle(auth),
+		Description:  auth.Description,
+		DefaultRoles: roleIds,
+		Config:       config,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GetActiveAuthBackend returns the active authentication backend or nil if the backend is not active
+func (connector *GraylogConnector) GetActiveAuthBackend() (*Entity, error) {
+	response, statusCode, err := connector.GET(activeAuthBackendUrl)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't get active authentication backend. Status code: %v. Response: %s", statusCode, response)
+	}
+	var active struct {
+		Backend *Entity `json:"backend"`
+	}
+	if err = json.Unmarshal([]byte(response), &active); err != nil {
+		return nil, err
+	}
+	return active.Backend, nil
+}
+
+// ActivateAuthBackend activates the authentication backend. The empty id deactivates the active backend
+func (connector *GraylogConnector) ActivateAuthBackend(id string) error {
+	var activeBackend interface{}
+	if id != "" {
+		activeBackend = id
+	}
+	data, err := json.Marshal(map[string]interface{}{"active_backend": activeBackend})
+	if err != nil {
+		return err
+	}
+	response, statusCode, err := connector.POST(authConfigurationUrl, string(data))
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("can't activate authentication backend %s. Status code: %v. Response: %s", id, statusCode, response)
+	}
+	return nil
+}
+
+// TestAuthBackend checks the connection of Graylog to the servers of the backend
+func (connector *GraylogConnector) TestAuthBackend(id string, data string) (bool, string, error) {
+	body := fmt.Sprintf(`{"backend_id":%s,"backend_configuration":%s}`, strconv.Quote(id), data)
+	if id == "" {
+		body = fmt.Sprintf(`{"backend_id":null,"backend_configuration":%s}`, data)
+	}
+	response, statusCode, err := connector.POST(authConnectionTestUrl, body)
+	if err != nil {
+		return false, "", err
+	}
+	if statusCode != http.StatusOK {
+		return false, fmt.Sprintf("status code: %v. Response: %s", statusCode, response), nil
+	}
+	var result struct {
+		Success bool     `json:"success"`
+		Message string   `json:"message"`
+		Errors  []string `json:"errors"`
+	}
+	if err = json.Unmarshal([]byte(response), &result); err != nil {
+		return false, "", err
+	}
+	return result.Success, strings.Join(append([]string{result.Message}, result.Errors...), " "), nil
+}
+
+func (connector *GraylogConnector) createOrUpdateAuthBackend(id string, title string, data string, cr *loggingService.LoggingService) (string, error) {
+	if id == "" {
+		response, statusCode, err := connector.POST(authBackendsUrl, data)
+		if err != nil {
+			return "", err
+		}
+		if statusCode != http.StatusOK && statusCode != http.StatusCreated {
+			return "", fmt.Errorf("can't create authentication backend %s. Status code: %v. Response: %s", title, statusCode, response)
+		}
+		var created struct {
+			Backend Entity `json:"backend"`
+		}
+		if err = json.Unmarshal([]byte(response), &created); err != nil {
+			return "", err
+		}
+		connector.recordCreated("authentication backend", title)
+		return created.Backend.Id, nil
+	}
+	if cr.Spec.Graylog.IsForceUpdate() {
+		response, statusCode, err := connector.PUT(authBackendsUrl+"/"+id, data)
+		if err != nil {
+			return "", err
+		}
+		if statusCode != http.StatusOK {
+			return "", fmt.Errorf("can't update authentication backend %s. Status code: %v. Response: %s", title, statusCode, response)
+		}
+		connector.recordUpdated("authentication backend", title)
+	}
+	return id, nil
+}
+
+// ManageAuthentication creates the authentication backend and activates it if Graylog can connect to its servers.
+// The previously active backend is restored if the activation fails.
+func (connector *GraylogConnector) ManageAuthentication(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) (*AuthenticationResult, error) {
+	title := authBackendTitle(cr.Spec.Graylog.Authentication)
+	if cr.Spec.Graylog.Authentication.Type == oidcBackend {
+		enterprise, err := connector.isEnterprise()
+		if err != nil {
+			return nil, err
+		}
+		if !enterprise {
+			result := &AuthenticationResult{Message: fmt.Sprintf("Authentication backend %s is not created, the oidc backend requires Graylog Enterprise", title)}
+			connector.EventRecorder.Warning(util.ReasonValidationFailed, result.Message)
+			return result, nil
+		}
+	}
+	data, err := connector.CreateAuthBackendData(ctx, cr, clientSet)
+	if err != nil {
+		return nil, err
+	}
+	backends, err := connector.GetData(authBackendsUrl, "backends", nil)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := connector.GetActiveAuthBackend()
+	if err != nil {
+		return nil, err
+	}
+	previousId, previousTitle := "", "none"
+	if previous != nil {
+		previousId, previousTitle = previous.Id, previous.Title
+	}
+
+	id := GetIdByTitle(backends, title)
+	success, message, err := connector.TestAuthBackend(id, data)
+	if err != nil {
+		return nil, err
+	}
+	if !success {
+		result := &AuthenticationResult{Message: fmt.Sprintf("Connection test of the authentication backend %s failed: %s. The active backend %s is kept", title, message, previousTitle)}
+		connector.EventRecorder.Warning(util.ReasonAuthBackendFailed, result.Message)
+		return result, nil
+	}
+
+	if id, err = connector.createOrUpdateAuthBackend(id, title, data, cr); err != nil {
+		return nil, err
+	}
+	if objects := connector.externalObjects(); !slices.Contains(objects.AuthBackends, title) {
+		objects.AuthBackends = append(objects.AuthBackends, title)
+	}
+	if id == previousId {
+		// The backends with the previous titles are deleted after the activation
+		if err = connector.deleteAuthBackends(title); err != nil {
+			return nil, err
+		}
+		return &AuthenticationResult{Activated: true, Message: fmt.Sprintf("Authentication backend %s is active", title)}, nil
+	}
+
+	err = connector.ActivateAuthBackend(id)
+	if err == nil {
+		var active *Entity
+		if active, err = connector.GetActiveAuthBackend(); err == nil && (active == nil || active.Id != id) {
+			err = fmt.Errorf("authentication backend %s is not active after the activation", title)
+		}
+	}
+	if err != nil {
+		if rollbackErr := connector.ActivateAuthBackend(previousId); rollbackErr != nil {
+			return nil, fmt.Errorf("%v. Rollback to the backend %s failed: %w", err, previousTitle, rollbackErr)
+		}
+		result := &AuthenticationResult{Message: fmt.Sprintf("Activation of the authentication backend %s failed: %v. Rolled back to the backend %s", title, err, previousTitle)}
+		connector.EventRecorder.Warning(util.ReasonAuthBackendFailed, result.Message)
+		return result, nil
+	}
+
+	result := &AuthenticationResult{Activated: true, Message: fmt.Sprintf("Authentication backend %s is activated instead of %s", title, previousTitle)}
+	connector.EventRecorder.Normal(util.ReasonAuthBackendActivated, result.Message)
+	if err = connector.deleteAuthBackends(title); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// isEnterprise checks that Graylog has the plugin of Graylog Enterprise
+func (connector *GraylogConnector) isEnterprise() (bool, error) {
+	response, statusCode, err := connector.GET(pluginsUrl)
+	if err != nil {
+		return false, err
+	}
+	if statusCode != http.StatusOK {
+		return false, fmt.Errorf("can't get Graylog plugins. Status code: %v. Response: %s", statusCode, response)
+	}
+	var plugins struct {
+		Plugins []struct {
+			Name string `json:"name"`
+		} `json:"plugins"`
+	}
+	if err = json.Unmarshal([]byte(response), &plugins); err != nil {
+		return false, err
+	}
+	for _, plugin := range plugins.Plugins {
+		if strings.Contains(plugin.Name, enterprisePlugin) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteAuthentication deactivates and deletes the authentication backends created by the operator.
+// It is called when the authentication section is removed from the spec or the custom resource is deleted
+func (connector *GraylogConnector) DeleteAuthentication(cr *loggingService.LoggingService) error {
+	if auth := cr.Spec.Graylog.Authentication; auth != nil {
+		// The backend may be created before its title is recorded in the status
+		if objects := connector.externalObjects(); !slices.Contains(objects.AuthBackends, authBackendTitle(auth)) {
+			objects.AuthBackends = append(objects.AuthBackends, authBackendTitle(auth))
+		}
+	}
+	return connector.deleteAuthBackends("")
+}
+
+// deleteAuthBackends deactivates and deletes the backends created by the operator except the backend with the title
+func (connector *GraylogConnector) deleteAuthBackends(keep string) error {
+	objects := connector.externalObjects()
+	if len(objects.AuthBackends) == 0 || (len(objects.AuthBackends) == 1 && objects.AuthBackends[0] == keep) {
+		return nil
+	}
+	backends, err := connector.GetData(authBackendsUrl, "backends", nil)
+	if err != nil {
+		return err
+	}
+	active, err := connector.GetActiveAuthBackend()
+	if err != nil {
+		return err
+	}
+	for _, title := range slices.Clone(objects.AuthBackends) {
+		if title == keep {
+			continue
+		}
+		if id := GetIdByTitle(backends, title); id != "" {
+			if active != nil && active.Id == id {
+				if err = connector.ActivateAuthBackend(""); err != nil {
+					return err
+				}
+				active = nil
+			}
+			if err = connector.deleteObject(authBackendsUrl+"/"+id, "authentication backend", title); err != nil {
+				return err
+			}
+		}
+		objects.AuthBackends = slices.DeleteFunc(objects.AuthBackends, func(item string) bool { return item == title })
+	}
+	return nil
+}
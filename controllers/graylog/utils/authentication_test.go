@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+var ldapServersTests = []struct {
+	description       string
+	servers           []string
+	transportSecurity string
+	expected          []map[string]interface{}
+	isError           bool
+}{
+	{
+		description:       "Default port is used for the server without the port",
+		servers:           []string{"ldap.example.com"},
+		transportSecurity: "none",
+		expected:          []map[string]interface{}{{"host": "ldap.example.com", "port": 389}},
+	},
+	{
+		description:       "Default TLS port is used for the server without the port",
+		servers:           []string{"ldap.example.com"},
+		transportSecurity: "tls",
+		expected:          []map[string]interface{}{{"host": "ldap.example.com", "port": 636}},
+	},
+	{
+		description:       "Port of the server is used",
+		servers:           []string{"ldap.example.com:1389", "[2001:db8::1]:1636"},
+		transportSecurity: "tls",
+		expected:          []map[string]interface{}{{"host": "ldap.example.com", "port": 1389}, {"host": "2001:db8::1", "port": 1636}},
+	},
+	{
+		description:       "Default port is used for IPv6 address without the port",
+		servers:           []string{"2001:db8::1", "[2001:db8::2]"},
+		transportSecurity: "none",
+		expected:          []map[string]interface{}{{"host": "2001:db8::1", "port": 389}, {"host": "2001:db8::2", "port": 389}},
+	},
+	{
+		description:       "Invalid port is rejected",
+		servers:           []string{"ldap.example.com:ldaps"},
+		transportSecurity: "none",
+		isError:           true,
+	},
+}
+
+func Test_ldapServers(t *testing.T) {
+	for _, tt := range ldapServersTests {
+		t.Run(tt.description, func(t *testing.T) {
+			servers, err := ldapServers(tt.servers, tt.transportSecurity)
+			if (err != nil) != tt.isError {
+				t.Fatalf("expected error: %v, got: %v", tt.isError, err)
+			}
+			if !tt.isError && !reflect.DeepEqual(servers, tt.expected) {
+				t.Errorf("expected servers %v, got %v", tt.expected, servers)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+)
+
+var (
+	managedUsers = []string{"operator", "auditViewer", "graylog_api_th_user", util.GraylogServiceUserName}
+	managedRoles = []string{"operator", "AuditViewer", util.GraylogServiceUserName}
+
+	// openSearchManagedPrefixes contains the paths of the OpenSearch objects which are uploaded
+	// from the configs of the content packs and must be deleted on the cleanup
+	openSearchManagedPrefixes = []string{"_template/", "_index_template/", "_component_template/", "_plugins/_ism/policies/", "_opendistro/_ism/policies/"}
+)
+
+// DeleteGraylogObjects deletes pipelines, processing rules, streams, index sets, roles, users
+// and content packs created by the operator
+func (connector *GraylogConnector) DeleteGraylogObjects(cr *loggingService.LoggingService) error {
+	if err := connector.DeletePipelines(); err != nil {
+		return err
+	}
+	if err := connector.DeleteProcessingRules(); err != nil {
+		return err
+	}
+	if err := connector.DeleteStreams(); err != nil {
+		return err
+	}
+	if err := connector.DeleteIndexSets(); err != nil {
+		return err
+	}
+	if err := connector.DeleteUserAccounts(); err != nil {
+		return err
+	}
+	if err := connector.DeleteContentPacks(cr); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (connector *GraylogConnector) deleteObject(url string, objectType string, title string) error {
+	_, statusCode, err := connector.DELETE(url)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusNoContent && statusCode != http.StatusOK && statusCode != http.StatusNotFound {
+		return fmt.Errorf("can't delete %s %s. Status code: %v", objectType, title, statusCode)
+	}
+	connector.Log.Info(fmt.Sprintf("Graylog %s %s deleted", objectType, title))
+	util.IncGraylogObjectChanges(objectType, "deleted")
+	return nil
+}
+
+// trackObject records the name of the object created from the spec in the list of the status
+func trackObject(objects *[]string, name string) {
+	if !slices.Contains(*objects, name) {
+		*objects = append(*objects, name)
+	}
+}
+
+// removedObjects returns the objects of the list of the status which are not in the names from the spec
+func removedObjects(objects []string, names []string) []string {
+	var removed []string
+	for _, name := range objects {
+		if !slices.Contains(names, name) {
+			removed = append(removed, name)
+		}
+	}
+	return removed
+}
+
+// deleteRemovedObjects deletes the objects of the list of the status which are not in the names from the spec
+// and removes them from the list. The object is kept in the list if its deletion fails, so it is retried
+func deleteRemovedObjects(objects *[]string, names []string, deleteByName func(name string) error) error {
+	for _, name := range removedObjects(*objects, names) {
+		if err := deleteByName(name); err != nil {
+			return err
+		}
+		*objects = slices.DeleteFunc(*objects, func(item string) bool { return item == name })
+	}
+	return nil
+}
+
+func (connector *GraylogConnector) DeletePipelines() error {
+	pipelines, err := connector.GetAllPipelines()
+	if err != nil {
+		return err
+	}
+	if id := GetIdByTitle(pipelines, "Logs routing"); id != "" {
+		return connector.deleteObject(pipelineUrl+"/"+id, "pipeline", "Logs routing")
+	}
+	return nil
+}
+
+func (connector *GraylogConnector) DeleteProcessingRules() error {
+	processingRules, err := connector.GetAllProcessingRules()
+	if err != nil {
+		return err
+	}
+	for title := range connector.GetProcessingRules() {
+		if id := GetIdByTitle(processingRules, title); id != "" {
+			if err = connector.deleteObject(processingRulesUrl+"/"+id, "processing rule", title); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (connector *GraylogConnector) DeleteStreams() error {
+	streams, err := connector.GetAllStreams()
+	if err != nil {
+		return err
+	}
+	for title := range connector.GetStreams() {
+		if id := GetIdByTitle(streams, title); id != "" {
+			if err = connector.deleteObject("streams/"+id, "stream", title); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteIndexSets deletes index sets created by the operator together with their indices.
+// The default index set can't be deleted in Graylog, so it is kept.
+func (connector *GraylogConnector) DeleteIndexSets() error {
+	indexSets, err := connector.GetAllIndexSets()
+	if err != nil {
+		return err
+	}
+	for title := range connector.GetIndexSets() {
+		if title == util.GraylogDefaultIndexSet {
+			continue
+		}
+		if id := GetIdByTitle(indexSets, title); id != "" {
+			if err = connector.deleteObject(indexSetsUrl+"/"+id+"?delete_indices=true", "index set", title); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (connector *GraylogConnector) DeleteUserAccounts() error {
+	for _, user := range managedUsers {
+		if id := connector.GetUserIdByName(user); id != "" {
+			if err := connector.deleteObject("users/id/"+id, "user", user); err != nil {
+				return err
+			}
+		}
+	}
+	for _, role := range managedRoles {
+		if err := connector.deleteObject("roles/"+role, "role", role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteContentPacks deletes the default content pack, the installed content packs from the status
+// and content packs uploaded from the content pack paths
+func (connector *GraylogConnector) DeleteContentPacks(cr *loggingService.LoggingService) error {
+	ids := []string{oobContentPackId}
+	for _, status := range cr.Status.ContentPacks {
+		ids = append(ids, status.Id)
+	}
+
+	files, err := os.ReadDir(filepath.Join(dataDir, contentPacksDir))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, f := range files {
+		var contentPack Entity
+		data, err := os.ReadFile(filepath.Join(dataDir, contentPacksDir, f.Name()))
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(data, &contentPack); err != nil || contentPack.Id == "" {
+			connector.Log.Info("Can not find id of the content pack " + f.Name() + ". Skip it")
+			continue
+		}
+		if !slices.Contains(ids, contentPack.Id) {
+			ids = append(ids, contentPack.Id)
+		}
+	}
+
+	for _, id := range ids {
+		if err = connector.DeleteContentPack(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteContentPack deletes installations of the content pack and all its revisions
+func (connector *GraylogConnector) DeleteContentPack(id string) error {
+	_, statusCode, err := connector.GET(contentpacksUrl + "/" + id)
+	if err != nil {
+		return err
+	}
+	if statusCode == http.StatusNotFound {
+		return nil
+	}
+
+	installations, err := connector.Client.ListContentPackInstallations(connector.context(), id)
+	if err != nil {
+		return err
+	}
+	for _, installation := range installations {
+		if err = connector.deleteObject(fmt.Sprintf(contentPackInstallationUrl, id, installation.Id), "content pack installation", installation.Id); err != nil {
+			return err
+		}
+	}
+	return connector.deleteObject(contentpacksUrl+"/"+id, "content pack", id)
+}
+
+// DeleteOpensearchConfigs deletes templates and policies which are uploaded
+// to OpenSearch from the configs of the content packs
+func (connector *GraylogConnector) DeleteOpensearchConfigs(cr *loggingService.LoggingService) error {
+	configsDirs := []string{openSearchConfigsDir, elasticsearchConfigsDir}
+	for _, configsDir := range configsDirs {
+		files, err := os.ReadDir(filepath.Join(dataDir, configsDir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, f := range files {
+			requests, err := connector.readConfigRequests(f.Name(), cr, configsDir)
+			if err != nil {
+				return err
+			}
+			for _, r := range requests {
+				if !strings.EqualFold(r.Method, http.MethodPut) || !isOpenSearchManagedObject(r.URL) {
+					continue
+				}
+				if _, _, err = connector.SendRequestToOpenSearch(http.MethodDelete, r.URL, nil); err != nil {
+					return err
+				}
+				connector.Log.Info("OpenSearch object " + r.URL + " deleted")
+			}
+		}
+	}
+	return nil
+}
+
+func isOpenSearchManagedObject(url string) bool {
+	for _, prefix := range openSearchManagedPrefixes {
+		if strings.HasPrefix(strings.TrimPrefix(url, "/"), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteArchivesDirectory unregisters the snapshot repository used for Graylog archives
+func (connector *GraylogConnector) DeleteArchivesDirectory(cr *loggingService.LoggingService) error {
+	_, _, err := connector.SendRequestToOpenSearch(http.MethodDelete, snapshotArchivesRequestUrl, nil)
+	return err
+}
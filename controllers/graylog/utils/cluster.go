@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const clusterNodesUrl = "system/cluster/nodes"
+
+// GetClusterNodesCount returns the number of nodes which are registered in the Graylog cluster
+func (connector *GraylogConnector) GetClusterNodesCount() (int, error) {
+	response, statusCode, err := connector.GET(clusterNodesUrl)
+	if err != nil {
+		return 0, err
+	}
+	if statusCode != http.StatusOK {
+		return 0, fmt.Errorf("can't get Graylog cluster nodes. Status code: %v. Response: %s", statusCode, response)
+	}
+	var nodes struct {
+		Nodes []json.RawMessage `json:"nodes"`
+	}
+	if err = json.Unmarshal([]byte(response), &nodes); err != nil {
+		return 0, err
+	}
+	return len(nodes.Nodes), nil
+}
+
+// WaitForClusterNodes waits for all Graylog nodes have joined the cluster after the rolling update
+func (connector *GraylogConnector) WaitForClusterNodes(replicas int, timeout time.Duration) (bool, error) {
+	joined := false
+	err := wait.PollUntilContextTimeout(context.TODO(), util.Interval, timeout, true, func(ctx context.Context) (bool, error) {
+		count, err := connector.GetClusterNodesCount()
+		if err != nil {
+			// Graylog may be temporarily unavailable while its nodes are restarted
+			connector.Log.Info(fmt.Sprintf("Can't get Graylog cluster nodes: %v", err))
+			return false, nil
+		}
+		connector.Log.Info(fmt.Sprintf("%d of %d Graylog nodes have joined the cluster", count, replicas))
+		joined = count >= replicas
+		return joined, nil
+	})
+	if err != nil && !wait.Interrupted(err) {
+		return false, err
+	}
+	return joined, nil
+}
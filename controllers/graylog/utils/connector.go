@@ -1,10 +1,10 @@
 package utils
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
-	"embed"
+	"fmt"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
@@ -12,33 +12,45 @@ import (
 	"time"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/kubernetes"
 )
 
 const (
-	GraylogPort                 = "9000"
-	contentpacksUrl             = "system/content_packs"
-	oobContentPackId            = "0fac53ed-df74-4ba6-88c2-aa16b4b8542d"
-	oobContentPackInstallUrl    = "system/content_packs/" + oobContentPackId + "/1/installations"
-	contentPackInstallationsUrl = "system/content_packs/%s/installations"
-	contentPackInstallationUrl  = "system/content_packs/%s/installations/%s"
-	grokUrl                     = "system/grok"
-	indexSetsUrl                = "system/indices/index_sets"
-	inputsUrl                   = "system/inputs"
-	pipelineUrl                 = "system/pipelines/pipeline"
-	processingRulesUrl          = "system/pipelines/rule"
-	authHeaderUrl               = "system/authentication/http-header-auth-config"
+	GraylogPort                = "9000"
+	contentpacksUrl            = "system/content_packs"
+	oobContentPackId           = "0fac53ed-df74-4ba6-88c2-aa16b4b8542d"
+	oobContentPackInstallUrl   = "system/content_packs/" + oobContentPackId + "/1/installations"
+	contentPackInstallationUrl = "system/content_packs/%s/installations/%s"
+	grokUrl                    = "system/grok"
+	indexSetsUrl               = "system/indices/index_sets"
+	pipelineUrl                = "system/pipelines/pipeline"
+	processingRulesUrl         = "system/pipelines/rule"
+	authHeaderUrl              = "system/authentication/http-header-auth-config"
 )
 
 type GraylogConnector struct {
+	// Client sends requests to the Graylog REST API, RestClient keeps its connection settings
+	Client               graylogClient.Interface
 	RestClient           *util.RestClient
 	OpenSearchRestClient *util.RestClient
 	Log                  logr.Logger
-	Assets               embed.FS
+	Assets               fs.FS
 	EnabledStreams       []Stream
 	TLSEnabled           bool
+	EventRecorder        util.EventRecorder
+	// Capabilities of the running Graylog, they are set by ProbeVersion
+	Capabilities *GraylogCapabilities
+	// UserPasswords are the passwords of the users created by the operator from the Graylog Secret,
+	// the passwords from the templates are used for other users
+	UserPasswords map[string]string
+	// ExternalObjects are the objects created by the operator from the spec, they are recorded in the status
+	// of the LoggingService, so the objects which are removed from the spec are deleted
+	ExternalObjects *loggingService.ExternalObjectsStatus
+	// ctx is the context of the reconciliation, requests to Graylog are cancelled with it
+	ctx context.Context
 }
 
 type Streams struct {
@@ -53,9 +65,28 @@ type Stream struct {
 	RotationPeriod     string
 	MaxSize            int
 	MaxNumberOfIndices int
+	MaxDocsPerIndex    int
+	IndexLifetimeMin   string
+	IndexLifetimeMax   string
+	RetentionStrategy  string
+	SnapshotAfter      string
+	IndexAnalyzer      string
+	FieldTypes         map[string]string
+	// IndexSet is the title of the index set which stores messages of the stream
+	IndexSet    string
+	Description string
+	// Custom is true for the streams which are not in the list of the streams provided by the operator
+	Custom            bool
+	SharedIndexSet    bool
+	IndexPrefix       string
+	MatchingType      string
+	Rules             []loggingService.StreamRule
+	PipelineRule      string
+	RuleTitle         string
+	RemoveFromDefault bool
 }
 
-func CreateConnector(ctx context.Context, cr *loggingService.LoggingService, assets embed.FS, clientSet kubernetes.Interface) (*GraylogConnector, error) {
+func CreateConnector(ctx context.Context, cr *loggingService.LoggingService, assets fs.FS, clientSet kubernetes.Interface) (*GraylogConnector, error) {
 	var user *util.Сreds
 	var tlsConfig *tls.Config
 	var err error
@@ -164,17 +195,45 @@ func CreateConnector(ctx context.Context, cr *loggingService.LoggingService, ass
 		Host:   host,
 	}
 
+	apiClient, err := graylogClient.New(restClient, cr.Spec.Graylog.TLS.HTTP.Enabled)
+	if err != nil {
+		return nil, err
+	}
+
 	var enabledStreams = GetStreams(cr)
 	return &GraylogConnector{
 		Log:                  util.Logger("connector"),
+		Client:               apiClient,
 		RestClient:           restClient,
 		OpenSearchRestClient: openSearchClient,
 		Assets:               assets,
 		EnabledStreams:       enabledStreams,
 		TLSEnabled:           cr.Spec.Graylog.TLS.HTTP.Enabled,
+		ExternalObjects:      cr.Status.ExternalObjects.DeepCopy(),
+		ctx:                  ctx,
 	}, nil
 }
 
+// externalObjects returns the objects created by the operator from the spec, it is empty if the status doesn't have them
+func (connector *GraylogConnector) externalObjects() *loggingService.ExternalObjectsStatus {
+	if connector.ExternalObjects == nil {
+		connector.ExternalObjects = &loggingService.ExternalObjectsStatus{}
+	}
+	return connector.ExternalObjects
+}
+
+// recordCreated emits the Event about the object created in Graylog and counts the change
+func (connector *GraylogConnector) recordCreated(objectType string, title string) {
+	util.IncGraylogObjectChanges(objectType, "created")
+	connector.EventRecorder.Normal(util.ReasonGraylogObjectCreated, fmt.Sprintf("Graylog %s %s created", objectType, title))
+}
+
+// recordUpdated emits the Event about the object updated in Graylog and counts the change
+func (connector *GraylogConnector) recordUpdated(objectType string, title string) {
+	util.IncGraylogObjectChanges(objectType, "updated")
+	connector.EventRecorder.Normal(util.ReasonGraylogObjectUpdated, fmt.Sprintf("Graylog %s %s updated", objectType, title))
+}
+
 func (connector *GraylogConnector) DELETE(url string) (string, int, error) {
 	return connector.Send(url, http.MethodDelete, "")
 }
@@ -191,50 +250,17 @@ func (connector *GraylogConnector) PUT(url string, data string) (string, int, er
 	return connector.Send(url, http.MethodPut, data)
 }
 
+// Send sends the request with the raw body to Graylog and returns the response without checking its status code
 func (connector *GraylogConnector) Send(urlPath string, method string, data string) (string, int, error) {
-	var err error
-	protocol := "http"
-	if connector.TLSEnabled {
-		protocol = "https"
-	}
-
-	var u *url.URL
-	u, err = url.Parse(urlPath)
-	if err != nil {
-		return "", -1, err
-	}
-
-	var base *url.URL
-	base, err = url.Parse(protocol + "://" + connector.RestClient.Host)
-	if err != nil {
-		return "", -1, err
-	}
-
-	uri := base.ResolveReference(u)
-
-	request, err := http.NewRequest(method, uri.String(), strings.NewReader(data))
-	if err != nil {
-		return "", -1, err
-	}
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("X-Requested-By", "Graylog API Browser")
-
-	connector.Log.V(util.Debug).Info("Send " + method + " request to: " + uri.String() + " with body: " + data)
+	return connector.Client.Raw(connector.context(), method, urlPath, data)
+}
 
-	connector.RestClient.SetAuthHeader(request)
-	var response *http.Response
-	response, err = connector.RestClient.Client.Do(request)
-	if err != nil {
-		return "", -1, err
-	}
-	buf := new(bytes.Buffer)
-	_, err = buf.ReadFrom(response.Body)
-	if err != nil {
-		return "", -1, err
+// context returns the context of the reconciliation. The connector created without it uses the background context
+func (connector *GraylogConnector) context() context.Context {
+	if connector.ctx == nil {
+		return context.Background()
 	}
-	connector.Log.V(util.Debug).Info("Status code: " + response.Status)
-	connector.Log.V(util.Debug).Info("Response: " + buf.String())
-	return buf.String(), response.StatusCode, nil
+	return connector.ctx
 }
 
 func ManageRequiredStreams(cr *loggingService.LoggingService) []loggingService.Stream {
@@ -275,6 +301,7 @@ func GetStreams(cr *loggingService.LoggingService) []Stream {
 	} else {
 		streams = ManageRequiredStreams(cr)
 	}
+	streams = append(streams, NamespaceTeamStreams(cr)...)
 	for i := range streams {
 		var needSkip = false
 		for j := i + 1; j < len(streams); j++ {
@@ -283,24 +310,37 @@ func GetStreams(cr *loggingService.LoggingService) []Stream {
 			}
 		}
 		if !needSkip && streams[i].Install {
-			for k := range util.GraylogStreamsRules {
-				if streams[i].Name == k {
-					rotationStrategy := "sizeBased"
-					if streams[i].RotationStrategy != "" &&
-						(strings.EqualFold(streams[i].RotationStrategy, "sizeBased") ||
-							strings.EqualFold(streams[i].RotationStrategy, "timeBased")) {
-						rotationStrategy = streams[i].RotationStrategy
-					}
-					enabledStreams = append(enabledStreams, Stream{
-						Title:              streams[i].Name,
-						RotationStrategy:   rotationStrategy,
-						RotationPeriod:     streams[i].RotationPeriod,
-						MaxSize:            streams[i].MaxSize,
-						MaxNumberOfIndices: streams[i].MaxNumberOfIndices,
-					})
-					break
+			name := streams[i].Name
+			if name == util.GraylogDefaultStream || name == util.GraylogAllMessagesStream {
+				continue
+			}
+			rotationStrategy := "sizeBased"
+			for _, strategy := range []string{"sizeBased", "timeBased", countBasedRotation, timeSizeOptimizingRotation} {
+				if strings.EqualFold(streams[i].RotationStrategy, strategy) {
+					rotationStrategy = strategy
 				}
 			}
+			stream := Stream{
+				Title:              name,
+				RotationStrategy:   rotationStrategy,
+				RotationPeriod:     streams[i].RotationPeriod,
+				MaxSize:            streams[i].MaxSize,
+				MaxNumberOfIndices: streams[i].MaxNumberOfIndices,
+				MaxDocsPerIndex:    streams[i].MaxDocsPerIndex,
+				IndexLifetimeMin:   streams[i].IndexLifetimeMin,
+				IndexLifetimeMax:   streams[i].IndexLifetimeMax,
+				RetentionStrategy:  streams[i].RetentionStrategy,
+				SnapshotAfter:      streams[i].SnapshotAfter,
+				IndexAnalyzer:      streams[i].IndexAnalyzer,
+				FieldTypes:         streams[i].FieldTypes,
+			}
+			if indexSet, ok := util.GraylogStreamsIndexTitles[name]; ok {
+				stream.IndexSet = indexSet
+				stream.Description = util.GraylogStreamsDescriptions[name]
+			} else {
+				setCustomStream(&stream, streams[i])
+			}
+			enabledStreams = append(enabledStreams, stream)
 		}
 	}
 	return enabledStreams
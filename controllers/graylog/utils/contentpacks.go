@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,63 +11,34 @@ import (
 	"strings"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 	"k8s.io/client-go/kubernetes"
 )
 
-const contentPacksDir string = "content-packs"
+const (
+	contentPacksDir                string = "content-packs"
+	contentPackInstallationComment string = "Installed by logging-operator"
+)
 
 var dataDir = os.TempDir()
 
-func (connector *GraylogConnector) UploadContentPack(name string, cr *loggingService.LoggingService) error {
-	fileContent, err := util.ReadFile(filepath.Join(dataDir, contentPacksDir, name))
-	if err != nil {
-		return err
-	}
-	data, err := util.ParseTemplate(fileContent, filepath.Join(dataDir, contentPacksDir, name), cr.ToParams())
-	if err != nil {
-		return err
-	}
-	_, statusCode, err := connector.POST(contentpacksUrl, data)
-	if err != nil {
-		return err
-	}
-	if statusCode != http.StatusCreated {
-		return errors.New("can't upload content-pack " + name)
-	}
-	return nil
-}
-
-// replaceFuncContentPackInstallations is needed to unmarshall json response to Entity struct.
-// GET response of content pack installations has ids of installation under key `_id`
-func replaceFuncContentPackInstallations(data string) string {
-	return strings.ReplaceAll(data, "_id", "id")
-}
-
 // DeleteDefaultContentPack gets content pack installation, deletes its
 // (because content pack can not be deleted if installations exist)
 // and deletes content pack
 func (connector *GraylogConnector) DeleteDefaultContentPack() error {
-	installations, err := connector.GetData(fmt.Sprintf(contentPackInstallationsUrl, oobContentPackId), "installations", replaceFuncContentPackInstallations)
+	installations, err := connector.Client.ListContentPackInstallations(connector.context(), oobContentPackId)
 	if err != nil {
 		return err
 	}
 	for _, installation := range installations {
-		_, statusCode, err := connector.DELETE(fmt.Sprintf(contentPackInstallationUrl, oobContentPackId, installation.Id))
-		if err != nil {
-			return err
-		}
-		if statusCode != http.StatusOK {
-			return errors.New("can't delete content pack installation")
+		if err = connector.Client.DeleteContentPackInstallation(connector.context(), oobContentPackId, installation.Id); err != nil {
+			return fmt.Errorf("can't delete content pack installation: %w", err)
 		}
 	}
 
-	_, statusCode, err := connector.DELETE(contentpacksUrl + "/" + oobContentPackId)
-	if err != nil {
-		return err
-	}
-	if statusCode != http.StatusNoContent {
-		return errors.New("can't delete content pack")
+	if err = connector.Client.DeleteContentPack(connector.context(), oobContentPackId); err != nil {
+		return fmt.Errorf("can't delete content pack: %w", err)
 	}
 	return nil
 }
@@ -98,100 +70,359 @@ func (connector *GraylogConnector) InstallDefaultContentPack(cr *loggingService.
 	if statusCode != http.StatusOK {
 		return errors.New("can't install dashboard")
 	}
+	connector.recordCreated("content pack installation", oobContentPackId)
 	return nil
 }
 
-func (connector *GraylogConnector) UploadContentPacks(cr *loggingService.LoggingService) error {
-	if _, err := os.Stat(filepath.Join(dataDir, contentPacksDir)); err == nil {
-		files, err := os.ReadDir(filepath.Join(dataDir, contentPacksDir))
+// contentPackSource is the URL of the content pack or of the archive with content packs and configs
+type contentPackSource struct {
+	*loggingService.ContentPackPathHTTPConfig
+	// insecure sources are the paths from contentPackPaths, they are downloaded without the verification of certificates
+	insecure bool
+}
+
+// contentPackSources returns the sources from contentPackPaths and contentPacks in the order of the spec
+func contentPackSources(cr *loggingService.LoggingService) []contentPackSource {
+	var sources []contentPackSource
+	if cr.Spec.Graylog.ContentPackPaths != "" {
+		for _, path := range strings.Split(cr.Spec.Graylog.ContentPackPaths, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				sources = append(sources, contentPackSource{ContentPackPathHTTPConfig: &loggingService.ContentPackPathHTTPConfig{URL: path}, insecure: true})
+			}
+		}
+	}
+	for _, item := range cr.Spec.Graylog.ContentPacks {
+		if item != nil && (item.URL != "" || item.IsSet()) {
+			sources = append(sources, contentPackSource{ContentPackPathHTTPConfig: item})
+		}
+	}
+	return sources
+}
+
+// name returns the URL or the description of the content source which is kept in the status
+func (source contentPackSource) name() string {
+	switch {
+	case source.URL != "":
+		return source.URL
+	case source.ConfigMap != nil:
+		return strings.TrimSuffix("configMap/"+source.ConfigMap.Name+"/"+source.ConfigMap.Key, "/")
+	case source.Secret != nil:
+		return strings.TrimSuffix("secret/"+source.Secret.Name+"/"+source.Secret.Key, "/")
+	case source.OCI != "":
+		return "oci://" + strings.TrimPrefix(source.OCI, "oci://")
+	}
+	return "inline"
+}
+
+// ManageContentPacks downloads the content packs from contentPackPaths and contentPacks and installs their revisions
+// which are not installed yet. The previous installation of the content pack is uninstalled after the installation
+// of the new revision, the content packs which are removed from the spec are uninstalled.
+// It returns the installed content packs, on the error they contain the progress of the run
+func (connector *GraylogConnector) ManageContentPacks(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) ([]loggingService.ContentPackStatus, error) {
+	installed := cr.Status.ContentPacks
+	var statuses []loggingService.ContentPackStatus
+
+	for _, source := range contentPackSources(cr) {
+		files, digest, err := connector.downloadContentPacks(ctx, cr, source, clientSet)
 		if err != nil {
-			return err
+			return keepInstalledContentPacks(statuses, installed), err
 		}
-		for _, f := range files {
-			if err = connector.UploadContentPack(f.Name(), cr); err != nil {
-				return err
+		for _, fileName := range files {
+			status, err := connector.installContentPack(cr, source.name(), digest, fileName, installed)
+			if err != nil {
+				return keepInstalledContentPacks(statuses, installed), err
 			}
+			statuses = append(statuses, status)
 		}
-	} else if os.IsNotExist(err) {
-		connector.Log.V(util.Debug).Info("Directory " + filepath.Join(dataDir, contentPacksDir) + " not found")
-	} else {
-		return err
 	}
-	return nil
+
+	for _, status := range installed {
+		if findContentPack(statuses, status.Id) != nil {
+			continue
+		}
+		if err := connector.DeleteContentPack(status.Id); err != nil {
+			return keepInstalledContentPacks(statuses, installed), err
+		}
+		connector.Log.Info(fmt.Sprintf("Content pack %s from %s is removed from the spec and uninstalled", status.Id, status.Source))
+	}
+	return statuses, nil
+}
+
+// downloadContentPacks downloads the content pack or the archive into the data directory and returns
+// the files of the content packs. The archive is unzipped into the data directory,
+// so the configs from it are uploaded to OpenSearch by ManageOpensearchConfigs.
+// It also returns the digest of the OCI artifact, it is empty for other sources
+func (connector *GraylogConnector) downloadContentPacks(ctx context.Context, cr *loggingService.LoggingService, source contentPackSource, clientSet kubernetes.Interface) ([]string, string, error) {
+	if source.URL == "" {
+		return connector.fetchContentPacks(ctx, cr, source, clientSet)
+	}
+	files, err := connector.downloadURL(ctx, cr, source, clientSet)
+	return files, "", err
 }
 
-func (connector *GraylogConnector) ManageContentPacks(cr *loggingService.LoggingService) error {
-	contentPacks := strings.Split(cr.Spec.Graylog.ContentPackPaths, ",")
+// downloadURL downloads the content pack or the archive by the URL of the source
+func (connector *GraylogConnector) downloadURL(ctx context.Context, cr *loggingService.LoggingService, source contentPackSource, clientSet kubernetes.Interface) ([]string, error) {
+	fileName := filepath.Join(dataDir, filepath.Base(source.URL))
+	isArchive := strings.HasSuffix(fileName, ".zip")
+	if !isArchive {
+		if !strings.HasSuffix(fileName, ".json") {
+			connector.Log.V(util.Error).Info("Incorrect content pack: " + fileName + " (it should be zip or json)")
+			connector.EventRecorder.Warning(util.ReasonValidationFailed, "Incorrect content pack: "+filepath.Base(fileName)+" (it should be zip or json)")
+			return nil, nil
+		}
+		fileName = filepath.Join(dataDir, contentPacksDir, filepath.Base(source.URL))
+		if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
 
-	for _, item := range contentPacks {
-		fullName := filepath.Join(dataDir, filepath.Base(item))
+	var err error
+	if source.insecure {
+		err = util.DownloadFile(source.URL, fileName)
+	} else {
+		err = util.DownloadFileTLS(ctx, source.ContentPackPathHTTPConfig, fileName, clientSet, cr.GetNamespace())
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		if err := util.DownloadFile(item, fullName); err != nil {
-			return err
+	if source.Checksum != "" {
+		if err = util.VerifyChecksum(fileName, source.Checksum); err != nil {
+			_ = os.Remove(fileName)
+			connector.EventRecorder.Warning(util.ReasonValidationFailed, "Content pack "+source.URL+" is not installed: "+err.Error())
+			return nil, err
 		}
+	}
 
-		if strings.HasSuffix(fullName, ".zip") {
-			connector.Log.V(util.Debug).Info("Unzip file " + fullName + " into " + filepath.Join(dataDir))
-			files, err := util.Unzip(fullName, filepath.Join(dataDir))
-			if err != nil {
-				return err
-			}
+	if !isArchive {
+		return []string{fileName}, nil
+	}
+	return connector.unzipContentPacks(fileName)
+}
 
-			connector.Log.V(util.Debug).Info("Unzipped:" + strings.Join(files, ";"))
+// fetchContentPacks writes the files from the ConfigMap, the Secret, the inline files or the OCI artifact
+// into the data directory and returns the files of the content packs. The archives are unzipped
+// as the archives downloaded by the URL
+func (connector *GraylogConnector) fetchContentPacks(ctx context.Context, cr *loggingService.LoggingService, source contentPackSource, clientSet kubernetes.Interface) ([]string, string, error) {
+	contentFiles, err := util.FetchContent(ctx, &source.ContentSource, source.HTTPConfig, clientSet, cr.GetNamespace())
+	if err != nil {
+		return nil, "", err
+	}
 
-			err = os.Remove(fullName)
+	var files []string
+	var digest string
+	for _, contentFile := range contentFiles {
+		digest = contentFile.Digest
+		switch {
+		case strings.HasSuffix(contentFile.Name, ".zip"):
+			fileName := filepath.Join(dataDir, contentFile.Name)
+			if err = os.WriteFile(fileName, contentFile.Data, 0o600); err != nil {
+				return nil, "", err
+			}
+			unzipped, err := connector.unzipContentPacks(fileName)
 			if err != nil {
-				return err
+				return nil, "", err
+			}
+			files = append(files, unzipped...)
+		case strings.HasSuffix(contentFile.Name, ".json"):
+			fileName := filepath.Join(dataDir, contentPacksDir, contentFile.Name)
+			if err = os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+				return nil, "", err
+			}
+			if err = os.WriteFile(fileName, contentFile.Data, 0o600); err != nil {
+				return nil, "", err
 			}
-		} else if !strings.HasSuffix(fullName, ".json") {
-			connector.Log.V(util.Error).Info("Incorrect content pack: " + fullName + " (it should be zip or json)")
+			files = append(files, fileName)
+		default:
+			connector.Log.V(util.Error).Info("Incorrect content pack: " + contentFile.Name + " from " + source.name() + " (it should be zip or json)")
+			connector.EventRecorder.Warning(util.ReasonValidationFailed, "Incorrect content pack: "+contentFile.Name+" from "+source.name()+" (it should be zip or json)")
 		}
 	}
+	return files, digest, nil
+}
 
-	if err := connector.UploadContentPacks(cr); err != nil {
-		return err
+// unzipContentPacks unzips the archive into the data directory, removes the archive
+// and returns the files of the content packs from it
+func (connector *GraylogConnector) unzipContentPacks(fileName string) ([]string, error) {
+	connector.Log.V(util.Debug).Info("Unzip file " + fileName + " into " + dataDir)
+	unzipped, err := util.Unzip(fileName, dataDir)
+	if err != nil {
+		return nil, err
+	}
+	connector.Log.V(util.Debug).Info("Unzipped:" + strings.Join(unzipped, ";"))
+	if err = os.Remove(fileName); err != nil {
+		return nil, err
 	}
 
-	return nil
+	var files []string
+	for _, file := range unzipped {
+		if filepath.Dir(file) == filepath.Join(dataDir, contentPacksDir) && strings.HasSuffix(file, ".json") {
+			files = append(files, file)
+		}
+	}
+	return files, nil
 }
 
-func (connector *GraylogConnector) ManageContentPackTLS(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
-
-	for _, item := range cr.Spec.Graylog.ContentPacks {
+// installContentPack uploads the revision of the content pack from the file if Graylog doesn't have it
+// and installs the revision if it isn't installed yet. The previous installation of the content pack
+// is uninstalled after the installation of the new one
+func (connector *GraylogConnector) installContentPack(cr *loggingService.LoggingService, source string, digest string, fileName string, installed []loggingService.ContentPackStatus) (loggingService.ContentPackStatus, error) {
+	fileContent, err := util.ReadFile(fileName)
+	if err != nil {
+		return loggingService.ContentPackStatus{}, err
+	}
+	data, err := util.ParseTemplate(fileContent, fileName, cr.ToParams())
+	if err != nil {
+		return loggingService.ContentPackStatus{}, err
+	}
+	var contentPack graylogClient.ContentPack
+	if err = json.Unmarshal([]byte(data), &contentPack); err != nil || contentPack.Id == "" {
+		return loggingService.ContentPackStatus{}, fmt.Errorf("can't find id of the content pack %s from %s", filepath.Base(fileName), source)
+	}
 
-		fullName := filepath.Join(dataDir, filepath.Base(item.URL))
+	status := loggingService.ContentPackStatus{Source: source, Id: contentPack.Id, Revision: contentPack.Revision, Digest: digest}
+	previous := findContentPack(installed, contentPack.Id)
+	installations, err := connector.contentPackInstallations(contentPack.Id)
+	if err != nil {
+		return status, err
+	}
+	// The tag of the OCI artifact can be pushed again with the changed content pack of the same revision,
+	// so the revision is uploaded and installed again. The status without the digest is kept from the previous
+	// version of the operator and is not compared
+	if previous != nil && previous.Revision == contentPack.Revision && previous.Digest != "" && digest != "" && previous.Digest != digest {
+		connector.Log.Info(fmt.Sprintf("OCI artifact %s is changed from %s to %s, revision %d of content pack %s is reinstalled",
+			source, previous.Digest, digest, contentPack.Revision, contentPack.Name))
+		if err = connector.deleteContentPackRevision(contentPack.Id, contentPack.Revision, installations); err != nil {
+			return status, err
+		}
+		previous, installations = nil, nil
+	}
+	if previous != nil && previous.Revision == contentPack.Revision && findContentPackInstallation(installations, previous.InstallationId) != nil {
+		status.InstallationId = previous.InstallationId
+		return status, nil
+	}
+	// The status doesn't have the installation if it is lost or the content pack was installed by the previous version
+	// of the operator, so the existing installation of the revision is adopted instead of the second installation
+	// which duplicates the entities of the content pack
+	for _, installation := range installations {
+		if installation.ContentPackRev == contentPack.Revision {
+			status.InstallationId = installation.Id
+			connector.Log.Info(fmt.Sprintf("Existing installation %s of revision %d of content pack %s is adopted", installation.Id, contentPack.Revision, contentPack.Name))
+			if previous != nil && previous.InstallationId != status.InstallationId {
+				connector.uninstallContentPackRevision(previous, contentPack.Revision)
+			}
+			return status, nil
+		}
+	}
 
-		if err := util.DownloadFileTLS(ctx, item, fullName, clientSet, cr.GetNamespace()); err != nil {
-			return err
+	revisions, err := connector.Client.GetContentPackRevisions(connector.context(), contentPack.Id)
+	if err != nil && !graylogClient.IsNotFound(err) {
+		return status, err
+	}
+	if _, ok := revisions[contentPack.Revision]; !ok {
+		_, statusCode, err := connector.POST(contentpacksUrl, data)
+		if err != nil {
+			return status, err
 		}
+		if statusCode != http.StatusCreated {
+			return status, errors.New("can't upload content-pack " + filepath.Base(fileName))
+		}
+		connector.recordCreated("content pack", contentPack.Name)
+	}
 
-		if strings.HasSuffix(fullName, ".zip") {
-			connector.Log.V(util.Debug).Info("Unzip file " + fullName + " into " + filepath.Join(dataDir))
-			files, err := util.Unzip(fullName, filepath.Join(dataDir))
-			if err != nil {
-				return err
-			}
+	installation, err := connector.Client.InstallContentPack(connector.context(), contentPack.Id, contentPack.Revision, graylogClient.ContentPackInstallRequest{
+		Parameters: map[string]interface{}{},
+		Comment:    contentPackInstallationComment,
+	})
+	if err != nil {
+		return status, fmt.Errorf("can't install revision %d of content pack %s: %w", contentPack.Revision, contentPack.Name, err)
+	}
+	status.InstallationId = installation.Id
+	connector.recordCreated("content pack installation", fmt.Sprintf("%s (revision %d)", contentPack.Name, contentPack.Revision))
 
-			connector.Log.V(util.Debug).Info("Unzipped:" + strings.Join(files, ";"))
+	if previous != nil && previous.InstallationId != status.InstallationId {
+		connector.uninstallContentPackRevision(previous, contentPack.Revision)
+	}
+	return status, nil
+}
 
-			err = os.Remove(fullName)
-			if err != nil {
-				return err
-			}
-		} else if !strings.HasSuffix(fullName, ".json") {
-			connector.Log.V(util.Error).Info("Incorrect content pack: " + fullName + " (it should be zip o json)")
+// uninstallContentPackRevision deletes the previous installation of the content pack and its revision
+// if it differs from the installed one. Errors are only logged, because the new revision is already installed
+func (connector *GraylogConnector) uninstallContentPackRevision(previous *loggingService.ContentPackStatus, installedRevision int) {
+	if previous.InstallationId != "" {
+		err := connector.Client.DeleteContentPackInstallation(connector.context(), previous.Id, previous.InstallationId)
+		if err != nil && !graylogClient.IsNotFound(err) {
+			connector.Log.Error(err, fmt.Sprintf("Can not uninstall revision %d of content pack %s", previous.Revision, previous.Id))
+			return
 		}
 	}
+	if previous.Revision != installedRevision {
+		err := connector.Client.DeleteContentPackRevision(connector.context(), previous.Id, previous.Revision)
+		if err != nil && !graylogClient.IsNotFound(err) {
+			connector.Log.Error(err, fmt.Sprintf("Can not delete revision %d of content pack %s", previous.Revision, previous.Id))
+			return
+		}
+	}
+	connector.Log.Info(fmt.Sprintf("Revision %d of content pack %s is uninstalled", previous.Revision, previous.Id))
+}
 
-	if err := connector.UploadContentPacks(cr); err != nil {
-		return err
+// deleteContentPackRevision deletes the installations of the revision of the content pack and the revision itself
+func (connector *GraylogConnector) deleteContentPackRevision(id string, revision int, installations []graylogClient.ContentPackInstallation) error {
+	for _, installation := range installations {
+		if installation.ContentPackRev != revision {
+			continue
+		}
+		err := connector.Client.DeleteContentPackInstallation(connector.context(), id, installation.Id)
+		if err != nil && !graylogClient.IsNotFound(err) {
+			return fmt.Errorf("can't uninstall revision %d of content pack %s: %w", revision, id, err)
+		}
+	}
+	err := connector.Client.DeleteContentPackRevision(connector.context(), id, revision)
+	if err != nil && !graylogClient.IsNotFound(err) {
+		return fmt.Errorf("can't delete revision %d of content pack %s: %w", revision, id, err)
 	}
+	return nil
+}
+
+// contentPackInstallations returns the installations of the content pack, it is empty if Graylog doesn't have it
+func (connector *GraylogConnector) contentPackInstallations(id string) ([]graylogClient.ContentPackInstallation, error) {
+	installations, err := connector.Client.ListContentPackInstallations(connector.context(), id)
+	if err != nil && !graylogClient.IsNotFound(err) {
+		return nil, err
+	}
+	return installations, nil
+}
+
+func findContentPackInstallation(installations []graylogClient.ContentPackInstallation, id string) *graylogClient.ContentPackInstallation {
+	for i := range installations {
+		if installations[i].Id == id {
+			return &installations[i]
+		}
+	}
+	return nil
+}
 
+func findContentPack(statuses []loggingService.ContentPackStatus, id string) *loggingService.ContentPackStatus {
+	for i := range statuses {
+		if statuses[i].Id == id {
+			return &statuses[i]
+		}
+	}
 	return nil
 }
 
+// keepInstalledContentPacks adds the previously installed content packs which are not processed in this run
+func keepInstalledContentPacks(statuses []loggingService.ContentPackStatus, installed []loggingService.ContentPackStatus) []loggingService.ContentPackStatus {
+	for _, status := range installed {
+		if findContentPack(statuses, status.Id) == nil {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
 func (connector *GraylogConnector) ManageDashboards(cr *loggingService.LoggingService) error {
-	contentPacks, err := connector.GetData(contentpacksUrl, "content_packs", nil)
+	contentPacks, err := connector.Client.ListContentPacks(connector.context())
 	if err != nil {
 		return err
 	}
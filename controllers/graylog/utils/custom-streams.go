@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+)
+
+var (
+	ruleTitleRegexp   = regexp.MustCompile(`^\s*rule\s+"([^"]+)"`)
+	indexPrefixRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+	// streamRuleTypes contains ids of the stream rule types in Graylog
+	streamRuleTypes = map[string]int{
+		"exact":    1,
+		"greater":  2,
+		"smaller":  3,
+		"regex":    4,
+		"presence": 5,
+		"contains": 6,
+	}
+)
+
+const (
+	defaultIndexShards        = 4
+	defaultIndexReplicas      = 1
+	defaultRotationPeriod     = "P1M"
+	defaultMaxIndexSize       = 1073741824
+	defaultMaxNumberOfIndices = 4
+)
+
+// customIndexSetData returns the index set of the custom stream. It is marshalled from the settings
+// of the stream, so the names of the stream can contain any characters
+func customIndexSetData(cr *loggingService.LoggingService, stream *Stream) (string, error) {
+	indexSet := graylogClient.IndexSet{
+		Title:                  stream.IndexSet,
+		Description:            fmt.Sprintf("The index set of the %s stream", stream.Title),
+		IndexPrefix:            stream.IndexPrefix,
+		Shards:                 defaultIndexShards,
+		Replicas:               defaultIndexReplicas,
+		RetentionStrategyClass: strategiesPackage + "retention.strategies.DeletionRetentionStrategy",
+		RetentionStrategy: map[string]interface{}{
+			"type":                  strategiesPackage + "retention.strategies.DeletionRetentionStrategyConfig",
+			"max_number_of_indices": defaultMaxNumberOfIndices,
+		},
+		CreationDate:                    util.GetTimeNow(),
+		IndexAnalyzer:                   "standard",
+		IndexOptimizationMaxNumSegments: 1,
+		Writable:                        true,
+		FieldTypeRefreshInterval:        5000,
+	}
+	if cr.Spec.Graylog.IndexShards != 0 {
+		indexSet.Shards = cr.Spec.Graylog.IndexShards
+	}
+	if cr.Spec.Graylog.IndexReplicas != 0 {
+		indexSet.Replicas = cr.Spec.Graylog.IndexReplicas
+	}
+	if stream.RotationStrategy == "timeBased" {
+		period := stream.RotationPeriod
+		if period == "" {
+			period = defaultRotationPeriod
+		}
+		indexSet.RotationStrategyClass = strategiesPackage + "rotation.strategies.TimeBasedRotationStrategy"
+		indexSet.RotationStrategy = map[string]interface{}{
+			"type":                strategiesPackage + "rotation.strategies.TimeBasedRotationStrategyConfig",
+			"rotation_period":     period,
+			"max_rotation_period": nil,
+		}
+	} else {
+		maxSize := stream.MaxSize
+		if maxSize == 0 {
+			maxSize = defaultMaxIndexSize
+		}
+		indexSet.RotationStrategyClass = strategiesPackage + "rotation.strategies.SizeBasedRotationStrategy"
+		indexSet.RotationStrategy = map[string]interface{}{
+			"type":     strategiesPackage + "rotation.strategies.SizeBasedRotationStrategyConfig",
+			"max_size": maxSize,
+		}
+	}
+	if stream.MaxNumberOfIndices != 0 {
+		indexSet.RetentionStrategy["max_number_of_indices"] = stream.MaxNumberOfIndices
+	}
+	data, err := json.Marshal(indexSet)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// setCustomStream fills the settings of the stream which is not provided by the operator
+func setCustomStream(stream *Stream, spec loggingService.Stream) {
+	stream.Custom = true
+	stream.Description = spec.Description
+	stream.MatchingType = "AND"
+	if spec.MatchingType != "" {
+		stream.MatchingType = spec.MatchingType
+	}
+	stream.Rules = spec.Rules
+	stream.PipelineRule = spec.PipelineRule
+	if title := ruleTitleRegexp.FindStringSubmatch(spec.PipelineRule); title != nil {
+		stream.RuleTitle = title[1]
+	}
+	stream.RemoveFromDefault = spec.RemoveFromDefault == nil || *spec.RemoveFromDefault
+	stream.IndexSet = spec.IndexSet
+	stream.SharedIndexSet = spec.IndexSet != ""
+	if !stream.SharedIndexSet {
+		stream.IndexSet = spec.Name + " index set"
+	}
+	stream.IndexPrefix = spec.IndexPrefix
+	if stream.IndexPrefix == "" {
+		stream.IndexPrefix = "gray_" + strings.Trim(indexPrefixRegexp.ReplaceAllString(strings.ToLower(spec.Name), "_"), "_")
+	}
+}
+
+// validateCustomStreams checks the settings of the custom streams before their creation
+func (connector *GraylogConnector) validateCustomStreams() error {
+	for _, stream := range connector.EnabledStreams {
+		if !stream.Custom {
+			continue
+		}
+		if stream.PipelineRule != "" && stream.RuleTitle == "" {
+			return fmt.Errorf("can't find title of the pipeline rule of the stream %s", stream.Title)
+		}
+		for _, rule := range stream.Rules {
+			if _, ok := streamRuleTypes[rule.Type]; !ok {
+				return fmt.Errorf("unknown type %s of the rule for the field %s of the stream %s", rule.Type, rule.Field, stream.Title)
+			}
+		}
+	}
+	return nil
+}
+
+// customStream returns the enabled custom stream by its title
+func (connector *GraylogConnector) customStream(title string) *Stream {
+	for i := range connector.EnabledStreams {
+		if connector.EnabledStreams[i].Custom && connector.EnabledStreams[i].Title == title {
+			return &connector.EnabledStreams[i]
+		}
+	}
+	return nil
+}
+
+func streamRules(stream Stream) []graylogClient.StreamRule {
+	var rules []graylogClient.StreamRule
+	for _, rule := range stream.Rules {
+		rules = append(rules, graylogClient.StreamRule{
+			Field:       rule.Field,
+			Type:        streamRuleTypes[rule.Type],
+			Value:       rule.Value,
+			Inverted:    rule.Inverted,
+			Description: rule.Description,
+		})
+	}
+	return rules
+}
+
+// ReplaceStreamRules replaces the rules of the existing custom stream,
+// because they are not changed by the update of the stream
+func (connector *GraylogConnector) ReplaceStreamRules(streamId string, stream Stream) error {
+	rules, err := connector.Client.ListStreamRules(connector.context(), streamId)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err = connector.Client.DeleteStreamRule(connector.context(), streamId, rule.Id); err != nil && !graylogClient.IsNotFound(err) {
+			return err
+		}
+		connector.Log.Info(fmt.Sprintf("Graylog stream rule %s deleted", rule.Id))
+	}
+	for _, rule := range streamRules(stream) {
+		if err = connector.Client.CreateStreamRule(connector.context(), streamId, rule); err != nil {
+			return fmt.Errorf("can't create rule for the field %s of the stream %s: %w", rule.Field, stream.Title, err)
+		}
+	}
+	return nil
+}
+
+// pipelineData returns the "Logs routing" pipeline with the pipeline rules of the custom streams
+func (connector *GraylogConnector) pipelineData(cr *loggingService.LoggingService) (string, error) {
+	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, util.GraylogPipeline), util.GraylogPipeline, cr.ToParams())
+	if err != nil {
+		return "", err
+	}
+	var rules strings.Builder
+	for _, stream := range connector.EnabledStreams {
+		if stream.Custom && stream.RuleTitle != "" {
+			rules.WriteString("rule \"" + stream.RuleTitle + "\"\n")
+		}
+	}
+	if rules.Len() == 0 {
+		return data, nil
+	}
+
+	var pipeline map[string]interface{}
+	if err = json.Unmarshal([]byte(data), &pipeline); err != nil {
+		return "", err
+	}
+	source, _ := pipeline["source"].(string)
+	end := strings.LastIndex(source, "end")
+	if end < 0 {
+		return "", errors.New("can't find the end of the stage in the pipeline Logs routing")
+	}
+	pipeline["source"] = source[:end] + rules.String() + source[end:]
+	result, err := json.Marshal(pipeline)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
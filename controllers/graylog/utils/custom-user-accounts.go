@@ -0,0 +1,296 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	namespaceStreamTitleFormat = "%s namespace logs"
+	namespaceRoleNameFormat    = "%s namespace team"
+	readerRole                 = "Reader"
+	defaultSessionTimeoutMs    = 3600000
+)
+
+// defaultUserPermissions are the permissions of the users created by the operator to search messages
+var defaultUserPermissions = []string{
+	"clusterconfigentry:read",
+	"indexercluster:read",
+	"messagecount:read",
+	"journal:read",
+	"messages:analyze",
+	"inputs:read",
+	"metrics:read",
+	"savedsearches:edit",
+	"fieldnames:read",
+	"buffers:read",
+	"system:read",
+	"savedsearches:create",
+	"jvmstats:read",
+	"decorators:read",
+	"throughput:read",
+	"savedsearches:read",
+	"messages:read",
+}
+
+// NamespaceTeamStreams returns the streams with messages of the namespaces of the teams
+func NamespaceTeamStreams(cr *loggingService.LoggingService) []loggingService.Stream {
+	if cr.Spec.Graylog == nil || cr.Spec.Graylog.NamespaceTeams == nil {
+		return nil
+	}
+	indexSet := cr.Spec.Graylog.NamespaceTeams.IndexSet
+	if indexSet == "" {
+		indexSet = util.GraylogDefaultIndexSet
+	}
+	removeFromDefault := false
+	var streams []loggingService.Stream
+	for _, namespace := range cr.Spec.Graylog.NamespaceTeams.Namespaces {
+		streams = append(streams, loggingService.Stream{
+			Name:        fmt.Sprintf(namespaceStreamTitleFormat, namespace),
+			Install:     true,
+			Description: "Log messages from the " + namespace + " namespace",
+			Rules: []loggingService.StreamRule{
+				{Field: "namespace", Type: "exact", Value: namespace},
+			},
+			IndexSet:          indexSet,
+			RemoveFromDefault: &removeFromDefault,
+		})
+	}
+	return streams
+}
+
+// customRoles returns the roles from the CR and the roles of the namespace teams
+func customRoles(cr *loggingService.LoggingService) []loggingService.GraylogRole {
+	roles := append([]loggingService.GraylogRole{}, cr.Spec.Graylog.Roles...)
+	if cr.Spec.Graylog.NamespaceTeams == nil {
+		return roles
+	}
+	for _, namespace := range cr.Spec.Graylog.NamespaceTeams.Namespaces {
+		roles = append(roles, loggingService.GraylogRole{
+			Name:        fmt.Sprintf(namespaceRoleNameFormat, namespace),
+			Description: "Read access to log messages from the " + namespace + " namespace",
+			Streams:     []string{fmt.Sprintf(namespaceStreamTitleFormat, namespace)},
+		})
+	}
+	return roles
+}
+
+// CreateCustomRoleData resolves titles of the streams and dashboards of the role to their ids
+func CreateCustomRoleData(role loggingService.GraylogRole, streams []Entity, dashboards []Entity) (string, error) {
+	permissions := append([]string{}, role.Permissions...)
+	for _, title := range role.Streams {
+		id := GetIdByTitle(streams, title)
+		if id == "" {
+			return "", fmt.Errorf("stream %s of the role %s not found", title, role.Name)
+		}
+		permissions = append(permissions, "streams:read:"+id)
+	}
+	for _, title := range role.Dashboards {
+		id := GetIdByTitle(dashboards, title)
+		if id == "" {
+			return "", fmt.Errorf("dashboard %s of the role %s not found", title, role.Name)
+		}
+		permissions = append(permissions, "dashboards:read:"+id)
+	}
+	data, err := json.Marshal(graylogClient.Role{Name: role.Name, Description: role.Description, Permissions: permissions, ReadOnly: true})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// CreateCustomUserData builds the user with the password from the Secret
+func CreateCustomUserData(ctx context.Context, user loggingService.GraylogUser, namespace string, clientSet kubernetes.Interface) (string, error) {
+	secret, err := clientSet.CoreV1().Secrets(namespace).Get(ctx, user.PasswordSecret.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	password, found := secret.Data[user.PasswordSecret.Key]
+	if !found {
+		return "", fmt.Errorf("can't find key %s in Secret %s for user %s", user.PasswordSecret.Key, user.PasswordSecret.Name, user.Username)
+	}
+
+	pattern := graylogClient.User{
+		Username:         user.Username,
+		Password:         string(password),
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Permissions:      append([]string{"users:edit:" + user.Username, "users:passwordchange:" + user.Username}, defaultUserPermissions...),
+		Roles:            append([]string{}, user.Roles...),
+		SessionTimeoutMs: user.SessionTimeoutMs,
+	}
+	if pattern.Email == "" {
+		pattern.Email = user.Username + "@localhost"
+	}
+	if pattern.FirstName == "" {
+		pattern.FirstName = user.Username
+	}
+	if pattern.LastName == "" {
+		pattern.LastName = user.Username
+	}
+	if !slices.Contains(pattern.Roles, readerRole) {
+		pattern.Roles = append(pattern.Roles, readerRole)
+	}
+	if pattern.SessionTimeoutMs == 0 {
+		pattern.SessionTimeoutMs = defaultSessionTimeoutMs
+	}
+	data, err := json.Marshal(pattern)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ManageCustomUserAccounts creates roles and users from the CR and the roles of the namespace teams.
+// The users are updated if they differ from the CR and their passwords are changed if Graylog doesn't accept
+// the passwords from the Secrets. The roles and users created before and removed from the CR are deleted
+func (connector *GraylogConnector) ManageCustomUserAccounts(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface, capabilities *GraylogCapabilities) error {
+	objects := connector.externalObjects()
+	roles := customRoles(cr)
+	var roleNames []string
+	if len(roles) > 0 {
+		streams, err := connector.GetAllStreams()
+		if err != nil {
+			return err
+		}
+		dashboards, err := connector.GetAllDashboards(capabilities)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			data, err := CreateCustomRoleData(role, streams, dashboards)
+			if err != nil {
+				return err
+			}
+			roleNames = append(roleNames, role.Name)
+			trackObject(&objects.Roles, role.Name)
+			if err = connector.CreateOrUpdateRole(role.Name, data, cr); err != nil {
+				return err
+			}
+		}
+	}
+
+	var usernames []string
+	if len(cr.Spec.Graylog.Users) > 0 {
+		users, err := connector.GetAllUsers()
+		if err != nil {
+			return err
+		}
+		for _, user := range cr.Spec.Graylog.Users {
+			data, err := CreateCustomUserData(ctx, user, cr.GetNamespace(), clientSet)
+			if err != nil {
+				return err
+			}
+			usernames = append(usernames, user.Username)
+			trackObject(&objects.Users, user.Username)
+			if err = connector.manageCustomUser(users, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return connector.deleteRemovedCustomUserAccounts(usernames, roleNames)
+}
+
+// manageCustomUser creates the user or updates the existing one if it differs from the spec,
+// and changes the password if Graylog doesn't accept it
+func (connector *GraylogConnector) manageCustomUser(users []graylogClient.User, data string) error {
+	var user graylogClient.User
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
+		return err
+	}
+	index := slices.IndexFunc(users, func(existing graylogClient.User) bool { return existing.Username == user.Username })
+	if index < 0 {
+		return connector.CreateUser(data)
+	}
+
+	// The password of the existing user is changed by the separate request
+	password := user.Password
+	user.Password = ""
+	if existing := users[index]; customUserChanged(existing, user) {
+		if err := connector.Client.UpdateUser(connector.context(), existing.Id, user); err != nil {
+			return fmt.Errorf("can't update user %s: %w", user.Username, err)
+		}
+		connector.recordUpdated("user", user.Username)
+	}
+
+	statusCode, err := connector.login(user.Username, password)
+	if err != nil {
+		return err
+	}
+	if statusCode == http.StatusUnauthorized {
+		// The password in the Secret is changed
+		if _, err = connector.ChangeUserPassword(user.Username, password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// customUserChanged checks if the existing user differs from the user from the spec. The permissions
+// of the existing user may contain the permissions added by Graylog, so only the missing ones are the changes
+func customUserChanged(existing graylogClient.User, user graylogClient.User) bool {
+	if existing.Email != user.Email || existing.FirstName != user.FirstName || existing.LastName != user.LastName ||
+		existing.SessionTimeoutMs != user.SessionTimeoutMs {
+		return true
+	}
+	existingRoles, roles := slices.Clone(existing.Roles), slices.Clone(user.Roles)
+	slices.Sort(existingRoles)
+	slices.Sort(roles)
+	if !slices.Equal(slices.Compact(existingRoles), slices.Compact(roles)) {
+		return true
+	}
+	for _, permission := range user.Permissions {
+		if !slices.Contains(existing.Permissions, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteCustomUserAccounts deletes users and roles created from the CR
+func (connector *GraylogConnector) DeleteCustomUserAccounts(cr *loggingService.LoggingService) error {
+	// The objects may be created before their names are recorded in the status
+	objects := connector.externalObjects()
+	for _, user := range cr.Spec.Graylog.Users {
+		trackObject(&objects.Users, user.Username)
+	}
+	for _, role := range customRoles(cr) {
+		trackObject(&objects.Roles, role.Name)
+	}
+	return connector.deleteRemovedCustomUserAccounts(nil, nil)
+}
+
+// deleteRemovedCustomUserAccounts deletes the users and the roles created by the operator except the ones
+// with the names from the CR. The users are deleted first, because they refer to the roles
+func (connector *GraylogConnector) deleteRemovedCustomUserAccounts(usernames []string, roleNames []string) error {
+	objects := connector.externalObjects()
+	if len(removedObjects(objects.Users, usernames)) > 0 {
+		users, err := connector.GetAllUsers()
+		if err != nil {
+			return err
+		}
+		if err = deleteRemovedObjects(&objects.Users, usernames, func(username string) error {
+			index := slices.IndexFunc(users, func(user graylogClient.User) bool { return user.Username == username })
+			if index < 0 {
+				return nil
+			}
+			return connector.deleteObject("users/id/"+users[index].Id, "user", username)
+		}); err != nil {
+			return err
+		}
+	}
+	return deleteRemovedObjects(&objects.Roles, roleNames, func(name string) error {
+		return connector.deleteObject("roles/"+name, "role", name)
+	})
+}
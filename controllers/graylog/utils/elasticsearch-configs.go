@@ -3,9 +3,9 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
-	"log"
+	"fmt"
+	"io"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -27,10 +27,16 @@ type Request struct {
 	Body   interface{} `json:"body,omitempty"`
 }
 
-func (connector *GraylogConnector) SendRequestToOpenSearch(method string, urlPath string, data []byte, cr *loggingService.LoggingService) error {
+// SendRequestToOpenSearch sends the request to OpenSearch and returns the body and the status code of the response.
+// The path can contain the query parameters
+func (connector *GraylogConnector) SendRequestToOpenSearch(method string, urlPath string, data []byte) ([]byte, int, error) {
+	urlPath, query, _ := strings.Cut(urlPath, "?")
 	requestUrl, err := url.JoinPath(connector.OpenSearchRestClient.Host, urlPath)
 	if err != nil {
-		return err
+		return nil, 0, err
+	}
+	if query != "" {
+		requestUrl += "?" + query
 	}
 	body := bytes.NewBuffer(data)
 
@@ -38,7 +44,7 @@ func (connector *GraylogConnector) SendRequestToOpenSearch(method string, urlPat
 
 	request, err := http.NewRequest(method, requestUrl, body)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
 	request.Header.Set("Content-Type", "application/json")
@@ -47,37 +53,46 @@ func (connector *GraylogConnector) SendRequestToOpenSearch(method string, urlPat
 	var response *http.Response
 	response, err = connector.OpenSearchRestClient.Client.Do(request)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
 	defer response.Body.Close()
 
-	b, err := httputil.DumpResponse(response, true)
+	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, 0, err
 	}
-	connector.Log.V(util.Debug).Info(string(b))
+	connector.Log.V(util.Debug).Info(fmt.Sprintf("Response status: %v. Body: %s", response.StatusCode, responseBody))
 
-	return nil
+	return responseBody, response.StatusCode, nil
 }
 
-func (connector *GraylogConnector) UploadConfig(name string, cr *loggingService.LoggingService, configsDir string) error {
+// readConfigRequests parses the list of requests to OpenSearch from the config file
+func (connector *GraylogConnector) readConfigRequests(name string, cr *loggingService.LoggingService, configsDir string) ([]Request, error) {
 	fileContent, err := util.ReadFile(filepath.Join(dataDir, configsDir, name))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	rawConfigs, err := util.ParseTemplate(fileContent, filepath.Join(dataDir, configsDir, name), cr.ToParams())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var data map[string]json.RawMessage
 	if err = json.Unmarshal([]byte(rawConfigs), &data); err != nil {
-		return err
+		return nil, err
 	}
 
 	var allRequests []Request
 	if err = json.Unmarshal(data["requests"], &allRequests); err != nil {
+		return nil, err
+	}
+	return allRequests, nil
+}
+
+func (connector *GraylogConnector) UploadConfig(name string, cr *loggingService.LoggingService, configsDir string) error {
+	allRequests, err := connector.readConfigRequests(name, cr, configsDir)
+	if err != nil {
 		return err
 	}
 
@@ -86,7 +101,7 @@ func (connector *GraylogConnector) UploadConfig(name string, cr *loggingService.
 		if err != nil {
 			return err
 		}
-		if err = connector.SendRequestToOpenSearch(r.Method, r.URL, body, cr); err != nil {
+		if _, _, err = connector.SendRequestToOpenSearch(r.Method, r.URL, body); err != nil {
 			return err
 		}
 	}
@@ -126,7 +141,7 @@ func (connector *GraylogConnector) ManageArchivesDirectory(cr *loggingService.Lo
 	if strings.Contains(elasticsearchHost, "opensearch") {
 		data = strings.Replace(data, "elasticsearch", "opensearch", 1)
 	}
-	if err = connector.SendRequestToOpenSearch(http.MethodPut, snapshotArchivesRequestUrl, []byte(data), cr); err != nil {
+	if _, _, err = connector.SendRequestToOpenSearch(http.MethodPut, snapshotArchivesRequestUrl, []byte(data)); err != nil {
 		return err
 	}
 	return nil
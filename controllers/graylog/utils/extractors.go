@@ -2,7 +2,9 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"path"
 	"strconv"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
@@ -44,6 +46,7 @@ func (connector *GraylogConnector) CreateExtractor(template string, inputId stri
 	if statusCode != http.StatusCreated {
 		return errors.New("can't create extractor " + template)
 	}
+	connector.recordCreated("extractor", path.Base(template))
 	return nil
 }
 
@@ -59,6 +62,7 @@ func (connector *GraylogConnector) UpdateExtractor(template string, inputId stri
 	if statusCode != http.StatusOK {
 		return errors.New("can't update extractor " + template)
 	}
+	connector.recordUpdated("extractor", path.Base(template))
 	return nil
 }
 
@@ -123,7 +127,7 @@ func (connector *GraylogConnector) CreateOrUpdateExtractors(extractors []Entity,
 	return nil
 }
 
-func (connector *GraylogConnector) ManageExtractors(cr *loggingService.LoggingService, installGraylog5 bool) error {
+func (connector *GraylogConnector) ManageExtractors(cr *loggingService.LoggingService, capabilities *GraylogCapabilities) error {
 	inputs, err := connector.GetAllInputs()
 	if err != nil {
 		return err
@@ -136,14 +140,10 @@ func (connector *GraylogConnector) ManageExtractors(cr *loggingService.LoggingSe
 		return err
 	}
 
-	// Extractors APIs for Graylog 4 and 5 are different
-	var extractorsAssets map[string]string
-	if installGraylog5 {
-		extractorsAssets = util.Graylog5Extractors
-	} else {
-		extractorsAssets = util.Graylog4Extractors
-	}
-	if err = connector.CreateOrUpdateExtractors(extractors, extractorsAssets, id, cr); err != nil {
+	// Extractors APIs of Graylog versions are different
+	if len(capabilities.Extractors) == 0 {
+		connector.Log.Info(fmt.Sprintf("Graylog %s doesn't support extractors. Skip them", capabilities.Version))
+	} else if err = connector.CreateOrUpdateExtractors(extractors, capabilities.Extractors, id, cr); err != nil {
 		return err
 	}
 
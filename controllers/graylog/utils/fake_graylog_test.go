@@ -0,0 +1,475 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeResource is the collection of the objects in the fake Graylog REST API
+type fakeResource struct {
+	// path of the collection, "*" matches any segment
+	path string
+	// field of the list response with the objects, the objects are returned as the array if it's empty
+	field string
+	// key is the field with the id of the object
+	key string
+	// created and updated are the status codes of the creation and the update, the method is not allowed if it's 0
+	created int
+	updated int
+	// idField is the field of the creation response with the id, the created object is returned if it's empty
+	idField string
+	// wrap is the field of the creation response with the created object
+	wrap string
+	// byName allows to find the objects by their names instead of the ids as the lookup tables API of Graylog does
+	byName bool
+}
+
+// fakeResources are the collections of the objects managed by the operator. Nested collections go before
+// their parents, so the paths are matched in this order
+var fakeResources = []fakeResource{
+	{path: "streams/*/rules", field: "stream_rules", key: "id", created: http.StatusCreated, idField: "streamrule_id"},
+	{path: "streams/*/outputs", field: "outputs", key: "id"},
+	{path: "streams", field: "streams", key: "id", created: http.StatusCreated, updated: http.StatusOK, idField: "stream_id"},
+	{path: "system/indices/index_sets", field: "index_sets", key: "id", created: http.StatusOK, updated: http.StatusOK},
+	{path: "system/inputs/*/extractors", field: "extractors", key: "id", created: http.StatusCreated, updated: http.StatusOK, idField: "extractor_id"},
+	{path: "system/inputs", field: "inputs", key: "id", created: http.StatusCreated, updated: http.StatusCreated, idField: "id"},
+	{path: "system/outputs", field: "outputs", key: "id", created: http.StatusCreated, updated: http.StatusOK},
+	{path: "system/lookup/adapters", field: "data_adapters", key: "id", created: http.StatusOK, updated: http.StatusOK, byName: true},
+	{path: "system/lookup/caches", field: "caches", key: "id", created: http.StatusOK, updated: http.StatusOK, byName: true},
+	{path: "system/lookup/tables", field: "lookup_tables", key: "id", created: http.StatusOK, updated: http.StatusOK, byName: true},
+	{path: "system/grok", field: "patterns", key: "id", created: http.StatusCreated, updated: http.StatusOK},
+	{path: "system/pipelines/rule", key: "id", created: http.StatusOK, updated: http.StatusOK},
+	{path: "system/pipelines/pipeline", key: "id", created: http.StatusOK, updated: http.StatusOK},
+	{path: "users/*/tokens", field: "tokens", key: "id"},
+	{path: "users", field: "users", key: "id", created: http.StatusCreated, updated: http.StatusNoContent},
+	{path: "roles", field: "roles", key: "name", created: http.StatusCreated, updated: http.StatusOK},
+	{path: "dashboards", field: "elements", key: "id"},
+	{path: "views/search", key: "id", created: http.StatusCreated},
+	{path: "views", field: "views", key: "id", created: http.StatusOK, updated: http.StatusOK},
+	{path: "system/content_packs/*/installations", field: "installations", key: "_id", created: http.StatusOK},
+	{path: "system/content_packs", field: "content_packs", key: "id", created: http.StatusCreated},
+	{path: "events/notifications", field: "notifications", key: "id", created: http.StatusOK, updated: http.StatusOK},
+	{path: "events/definitions", field: "event_definitions", key: "id", created: http.StatusOK, updated: http.StatusOK},
+	{path: "system/authentication/services/backends", field: "backends", key: "id", created: http.StatusOK, updated: http.StatusOK, wrap: "backend"},
+	{path: "system/plugins", field: "plugins", key: "unique_id"},
+}
+
+// fakeSettings are the configs of Graylog which are only replaced by the operator
+var fakeSettings = []string{
+	"system/messageprocessors/config",
+	"system/authentication/http-header-auth-config",
+	"system/indices/mappings",
+	"system/pipelines/connections/to_stream",
+}
+
+var (
+	fakeIdRegexp = regexp.MustCompile(`/[0-9a-f]{24}(/|$)`)
+	// fakeRewrites are the aliases of the paths of the objects
+	fakeRewrites = []struct {
+		pattern     *regexp.Regexp
+		replacement string
+	}{
+		{regexp.MustCompile(`^users/id/`), "users/"},
+		{regexp.MustCompile(`^(system/content_packs/[^/]+)/[0-9]+/installations$`), "$1/installations"},
+	}
+)
+
+// fakeGraylog is the Graylog REST API which keeps the objects in memory
+type fakeGraylog struct {
+	t       *testing.T
+	server  *httptest.Server
+	version string
+
+	mu          sync.Mutex
+	lastId      int
+	collections map[string][]map[string]interface{}
+	settings    map[string]json.RawMessage
+	// files are served by their path for the downloads of the content packs
+	files map[string][]byte
+	// activeBackend is the id of the active authentication backend
+	activeBackend string
+	// rejectedBackend is the title of the authentication backend which is not activated by Graylog
+	rejectedBackend string
+	// requests are the methods and the paths of the received requests
+	requests []string
+}
+
+func newFakeGraylog(t *testing.T, version string) *fakeGraylog {
+	graylog := &fakeGraylog{
+		t:           t,
+		version:     version,
+		collections: map[string][]map[string]interface{}{},
+		settings:    map[string]json.RawMessage{},
+		files:       map[string][]byte{},
+	}
+	graylog.server = httptest.NewServer(graylog)
+	t.Cleanup(graylog.server.Close)
+	return graylog
+}
+
+// add puts the objects to the collection and returns the id of the last one
+func (graylog *fakeGraylog) add(path string, objects ...map[string]interface{}) string {
+	graylog.mu.Lock()
+	defer graylog.mu.Unlock()
+	resource, ok := findFakeResource(path)
+	if !ok {
+		graylog.t.Fatalf("unknown collection %s", path)
+	}
+	var id string
+	for _, object := range objects {
+		id = graylog.store(resource, path, object)
+	}
+	return id
+}
+
+// titles returns the sorted titles (names) of the objects of the collection
+func (graylog *fakeGraylog) titles(path string) []string {
+	graylog.mu.Lock()
+	defer graylog.mu.Unlock()
+	var titles []string
+	for _, object := range graylog.collections[path] {
+		for _, field := range []string{"title", "name", "username"} {
+			if title, ok := object[field].(string); ok {
+				titles = append(titles, title)
+				break
+			}
+		}
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+// changes returns the numbers of the requests which change objects by the methods and the paths.
+// The ids of the objects in the paths are replaced with {id}
+func (graylog *fakeGraylog) changes() map[string]int {
+	graylog.mu.Lock()
+	defer graylog.mu.Unlock()
+	changes := map[string]int{}
+	for _, request := range graylog.requests {
+		if strings.HasPrefix(request, http.MethodGet+" ") {
+			continue
+		}
+		changes[fakeIdRegexp.ReplaceAllString(request, "/{id}$1")]++
+	}
+	return changes
+}
+
+func (graylog *fakeGraylog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	graylog.mu.Lock()
+	defer graylog.mu.Unlock()
+
+	if file, ok := graylog.files[r.URL.Path]; ok {
+		_, _ = w.Write(file)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	for _, rewrite := range fakeRewrites {
+		path = rewrite.pattern.ReplaceAllString(path, rewrite.replacement)
+	}
+	graylog.requests = append(graylog.requests, r.Method+" "+path)
+
+	// Requests with the revoked access tokens are rejected
+	if token, password, ok := r.BasicAuth(); ok && password == accessTokenPassword && !graylog.hasToken(token) {
+		writeFakeResponse(w, http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+		return
+	}
+	// Requests with the wrong passwords of the users are rejected
+	if username, password, ok := r.BasicAuth(); ok && !graylog.acceptsPassword(username, password) {
+		writeFakeResponse(w, http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeFakeResponse(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	var body map[string]interface{}
+	if len(data) != 0 {
+		if err = json.Unmarshal(data, &body); err != nil {
+			writeFakeResponse(w, http.StatusBadRequest, map[string]string{"message": "invalid JSON: " + err.Error()})
+			return
+		}
+	}
+
+	if graylog.serveSystem(w, r.Method, path, body) {
+		return
+	}
+	segments := strings.Split(path, "/")
+	for _, resource := range fakeResources {
+		pattern := strings.Split(resource.path, "/")
+		if matchSegments(pattern, segments) {
+			graylog.serveCollection(w, r.Method, resource, path, body)
+			return
+		}
+		if len(segments) == len(pattern)+1 && matchSegments(pattern, segments[:len(pattern)]) {
+			graylog.serveObject(w, r.Method, resource, strings.Join(segments[:len(pattern)], "/"), segments[len(pattern)], body)
+			return
+		}
+	}
+	for _, setting := range fakeSettings {
+		if path != setting {
+			continue
+		}
+		if r.Method == http.MethodGet {
+			writeFakeResponse(w, http.StatusOK, graylog.settings[path])
+		} else {
+			graylog.settings[path] = data
+			writeFakeResponse(w, http.StatusOK, nil)
+		}
+		return
+	}
+	writeFakeResponse(w, http.StatusNotFound, map[string]string{"message": "HTTP 404 Not Found"})
+}
+
+// serveSystem serves the requests which are not the requests of the collections
+func (graylog *fakeGraylog) serveSystem(w http.ResponseWriter, method string, path string, body map[string]interface{}) bool {
+	segments := strings.Split(path, "/")
+	switch {
+	case method == http.MethodGet && path == "system":
+		writeFakeResponse(w, http.StatusOK, map[string]string{"version": graylog.version})
+	case method == http.MethodPost && len(segments) == 3 && segments[0] == "streams" && segments[2] == "resume":
+		if graylog.find("streams", "id", segments[1]) < 0 {
+			writeFakeResponse(w, http.StatusNotFound, map[string]string{"message": "Stream " + segments[1] + " not found"})
+		} else {
+			writeFakeResponse(w, http.StatusNoContent, nil)
+		}
+	case method == http.MethodPost && len(segments) == 3 && segments[0] == "streams" && segments[2] == "outputs":
+		// The outputs are attached by their ids, so the attached outputs are stored by the ids
+		outputsPath := strings.Join(segments, "/")
+		resource, _ := findFakeResource(outputsPath)
+		ids, _ := body["outputs"].([]interface{})
+		for _, id := range ids {
+			graylog.store(resource, outputsPath, map[string]interface{}{"id": id})
+		}
+		writeFakeResponse(w, http.StatusAccepted, nil)
+	case method == http.MethodPut && len(segments) == 3 && segments[0] == "users" && segments[2] == "password":
+		index := graylog.find("users", "id", segments[1])
+		if index < 0 {
+			writeFakeResponse(w, http.StatusNotFound, map[string]string{"message": "User " + segments[1] + " not found"})
+		} else {
+			graylog.collections["users"][index]["password"] = body["password"]
+			writeFakeResponse(w, http.StatusNoContent, nil)
+		}
+	case method == http.MethodPost && len(segments) == 4 && segments[0] == "users" && segments[2] == "tokens":
+		tokensPath := strings.Join(segments[:3], "/")
+		resource, _ := findFakeResource(tokensPath)
+		token := map[string]interface{}{"name": segments[3]}
+		id := graylog.store(resource, tokensPath, token)
+		token["token"] = "token-" + id
+		writeFakeResponse(w, http.StatusOK, token)
+	case len(segments) == 3 && segments[0] == "system" && segments[1] == "content_packs" && method == http.MethodGet:
+		revisions := map[string]interface{}{}
+		for _, contentPack := range graylog.collections["system/content_packs"] {
+			if contentPack["id"] == segments[2] {
+				revisions[fmt.Sprint(contentPack["rev"])] = contentPack
+			}
+		}
+		if len(revisions) == 0 {
+			writeFakeResponse(w, http.StatusNotFound, map[string]string{"message": "Content pack " + segments[2] + " not found"})
+		} else {
+			writeFakeResponse(w, http.StatusOK, map[string]interface{}{"content_pack_revisions": revisions})
+		}
+	case len(segments) == 4 && segments[0] == "system" && segments[1] == "content_packs" && method == http.MethodDelete:
+		index := graylog.find("system/content_packs", "id", segments[2])
+		if index < 0 || fmt.Sprint(graylog.collections["system/content_packs"][index]["rev"]) != segments[3] {
+			writeFakeResponse(w, http.StatusNotFound, map[string]string{"message": "Content pack " + segments[2] + " not found"})
+		} else {
+			objects := graylog.collections["system/content_packs"]
+			graylog.collections["system/content_packs"] = append(objects[:index:index], objects[index+1:]...)
+			writeFakeResponse(w, http.StatusNoContent, nil)
+		}
+	case method == http.MethodGet && path == "authz/roles":
+		var roles []map[string]interface{}
+		for _, role := range graylog.collections["roles"] {
+			roles = append(roles, map[string]interface{}{"id": "role-" + role["name"].(string), "name": role["name"]})
+		}
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{"roles": roles})
+	case method == http.MethodGet && path == "system/authentication/services/backends/active":
+		var active interface{}
+		if index := graylog.find("system/authentication/services/backends", "id", graylog.activeBackend); index >= 0 {
+			active = graylog.collections["system/authentication/services/backends"][index]
+		}
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{"backend": active})
+	case method == http.MethodPost && path == "system/authentication/services/configuration":
+		id, _ := body["active_backend"].(string)
+		index := graylog.find("system/authentication/services/backends", "id", id)
+		if index < 0 || graylog.collections["system/authentication/services/backends"][index]["title"] != graylog.rejectedBackend {
+			graylog.activeBackend = id
+		}
+		writeFakeResponse(w, http.StatusOK, body)
+	case method == http.MethodPost && path == "system/authentication/services/test/backend/connection":
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Connection successful", "errors": []string{}})
+	default:
+		return false
+	}
+	return true
+}
+
+func (graylog *fakeGraylog) serveCollection(w http.ResponseWriter, method string, resource fakeResource, path string, body map[string]interface{}) {
+	switch method {
+	case http.MethodGet:
+		objects := graylog.collections[path]
+		if objects == nil {
+			objects = []map[string]interface{}{}
+		}
+		if resource.field == "" {
+			writeFakeResponse(w, http.StatusOK, objects)
+		} else {
+			writeFakeResponse(w, http.StatusOK, map[string]interface{}{resource.field: objects, "total": len(objects)})
+		}
+	case http.MethodPost:
+		if resource.created == 0 {
+			writeFakeResponse(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+		id := graylog.store(resource, path, body)
+		switch {
+		case resource.idField != "":
+			writeFakeResponse(w, resource.created, map[string]string{resource.idField: id})
+		case resource.wrap != "":
+			writeFakeResponse(w, resource.created, map[string]interface{}{resource.wrap: body})
+		default:
+			writeFakeResponse(w, resource.created, body)
+		}
+	default:
+		writeFakeResponse(w, http.StatusMethodNotAllowed, nil)
+	}
+}
+
+func (graylog *fakeGraylog) serveObject(w http.ResponseWriter, method string, resource fakeResource, path string, id string, body map[string]interface{}) {
+	index := graylog.find(path, resource.key, id)
+	if index < 0 && resource.byName {
+		index = graylog.find(path, "name", id)
+	}
+	if index < 0 {
+		writeFakeResponse(w, http.StatusNotFound, map[string]string{"message": fmt.Sprintf("Couldn't find object %s in %s", id, path)})
+		return
+	}
+	switch method {
+	case http.MethodGet:
+		writeFakeResponse(w, http.StatusOK, graylog.collections[path][index])
+	case http.MethodPut:
+		if resource.updated == 0 {
+			writeFakeResponse(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+		if body == nil {
+			body = map[string]interface{}{}
+		}
+		body[resource.key] = graylog.collections[path][index][resource.key]
+		// The password of the user is changed only by the separate request
+		if password, found := graylog.collections[path][index]["password"]; found && path == "users" {
+			body["password"] = password
+		}
+		graylog.collections[path][index] = body
+		if resource.updated == http.StatusNoContent {
+			writeFakeResponse(w, resource.updated, nil)
+		} else {
+			writeFakeResponse(w, resource.updated, body)
+		}
+	case http.MethodDelete:
+		objects := graylog.collections[path]
+		graylog.collections[path] = append(objects[:index:index], objects[index+1:]...)
+		writeFakeResponse(w, http.StatusNoContent, nil)
+	default:
+		writeFakeResponse(w, http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// store adds the object to the collection or replaces the object with the same id
+func (graylog *fakeGraylog) store(resource fakeResource, path string, object map[string]interface{}) string {
+	if object == nil {
+		object = map[string]interface{}{}
+	}
+	id, _ := object[resource.key].(string)
+	if id == "" {
+		// The ids of the created objects differ from the ids of the objects created by Graylog on the start
+		graylog.lastId++
+		id = fmt.Sprintf("65%022x", graylog.lastId)
+		object[resource.key] = id
+	}
+	if index := graylog.find(path, resource.key, id); index >= 0 {
+		graylog.collections[path][index] = object
+	} else {
+		graylog.collections[path] = append(graylog.collections[path], object)
+	}
+	return id
+}
+
+// hasToken checks that the access token is created and not revoked
+func (graylog *fakeGraylog) hasToken(token string) bool {
+	for path, objects := range graylog.collections {
+		if !strings.HasSuffix(path, "/tokens") {
+			continue
+		}
+		for _, object := range objects {
+			if object["token"] == token {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// acceptsPassword checks the password of the user with the password set, other users are not checked
+func (graylog *fakeGraylog) acceptsPassword(username string, password string) bool {
+	index := graylog.find("users", "username", username)
+	if index < 0 {
+		return true
+	}
+	expected, found := graylog.collections["users"][index]["password"]
+	return !found || expected == password
+}
+
+func (graylog *fakeGraylog) find(path string, key string, id string) int {
+	for i, object := range graylog.collections[path] {
+		if object[key] == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findFakeResource(path string) (fakeResource, bool) {
+	segments := strings.Split(path, "/")
+	for _, resource := range fakeResources {
+		if matchSegments(strings.Split(resource.path, "/"), segments) {
+			return resource, true
+		}
+	}
+	return fakeResource{}, false
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeFakeResponse(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	switch value := body.(type) {
+	case nil:
+	case json.RawMessage:
+		_, _ = w.Write(value)
+	default:
+		_ = json.NewEncoder(w).Encode(value)
+	}
+}
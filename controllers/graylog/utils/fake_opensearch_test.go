@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeOpenSearch is the OpenSearch REST API which keeps the objects in memory by their paths
+type fakeOpenSearch struct {
+	server       *httptest.Server
+	distribution string
+
+	mu      sync.Mutex
+	objects map[string]map[string]interface{}
+	// seqNo is the sequence number of the ISM policies
+	seqNo int
+	// requests are the methods and the paths of the received requests
+	requests []string
+}
+
+func newFakeOpenSearch(t *testing.T, distribution string) *fakeOpenSearch {
+	openSearch := &fakeOpenSearch{distribution: distribution, objects: map[string]map[string]interface{}{}}
+	openSearch.server = httptest.NewServer(openSearch)
+	t.Cleanup(openSearch.server.Close)
+	return openSearch
+}
+
+// changes returns the numbers of the requests which change objects by the methods and the paths
+func (openSearch *fakeOpenSearch) changes() map[string]int {
+	openSearch.mu.Lock()
+	defer openSearch.mu.Unlock()
+	changes := map[string]int{}
+	for _, request := range openSearch.requests {
+		if !strings.HasPrefix(request, http.MethodGet+" ") {
+			changes[request]++
+		}
+	}
+	return changes
+}
+
+func (openSearch *fakeOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	openSearch.mu.Lock()
+	defer openSearch.mu.Unlock()
+
+	path := strings.Trim(r.URL.Path, "/")
+	openSearch.requests = append(openSearch.requests, r.Method+" "+path)
+
+	var body map[string]interface{}
+	if data, err := io.ReadAll(r.Body); err != nil || (len(data) != 0 && json.Unmarshal(data, &body) != nil) {
+		writeFakeResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid body"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		openSearch.get(w, path)
+	case http.MethodPut, http.MethodPost:
+		openSearch.put(w, r, path, body)
+	case http.MethodDelete:
+		if _, found := openSearch.objects[path]; !found {
+			writeFakeResponse(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		delete(openSearch.objects, path)
+		writeFakeResponse(w, http.StatusOK, map[string]bool{"acknowledged": true})
+	default:
+		writeFakeResponse(w, http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// get returns the object in the format of the API of its type
+func (openSearch *fakeOpenSearch) get(w http.ResponseWriter, path string) {
+	switch path {
+	case "":
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{"version": map[string]string{"distribution": openSearch.distribution, "number": "2.11.0"}})
+		return
+	case clusterHealthUrl:
+		writeFakeResponse(w, http.StatusOK, OpenSearchHealth{Status: "green", NumberOfNodes: 1})
+		return
+	case clusterSettingsUrl:
+		persistent := openSearch.objects[path]
+		if persistent == nil {
+			persistent = map[string]interface{}{}
+		}
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{"persistent": persistent, "transient": map[string]interface{}{}})
+		return
+	}
+
+	object, found := openSearch.objects[path]
+	if !found {
+		writeFakeResponse(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	name := path[strings.LastIndex(path, "/")+1:]
+	switch {
+	case strings.HasPrefix(path, indexTemplateUrl):
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{"index_templates": []interface{}{map[string]interface{}{"name": name, "index_template": object}}})
+	case strings.HasPrefix(path, ismPoliciesUrl):
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{"_id": name, "_seq_no": object["_seq_no"], "_primary_term": 1, "policy": object["policy"]})
+	case strings.HasPrefix(path, ilmPolicyUrl):
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{name: map[string]interface{}{"version": 1, "policy": object["policy"]}})
+	default:
+		writeFakeResponse(w, http.StatusOK, object)
+	}
+}
+
+func (openSearch *fakeOpenSearch) put(w http.ResponseWriter, r *http.Request, path string, body map[string]interface{}) {
+	switch {
+	case path == clusterSettingsUrl:
+		persistent := openSearch.objects[path]
+		if persistent == nil {
+			persistent = map[string]interface{}{}
+		}
+		settings, _ := body["persistent"].(map[string]interface{})
+		for key, value := range settings {
+			// The null value resets the setting to its default
+			if value == nil {
+				delete(persistent, key)
+			} else {
+				persistent[key] = value
+			}
+		}
+		openSearch.objects[path] = persistent
+	case strings.HasPrefix(path, ismAddPolicyUrl):
+		writeFakeResponse(w, http.StatusOK, map[string]interface{}{"updated_indices": 0, "failures": false})
+		return
+	case strings.HasPrefix(path, ismPoliciesUrl):
+		// The existing policy is replaced only with its sequence number
+		if existing, found := openSearch.objects[path]; found && r.URL.Query().Get("if_seq_no") != jsonString(existing["_seq_no"]) {
+			writeFakeResponse(w, http.StatusConflict, map[string]string{"error": "version_conflict_engine_exception"})
+			return
+		}
+		openSearch.seqNo++
+		body["_seq_no"] = openSearch.seqNo
+		openSearch.objects[path] = body
+	default:
+		openSearch.objects[path] = body
+	}
+	writeFakeResponse(w, http.StatusOK, map[string]bool{"acknowledged": true})
+}
+
+func jsonString(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
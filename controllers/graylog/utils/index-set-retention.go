@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+)
+
+const (
+	countBasedRotation         = "countBased"
+	timeSizeOptimizingRotation = "timeSizeOptimizing"
+	closeRetention             = "close"
+	snapshotRetention          = "snapshot"
+
+	strategiesPackage         = "org.graylog2.indexer."
+	defaultMaxDocsPerIndex    = 20000000
+	defaultIndexLifetimeMin   = "P30D"
+	defaultIndexLifetimeMax   = "P40D"
+	defaultSnapshotAfter      = "30d"
+	ismPoliciesUrl            = "_plugins/_ism/policies/"
+	ismAddPolicyUrl           = "_plugins/_ism/add/"
+	indexAliasesUrlFormat     = "%s_*/_alias"
+	indexMappingsUrl          = "system/indices/mappings"
+	snapshotPolicyTitleFormat = "graylog-%s-snapshot"
+)
+
+// streamOfIndexSet returns the enabled stream which owns the index set
+func (connector *GraylogConnector) streamOfIndexSet(indexSetName string) *Stream {
+	for i := range connector.EnabledStreams {
+		if connector.EnabledStreams[i].IndexSet == indexSetName && !connector.EnabledStreams[i].SharedIndexSet {
+			return &connector.EnabledStreams[i]
+		}
+	}
+	return nil
+}
+
+// applyStreamSettings overrides rotation, retention and analyzer of the index set template
+// with the settings of the stream which are not supported by the template
+func (connector *GraylogConnector) applyStreamSettings(data string, indexSetName string, cr *loggingService.LoggingService) (string, error) {
+	stream := connector.streamOfIndexSet(indexSetName)
+	if stream == nil {
+		return data, nil
+	}
+	rotation := stream.RotationStrategy
+	if rotation == timeSizeOptimizingRotation && (connector.Capabilities == nil || !connector.Capabilities.TimeSizeOptimizingRotation) {
+		connector.Log.Info(fmt.Sprintf("Rotation strategy %s of the stream %s requires Graylog 5.1 or later. Size based rotation is used", rotation, stream.Title))
+		rotation = ""
+	}
+	if rotation != countBasedRotation && rotation != timeSizeOptimizingRotation &&
+		stream.RetentionStrategy != closeRetention && stream.RetentionStrategy != snapshotRetention && stream.IndexAnalyzer == "" {
+		return data, nil
+	}
+
+	var indexSet map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &indexSet); err != nil {
+		return "", fmt.Errorf("can't parse template of %s: %w", indexSetName, err)
+	}
+
+	switch rotation {
+	case countBasedRotation:
+		maxDocs := stream.MaxDocsPerIndex
+		if maxDocs == 0 {
+			maxDocs = defaultMaxDocsPerIndex
+		}
+		setStrategy(indexSet, "rotation", "rotation.strategies.MessageCountRotationStrategy", map[string]interface{}{"max_docs_per_index": maxDocs})
+	case timeSizeOptimizingRotation:
+		lifetimeMin, lifetimeMax := stream.IndexLifetimeMin, stream.IndexLifetimeMax
+		if lifetimeMin == "" {
+			lifetimeMin = defaultIndexLifetimeMin
+		}
+		if lifetimeMax == "" {
+			lifetimeMax = defaultIndexLifetimeMax
+		}
+		indexSet["rotation_strategy_class"] = strategiesPackage + "rotation.strategies.TimeBasedSizeOptimizingStrategy"
+		indexSet["rotation_strategy"] = map[string]interface{}{
+			"type":               strategiesPackage + "rotation.strategies.TimeBasedSizeOptimizingStrategyConfig",
+			"index_lifetime_min": lifetimeMin,
+			"index_lifetime_max": lifetimeMax,
+		}
+	}
+
+	switch stream.RetentionStrategy {
+	case closeRetention:
+		setStrategy(indexSet, "retention", "retention.strategies.ClosingRetentionStrategy", map[string]interface{}{"max_number_of_indices": maxNumberOfIndices(indexSet)})
+	case snapshotRetention:
+		// Indices are saved to the snapshot and deleted by the ISM policy, so Graylog must not delete them
+		setStrategy(indexSet, "retention", "retention.strategies.NoopRetentionStrategy", map[string]interface{}{"max_number_of_indices": math.MaxInt32})
+	}
+
+	if stream.IndexAnalyzer != "" {
+		indexSet["index_analyzer"] = stream.IndexAnalyzer
+	}
+
+	result, err := json.Marshal(indexSet)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+func setStrategy(indexSet map[string]interface{}, kind string, class string, config map[string]interface{}) {
+	indexSet[kind+"_strategy_class"] = strategiesPackage + class
+	config["type"] = strategiesPackage + class + "Config"
+	indexSet[kind+"_strategy"] = config
+}
+
+func maxNumberOfIndices(indexSet map[string]interface{}) interface{} {
+	if retention, ok := indexSet["retention_strategy"].(map[string]interface{}); ok && retention["max_number_of_indices"] != nil {
+		return retention["max_number_of_indices"]
+	}
+	return 20
+}
+
+// indexPrefix returns the prefix of the indices of the index set from its template
+func (connector *GraylogConnector) indexPrefix(indexSetName string, cr *loggingService.LoggingService) (string, error) {
+	path := connector.GetIndexSets()[indexSetName]
+	data, err := connector.indexSetData(cr, path, indexSetName)
+	if err != nil {
+		return "", err
+	}
+	var indexSet struct {
+		IndexPrefix string `json:"index_prefix"`
+	}
+	if err = json.Unmarshal([]byte(data), &indexSet); err != nil {
+		return "", err
+	}
+	return indexSet.IndexPrefix, nil
+}
+
+// ManageSnapshotPolicies creates ISM policies in OpenSearch which save the indices of the streams
+// with the snapshot retention to the archives repository and delete them after the configured age
+func (connector *GraylogConnector) ManageSnapshotPolicies(cr *loggingService.LoggingService) error {
+	for _, stream := range connector.EnabledStreams {
+		if stream.RetentionStrategy != snapshotRetention || stream.SharedIndexSet {
+			continue
+		}
+		prefix, err := connector.indexPrefix(stream.IndexSet, cr)
+		if err != nil {
+			return err
+		}
+		snapshotAfter := stream.SnapshotAfter
+		if snapshotAfter == "" {
+			snapshotAfter = defaultSnapshotAfter
+		}
+		policyId := fmt.Sprintf(snapshotPolicyTitleFormat, prefix)
+		if err = connector.manageISMPolicy(policyId, snapshotPolicyBody(stream.Title, prefix, snapshotAfter)); err != nil {
+			return err
+		}
+		// The policy doesn't have ISM template, because the template is applied to the new write index,
+		// so the policy is attached only to the indices which are rotated already
+		indices, err := connector.rotatedIndices(prefix)
+		if err != nil {
+			return err
+		}
+		if len(indices) == 0 {
+			continue
+		}
+		if err = connector.addSnapshotPolicy(policyId, strings.Join(indices, ",")); err != nil {
+			return err
+		}
+		connector.Log.Info("ISM policy " + policyId + " is applied to the indices " + strings.Join(indices, ","))
+	}
+	return nil
+}
+
+func snapshotPolicyBody(streamTitle string, prefix string, snapshotAfter string) map[string]interface{} {
+	return map[string]interface{}{
+		"policy": map[string]interface{}{
+			"description":   fmt.Sprintf("Snapshot of %s indices to the archives repository and their deletion", streamTitle),
+			"default_state": "hot",
+			"states": []interface{}{
+				map[string]interface{}{
+					"name":        "hot",
+					"actions":     []interface{}{},
+					"transitions": []interface{}{ismTransition("snapshot", snapshotAfter)},
+				},
+				map[string]interface{}{
+					"name":        "snapshot",
+					"actions":     []interface{}{map[string]interface{}{"snapshot": map[string]interface{}{"repository": "archives", "snapshot": prefix}}},
+					"transitions": []interface{}{map[string]interface{}{"state_name": "delete"}},
+				},
+				map[string]interface{}{
+					"name":        "delete",
+					"actions":     []interface{}{map[string]interface{}{"delete": map[string]interface{}{}}},
+					"transitions": []interface{}{},
+				},
+			},
+		},
+	}
+}
+
+// rotatedIndices returns the indices of the index set with the prefix except the active write index
+// which is the target of the deflector alias
+func (connector *GraylogConnector) rotatedIndices(prefix string) ([]string, error) {
+	aliases, err := connector.getOpenSearchObject(fmt.Sprintf(indexAliasesUrlFormat, prefix), nil)
+	if err != nil {
+		return nil, err
+	}
+	var indices []string
+	for index, value := range aliases {
+		// The pattern matches the indices of other index sets with the prefix which starts with this one
+		if _, err = strconv.Atoi(strings.TrimPrefix(index, prefix+"_")); err != nil {
+			continue
+		}
+		if object, ok := value.(map[string]interface{}); ok {
+			if indexAliases, ok := object["aliases"].(map[string]interface{}); ok && indexAliases[prefix+"_deflector"] != nil {
+				continue
+			}
+		}
+		indices = append(indices, index)
+	}
+	slices.Sort(indices)
+	return indices, nil
+}
+
+// addSnapshotPolicy attaches the ISM policy to the indices. The indices which are managed already are skipped
+func (connector *GraylogConnector) addSnapshotPolicy(policyId string, indices string) error {
+	response, statusCode, err := connector.SendRequestToOpenSearch(http.MethodPost, ismAddPolicyUrl+indices, []byte(`{"policy_id":"`+policyId+`"}`))
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("can't attach ISM policy %s to the indices %s. Status code: %v. Response: %s", policyId, indices, statusCode, response)
+	}
+	var result struct {
+		FailedIndices []struct {
+			IndexName string `json:"index_name"`
+			Reason    string `json:"reason"`
+		} `json:"failed_indices"`
+	}
+	if err = json.Unmarshal(response, &result); err != nil {
+		return err
+	}
+	for _, failed := range result.FailedIndices {
+		if !strings.Contains(failed.Reason, "already has a policy") {
+			return fmt.Errorf("can't attach ISM policy %s to the index %s: %s", policyId, failed.IndexName, failed.Reason)
+		}
+	}
+	return nil
+}
+
+// DeleteSnapshotPolicies deletes ISM policies created for the streams with the snapshot retention
+func (connector *GraylogConnector) DeleteSnapshotPolicies(cr *loggingService.LoggingService) error {
+	for _, stream := range connector.EnabledStreams {
+		if stream.RetentionStrategy != snapshotRetention || stream.SharedIndexSet {
+			continue
+		}
+		prefix, err := connector.indexPrefix(stream.IndexSet, cr)
+		if err != nil {
+			return err
+		}
+		if err = connector.deleteOpenSearchObject(ismPoliciesUrl + fmt.Sprintf(snapshotPolicyTitleFormat, prefix)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ManageFieldTypes sets custom types of the message fields in the index sets of the streams.
+// New types are applied after the next rotation of the index set.
+func (connector *GraylogConnector) ManageFieldTypes(indexSets []Entity, cr *loggingService.LoggingService) error {
+	for _, stream := range connector.EnabledStreams {
+		if len(stream.FieldTypes) == 0 {
+			continue
+		}
+		if connector.Capabilities == nil || !connector.Capabilities.FieldTypes {
+			connector.Log.Info(fmt.Sprintf("Custom field types of the stream %s require Graylog 5.1 or later. Skip them", stream.Title))
+			continue
+		}
+		indexSetName := stream.IndexSet
+		id := GetIdByTitle(indexSets, indexSetName)
+		if id == "" {
+			return fmt.Errorf("index set %s of the stream %s not found", indexSetName, stream.Title)
+		}
+		for field, fieldType := range stream.FieldTypes {
+			body, err := json.Marshal(map[string]interface{}{
+				"index_sets": []string{id},
+				"field":      field,
+				"type":       fieldType,
+				"rotate":     false,
+			})
+			if err != nil {
+				return err
+			}
+			response, statusCode, err := connector.PUT(indexMappingsUrl, string(body))
+			if err != nil {
+				return err
+			}
+			if statusCode != http.StatusOK && statusCode != http.StatusNoContent {
+				return fmt.Errorf("can't set type %s of the field %s in %s. Status code: %v. Response: %s", fieldType, field, indexSetName, statusCode, response)
+			}
+		}
+	}
+	return nil
+}
@@ -1,50 +1,65 @@
 package utils
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 )
 
 func (connector *GraylogConnector) GetAllIndexSets() ([]Entity, error) {
-	indexSets, err := connector.GetData(indexSetsUrl, "index_sets", nil)
+	indexSets, err := connector.Client.ListIndexSets(connector.context())
 	if err != nil {
 		return nil, err
 	}
-	return indexSets, nil
+	entities := make([]Entity, 0, len(indexSets))
+	for _, indexSet := range indexSets {
+		entities = append(entities, Entity{Id: indexSet.Id, Title: indexSet.Title})
+	}
+	return entities, nil
 }
 
 func (connector *GraylogConnector) UpdateIndexSet(id string, cr *loggingService.LoggingService, path string, indexSetName string) error {
-	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, path), path, cr.ToParams())
+	indexSet, err := connector.indexSetBody(cr, path, indexSetName)
 	if err != nil {
 		return err
 	}
-	_, statusCode, err := connector.PUT(indexSetsUrl+"/"+id, data)
-	if err != nil {
-		return err
-	}
-	if statusCode != http.StatusOK {
-		return errors.New("can't update " + indexSetName)
+	if _, err = connector.Client.UpdateIndexSet(connector.context(), id, indexSet); err != nil {
+		return fmt.Errorf("can't update %s: %w", indexSetName, err)
 	}
+	connector.recordUpdated("index set", indexSetName)
 	return nil
 }
 
 func (connector *GraylogConnector) CreateIndexSet(cr *loggingService.LoggingService, path string, indexSetName string) error {
-	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, path), path, cr.ToParams())
+	indexSet, err := connector.indexSetBody(cr, path, indexSetName)
 	if err != nil {
 		return err
 	}
-	response, statusCode, err := connector.POST(indexSetsUrl, data)
+	if _, err = connector.Client.CreateIndexSet(connector.context(), indexSet); err != nil {
+		return fmt.Errorf("can't create %s: %w", indexSetName, err)
+	}
+	connector.recordCreated("index set", indexSetName)
+	return nil
+}
+
+// indexSetBody returns the index set from the template with the settings of its stream
+func (connector *GraylogConnector) indexSetBody(cr *loggingService.LoggingService, path string, indexSetName string) (graylogClient.IndexSet, error) {
+	var indexSet graylogClient.IndexSet
+	data, err := connector.indexSetData(cr, path, indexSetName)
 	if err != nil {
-		return err
+		return indexSet, err
 	}
-	if statusCode != http.StatusOK {
-		return fmt.Errorf("can't create %s. Status code: %v. Response: %s", indexSetName, statusCode, response)
+	if data, err = connector.applyStreamSettings(data, indexSetName, cr); err != nil {
+		return indexSet, err
 	}
-	return nil
+	if err = json.Unmarshal([]byte(data), &indexSet); err != nil {
+		return indexSet, fmt.Errorf("can't parse template of %s: %w", indexSetName, err)
+	}
+	return indexSet, nil
 }
 
 func (connector *GraylogConnector) CreateOrUpdateIndexSet(indexSets []Entity, cr *loggingService.LoggingService, path string, title string) error {
@@ -79,7 +94,20 @@ func (connector *GraylogConnector) ManageIndexSets(cr *loggingService.LoggingSer
 		}
 	}
 
-	return nil
+	indexSets, err = connector.GetAllIndexSets()
+	if err != nil {
+		return err
+	}
+	return connector.ManageFieldTypes(indexSets, cr)
+}
+
+// indexSetData returns the index set from the template. The index set of the custom stream
+// is built from the settings of the stream.
+func (connector *GraylogConnector) indexSetData(cr *loggingService.LoggingService, path string, indexSetName string) (string, error) {
+	if stream := connector.streamOfIndexSet(indexSetName); stream != nil && stream.Custom {
+		return customIndexSetData(cr, stream)
+	}
+	return util.ParseTemplate(util.MustAssetReader(connector.Assets, path), path, cr.ToParams())
 }
 
 func (connector *GraylogConnector) GetIndexSets() map[string]string {
@@ -88,14 +116,12 @@ func (connector *GraylogConnector) GetIndexSets() map[string]string {
 		util.GraylogAuditIndexSet:   util.GraylogIndexConfigs[util.GraylogAuditIndexSet],
 	}
 
-	for i := range connector.EnabledStreams {
-		stream := connector.EnabledStreams[i].Title
-		for k, v := range util.GraylogStreamsIndexTitles {
-			if k == stream {
-				availableIndexSets[v] = util.GraylogIndexConfigs[v]
-				break
-			}
+	for _, stream := range connector.EnabledStreams {
+		if stream.SharedIndexSet {
+			continue
 		}
+		// The index set of the custom stream has no template
+		availableIndexSets[stream.IndexSet] = util.GraylogIndexConfigs[stream.IndexSet]
 	}
 	return availableIndexSets
 }
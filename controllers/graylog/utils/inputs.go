@@ -1,52 +1,61 @@
 package utils
 
 import (
-	"errors"
-	"net/http"
+	"encoding/json"
+	"fmt"
 	"strconv"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 )
 
 func (connector *GraylogConnector) GetAllInputs() ([]Entity, error) {
-	inputs, err := connector.GetData(inputsUrl, "inputs", nil)
+	inputs, err := connector.Client.ListInputs(connector.context())
 	if err != nil {
 		return nil, err
 	}
-	return inputs, nil
+	entities := make([]Entity, 0, len(inputs))
+	for _, input := range inputs {
+		entities = append(entities, Entity{Id: input.Id, Title: input.Title})
+	}
+	return entities, nil
 }
 
 func (connector *GraylogConnector) UpdateDefaultInput(id string, cr *loggingService.LoggingService) error {
-	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, util.GraylogInput), util.GraylogInput, cr.ToParams())
+	input, err := connector.defaultInputBody(cr)
 	if err != nil {
 		return err
 	}
-	_, statusCode, err := connector.PUT(inputsUrl+"/"+id, data)
-	if err != nil {
-		return err
-	}
-	if statusCode != http.StatusCreated {
-		return errors.New("can't update default input")
+	if err = connector.Client.UpdateInput(connector.context(), id, input); err != nil {
+		return fmt.Errorf("can't update default input: %w", err)
 	}
+	connector.recordUpdated("input", input.Title)
 	return nil
 }
 
 func (connector *GraylogConnector) CreateDefaultInput(cr *loggingService.LoggingService) error {
-	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, util.GraylogInput), util.GraylogInput, cr.ToParams())
-	if err != nil {
-		return err
-	}
-	_, statusCode, err := connector.POST(inputsUrl, data)
+	input, err := connector.defaultInputBody(cr)
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusCreated {
-		return errors.New("can't create default input")
+	if _, err = connector.Client.CreateInput(connector.context(), input); err != nil {
+		return fmt.Errorf("can't create default input: %w", err)
 	}
+	connector.recordCreated("input", input.Title)
 	return nil
 }
 
+func (connector *GraylogConnector) defaultInputBody(cr *loggingService.LoggingService) (graylogClient.Input, error) {
+	var input graylogClient.Input
+	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, util.GraylogInput), util.GraylogInput, cr.ToParams())
+	if err != nil {
+		return input, err
+	}
+	err = json.Unmarshal([]byte(data), &input)
+	return input, err
+}
+
 func (connector *GraylogConnector) CreateOrUpdateDefaultInput(inputs []Entity, cr *loggingService.LoggingService) error {
 	id := GetIdByTitle(inputs, "input-"+strconv.Itoa(cr.Spec.Graylog.InputPort))
 	if id == "" {
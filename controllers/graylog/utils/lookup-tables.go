@@ -0,0 +1,365 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+)
+
+const (
+	lookupKeyColumn   = "key"
+	lookupValueColumn = "value"
+	// lookupInterval is the default interval in seconds of the check of the files and of the cache expiration
+	lookupInterval = 60
+)
+
+// LookupTableFile returns the path of the file from the key of the ConfigMap mounted into the Graylog pods
+func LookupTableFile(configMap string, key string) string {
+	return path.Join(util.GraylogLookupTablesPath, configMap, key)
+}
+
+// LookupTableConfigMaps returns the sorted names of the ConfigMaps with the files of the data adapters,
+// they are mounted into the Graylog pods
+func LookupTableConfigMaps(cr *loggingService.LoggingService) []string {
+	if cr.Spec.Graylog == nil || cr.Spec.Graylog.LookupTables == nil {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, adapter := range cr.Spec.Graylog.LookupTables.DataAdapters {
+		switch {
+		case adapter.Type == "csv" && adapter.CSV != nil:
+			names[adapter.CSV.ConfigMap.Name] = true
+		case adapter.Type == "inmemory":
+			names[util.GraylogLookupTablesConfigMap] = true
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// InMemoryLookupData returns the CSV files with the entries of the inmemory data adapters by the file names
+func InMemoryLookupData(cr *loggingService.LoggingService) (map[string]string, error) {
+	data := map[string]string{}
+	if cr.Spec.Graylog == nil || cr.Spec.Graylog.LookupTables == nil {
+		return data, nil
+	}
+	for _, adapter := range cr.Spec.Graylog.LookupTables.DataAdapters {
+		if adapter.Type != "inmemory" {
+			continue
+		}
+		keys := make([]string, 0, len(adapter.Entries))
+		for key := range adapter.Entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+		if err := writer.Write([]string{lookupKeyColumn, lookupValueColumn}); err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if err := writer.Write([]string{key, adapter.Entries[key]}); err != nil {
+				return nil, err
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return nil, err
+		}
+		data[adapter.Name+".csv"] = buffer.String()
+	}
+	return data, nil
+}
+
+// ManageLookupTables creates the data adapters, the caches and the lookup tables described in the custom resource.
+// Existing objects are updated only with the force-update content deploy policy. The objects created
+// by the operator before and removed from the custom resource are deleted
+func (connector *GraylogConnector) ManageLookupTables(cr *loggingService.LoggingService) error {
+	lookupTables := cr.Spec.Graylog.LookupTables
+	if lookupTables == nil {
+		lookupTables = &loggingService.GraylogLookupTables{}
+	}
+	objects := connector.externalObjects()
+
+	var adapterNames, cacheNames, tableNames []string
+	for _, a := range lookupTables.DataAdapters {
+		adapter, err := lookupDataAdapter(a)
+		if err != nil {
+			return err
+		}
+		adapterNames = append(adapterNames, a.Name)
+		trackObject(&objects.LookupDataAdapters, a.Name)
+		if err = connector.manageLookupObject(cr, "lookup data adapter", a.Name,
+			func() (string, error) {
+				existing, err := connector.Client.GetLookupDataAdapter(connector.context(), a.Name)
+				if err != nil {
+					return "", err
+				}
+				return existing.Id, nil
+			},
+			func() error {
+				_, err := connector.Client.CreateLookupDataAdapter(connector.context(), *adapter)
+				return err
+			},
+			func(id string) error {
+				adapter.Id = id
+				return connector.Client.UpdateLookupDataAdapter(connector.context(), *adapter)
+			}); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range lookupTables.Caches {
+		cache := lookupCache(c)
+		cacheNames = append(cacheNames, c.Name)
+		trackObject(&objects.LookupCaches, c.Name)
+		if err := connector.manageLookupObject(cr, "lookup cache", c.Name,
+			func() (string, error) {
+				existing, err := connector.Client.GetLookupCache(connector.context(), c.Name)
+				if err != nil {
+					return "", err
+				}
+				return existing.Id, nil
+			},
+			func() error {
+				_, err := connector.Client.CreateLookupCache(connector.context(), *cache)
+				return err
+			},
+			func(id string) error {
+				cache.Id = id
+				return connector.Client.UpdateLookupCache(connector.context(), *cache)
+			}); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range lookupTables.Tables {
+		table, err := connector.lookupTable(t)
+		if err != nil {
+			return err
+		}
+		tableNames = append(tableNames, t.Name)
+		trackObject(&objects.LookupTables, t.Name)
+		if err = connector.manageLookupObject(cr, "lookup table", t.Name,
+			func() (string, error) {
+				existing, err := connector.Client.GetLookupTable(connector.context(), t.Name)
+				if err != nil {
+					return "", err
+				}
+				return existing.Id, nil
+			},
+			func() error {
+				_, err := connector.Client.CreateLookupTable(connector.context(), *table)
+				return err
+			},
+			func(id string) error {
+				table.Id = id
+				return connector.Client.UpdateLookupTable(connector.context(), *table)
+			}); err != nil {
+			return err
+		}
+	}
+	return connector.deleteRemovedLookupTables(tableNames, cacheNames, adapterNames)
+}
+
+// manageLookupObject creates the object if it is not found by the name and updates the existing one
+// with the force-update content deploy policy
+func (connector *GraylogConnector) manageLookupObject(cr *loggingService.LoggingService, objectType string, name string,
+	getId func() (string, error), create func() error, update func(id string) error) error {
+	id, err := getId()
+	switch {
+	case graylogClient.IsNotFound(err):
+		if err = create(); err != nil {
+			return fmt.Errorf("can't create %s %s: %w", objectType, name, err)
+		}
+		connector.recordCreated(objectType, name)
+	case err != nil:
+		return err
+	case cr.Spec.Graylog.IsForceUpdate():
+		if err = update(id); err != nil {
+			return fmt.Errorf("can't update %s %s: %w", objectType, name, err)
+		}
+		connector.recordUpdated(objectType, name)
+	}
+	return nil
+}
+
+// lookupDataAdapter returns the data adapter with the configuration of its type. The inmemory data adapter
+// reads the entries from the file of the ConfigMap created by the operator
+func lookupDataAdapter(a loggingService.GraylogLookupDataAdapter) (*graylogClient.LookupDataAdapter, error) {
+	var config map[string]interface{}
+	switch a.Type {
+	case "csv":
+		if a.CSV == nil {
+			return nil, fmt.Errorf("csv is required for lookup data adapter %s", a.Name)
+		}
+		config = map[string]interface{}{
+			"type":           "csvfile",
+			"path":           LookupTableFile(a.CSV.ConfigMap.Name, a.CSV.ConfigMap.Key),
+			"separator":      valueOrDefault(a.CSV.Separator, ","),
+			"quotechar":      valueOrDefault(a.CSV.QuoteChar, `"`),
+			"key_column":     a.CSV.KeyColumn,
+			"value_column":   a.CSV.ValueColumn,
+			"check_interval": intOrDefault(a.CSV.CheckIntervalSeconds, lookupInterval),
+		}
+	case "dsvhttp":
+		if a.DSVHTTP == nil {
+			return nil, fmt.Errorf("dsvHttp is required for lookup data adapter %s", a.Name)
+		}
+		valueColumn := 1
+		if a.DSVHTTP.ValueColumn != nil {
+			valueColumn = *a.DSVHTTP.ValueColumn
+		}
+		config = map[string]interface{}{
+			"type":                "dsvhttp",
+			"url":                 a.DSVHTTP.URL,
+			"line_separator":      valueOrDefault(a.DSVHTTP.LineSeparator, "\n"),
+			"value_separator":     valueOrDefault(a.DSVHTTP.Separator, ","),
+			"quotechar":           valueOrDefault(a.DSVHTTP.QuoteChar, `"`),
+			"ignorechar":          valueOrDefault(a.DSVHTTP.IgnoreChar, "#"),
+			"key_column":          strconv.Itoa(a.DSVHTTP.KeyColumn),
+			"value_column":        strconv.Itoa(valueColumn),
+			"refresh_interval":    intOrDefault(a.DSVHTTP.RefreshIntervalSeconds, lookupInterval),
+			"check_presence_only": false,
+		}
+	case "inmemory":
+		config = map[string]interface{}{
+			"type":           "csvfile",
+			"path":           LookupTableFile(util.GraylogLookupTablesConfigMap, a.Name+".csv"),
+			"separator":      ",",
+			"quotechar":      `"`,
+			"key_column":     lookupKeyColumn,
+			"value_column":   lookupValueColumn,
+			"check_interval": lookupInterval,
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %s of lookup data adapter %s", a.Type, a.Name)
+	}
+	config["case_insensitive_lookup"] = a.CaseInsensitive
+	return &graylogClient.LookupDataAdapter{
+		Name:        a.Name,
+		Title:       valueOrDefault(a.Title, a.Name),
+		Description: a.Description,
+		Config:      config,
+	}, nil
+}
+
+func lookupCache(c loggingService.GraylogLookupCache) *graylogClient.LookupCache {
+	config := map[string]interface{}{"type": "none"}
+	if c.Type != "none" {
+		config = map[string]interface{}{
+			"type":                     "guava_cache",
+			"max_size":                 intOrDefault(c.MaxSize, 1000),
+			"expire_after_access":      intOrDefault(c.ExpireAfterAccessSeconds, lookupInterval),
+			"expire_after_access_unit": "SECONDS",
+			"expire_after_write":       c.ExpireAfterWriteSeconds,
+		}
+		if c.ExpireAfterWriteSeconds != 0 {
+			config["expire_after_write_unit"] = "SECONDS"
+		}
+	}
+	return &graylogClient.LookupCache{
+		Name:        c.Name,
+		Title:       valueOrDefault(c.Title, c.Name),
+		Description: c.Description,
+		Config:      config,
+	}
+}
+
+// lookupTable returns the lookup table with the ids of its data adapter and cache found by their names
+func (connector *GraylogConnector) lookupTable(t loggingService.GraylogLookupTable) (*graylogClient.LookupTable, error) {
+	adapter, err := connector.Client.GetLookupDataAdapter(connector.context(), t.DataAdapter)
+	if err != nil {
+		return nil, fmt.Errorf("can't find data adapter %s of lookup table %s: %w", t.DataAdapter, t.Name, err)
+	}
+	cache, err := connector.Client.GetLookupCache(connector.context(), t.Cache)
+	if err != nil {
+		return nil, fmt.Errorf("can't find cache %s of lookup table %s: %w", t.Cache, t.Name, err)
+	}
+	table := &graylogClient.LookupTable{
+		Name:                   t.Name,
+		Title:                  valueOrDefault(t.Title, t.Name),
+		Description:            t.Description,
+		DataAdapterId:          adapter.Id,
+		CacheId:                cache.Id,
+		DefaultSingleValue:     t.DefaultValue,
+		DefaultSingleValueType: "NULL",
+		DefaultMultiValueType:  "NULL",
+	}
+	if t.DefaultValue != "" {
+		table.DefaultSingleValueType = "STRING"
+	}
+	return table, nil
+}
+
+// DeleteLookupTables deletes the lookup tables created by the operator and then their caches and data adapters,
+// because Graylog doesn't delete the data adapters and the caches used by the tables
+func (connector *GraylogConnector) DeleteLookupTables(cr *loggingService.LoggingService) error {
+	// The objects may be created before their names are recorded in the status
+	if lookupTables := cr.Spec.Graylog.LookupTables; lookupTables != nil {
+		objects := connector.externalObjects()
+		for _, t := range lookupTables.Tables {
+			trackObject(&objects.LookupTables, t.Name)
+		}
+		for _, c := range lookupTables.Caches {
+			trackObject(&objects.LookupCaches, c.Name)
+		}
+		for _, a := range lookupTables.DataAdapters {
+			trackObject(&objects.LookupDataAdapters, a.Name)
+		}
+	}
+	return connector.deleteRemovedLookupTables(nil, nil, nil)
+}
+
+// deleteRemovedLookupTables deletes the lookup tables, the caches and the data adapters created by the operator
+// except the ones with the names from the custom resource. The tables are deleted first, because they refer
+// to the caches and the data adapters
+func (connector *GraylogConnector) deleteRemovedLookupTables(tableNames []string, cacheNames []string, adapterNames []string) error {
+	objects := connector.externalObjects()
+	if err := deleteRemovedObjects(&objects.LookupTables, tableNames, func(name string) error {
+		return connector.deleteLookupObject("lookup table", name, connector.Client.DeleteLookupTable)
+	}); err != nil {
+		return err
+	}
+	if err := deleteRemovedObjects(&objects.LookupCaches, cacheNames, func(name string) error {
+		return connector.deleteLookupObject("lookup cache", name, connector.Client.DeleteLookupCache)
+	}); err != nil {
+		return err
+	}
+	return deleteRemovedObjects(&objects.LookupDataAdapters, adapterNames, func(name string) error {
+		return connector.deleteLookupObject("lookup data adapter", name, connector.Client.DeleteLookupDataAdapter)
+	})
+}
+
+// deleteLookupObject deletes the object by the name, the object which is not found is skipped
+func (connector *GraylogConnector) deleteLookupObject(objectType string, name string, deleteByName func(ctx context.Context, name string) error) error {
+	if err := deleteByName(connector.context(), name); err != nil {
+		if graylogClient.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("can't delete %s %s: %w", objectType, name, err)
+	}
+	connector.Log.Info(fmt.Sprintf("Graylog %s %s deleted", objectType, name))
+	util.IncGraylogObjectChanges(objectType, "deleted")
+	return nil
+}
+
+func intOrDefault(value int, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,1455 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	onlyCreatePolicy  = "only-create"
+	forceUpdatePolicy = "force-update"
+	skipPolicy        = "skip"
+
+	testNamespace = "logging"
+	testInputPort = 12201
+
+	testContentPackId  = "7d5c3b1a-2b6f-4f3e-9b0a-0c1d2e3f4a5b"
+	testInstallationId = "64f000000000000000000001"
+)
+
+// contentDeployPolicies are the values of contentDeployPolicy by the names of the policies.
+// Any value other than only-create and force-update skips the existing objects
+var contentDeployPolicies = map[string]string{
+	onlyCreatePolicy:  onlyCreatePolicy,
+	forceUpdatePolicy: forceUpdatePolicy,
+	skipPolicy:        "",
+}
+
+// manageResult is the expected result of the Manage function with the content deploy policy
+type manageResult struct {
+	// graylog and openSearch are the numbers of the requests which change objects by the methods and the paths.
+	// They are not checked if the error is expected
+	graylog    map[string]int
+	openSearch map[string]int
+	// titles are the titles of the objects of the Graylog collections after the run, other collections are not checked
+	titles  map[string][]string
+	isError bool
+}
+
+// anyPolicy is the result of the Manage function which doesn't depend on the content deploy policy
+func anyPolicy(result manageResult) map[string]manageResult {
+	return map[string]manageResult{onlyCreatePolicy: result, forceUpdatePolicy: result, skipPolicy: result}
+}
+
+var manageTests = []struct {
+	description string
+	spec        loggingService.Graylog
+	// files are created in the data directory by their relative paths
+	files map[string]string
+	// seed adds the existing objects to Graylog and can refer to them in the spec
+	seed func(graylog *fakeGraylog, spec *loggingService.Graylog)
+	// openSearch are the existing objects in OpenSearch by their paths
+	openSearch map[string]map[string]interface{}
+	manage     func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error
+	results    map[string]manageResult
+}{
+	{
+		description: "ManageStreams creates missing streams and resumes them",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			seedIndexSets(graylog, util.GraylogAuditIndexSet, util.GraylogKubernetesEventsIndexSet)
+			graylog.add("streams", map[string]interface{}{"title": util.GraylogAuditStream})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageStreams(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {
+				graylog: map[string]int{"POST streams": 2, "POST streams/{id}/resume": 3},
+				titles:  map[string][]string{"streams": {"All events", util.GraylogAllMessagesStream, "All system events", util.GraylogAuditStream, util.GraylogKubernetesEventsStream, util.GraylogSystemStream}},
+			},
+			forceUpdatePolicy: {
+				graylog: map[string]int{"POST streams": 2, "PUT streams/{id}": 1, "POST streams/{id}/resume": 3},
+				titles:  map[string][]string{"streams": {"All events", util.GraylogAllMessagesStream, "All system events", util.GraylogAuditStream, util.GraylogKubernetesEventsStream, util.GraylogSystemStream}},
+			},
+			// Streams which are not created can't be resumed
+			skipPolicy: {isError: true},
+		},
+	},
+	{
+		description: "ManageStreams replaces rules of existing custom streams",
+		spec: loggingService.Graylog{
+			Streams: []loggingService.Stream{{
+				Name:     "Payments",
+				Install:  true,
+				IndexSet: util.GraylogDefaultIndexSet,
+				Rules:    []loggingService.StreamRule{{Field: "namespace", Type: "exact", Value: "payments"}},
+			}},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			seedIndexSets(graylog, util.GraylogAuditIndexSet)
+			graylog.add("streams", map[string]interface{}{"title": util.GraylogAuditStream}, map[string]interface{}{"title": util.GraylogSystemStream})
+			id := graylog.add("streams", map[string]interface{}{"title": "Payments"})
+			graylog.add("streams/"+id+"/rules", map[string]interface{}{"field": "namespace", "type": 1, "value": "billing"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageStreams(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {graylog: map[string]int{"POST streams/{id}/resume": 3}},
+			forceUpdatePolicy: {graylog: map[string]int{
+				"PUT streams/{id}": 3, "DELETE streams/{id}/rules/{id}": 1, "POST streams/{id}/rules": 1, "POST streams/{id}/resume": 3,
+			}},
+			skipPolicy: {graylog: map[string]int{"POST streams/{id}/resume": 3}},
+		},
+	},
+	{
+		description: "ManageIndexSets creates the index set of the custom stream with quotes in its name",
+		spec: loggingService.Graylog{
+			Streams: []loggingService.Stream{{Name: `Payments "EU"`, Install: true, MaxNumberOfIndices: 10}},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			seedIndexSets(graylog, util.GraylogAuditIndexSet)
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageIndexSets(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {
+				graylog: map[string]int{"POST system/indices/index_sets": 1, "PUT system/indices/index_sets/{id}": 1},
+				titles:  map[string][]string{"system/indices/index_sets": {util.GraylogAuditIndexSet, util.GraylogDefaultIndexSet, `Payments "EU" index set`}},
+			},
+			forceUpdatePolicy: {
+				graylog: map[string]int{"POST system/indices/index_sets": 1, "PUT system/indices/index_sets/{id}": 2},
+				titles:  map[string][]string{"system/indices/index_sets": {util.GraylogAuditIndexSet, util.GraylogDefaultIndexSet, `Payments "EU" index set`}},
+			},
+			skipPolicy: {
+				graylog: map[string]int{"POST system/indices/index_sets": 1, "PUT system/indices/index_sets/{id}": 1},
+				titles:  map[string][]string{"system/indices/index_sets": {util.GraylogAuditIndexSet, util.GraylogDefaultIndexSet, `Payments "EU" index set`}},
+			},
+		},
+	},
+	{
+		description: "ManageIndexSets creates missing index sets and updates the default one",
+		spec:        loggingService.Graylog{Streams: GetDefaultStreams()},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			seedIndexSets(graylog, util.GraylogAuditIndexSet)
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageIndexSets(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy:  {graylog: map[string]int{"POST system/indices/index_sets": 1, "PUT system/indices/index_sets/{id}": 1}},
+			forceUpdatePolicy: {graylog: map[string]int{"POST system/indices/index_sets": 1, "PUT system/indices/index_sets/{id}": 2}},
+			skipPolicy:        {graylog: map[string]int{"POST system/indices/index_sets": 1, "PUT system/indices/index_sets/{id}": 1}},
+		},
+	},
+	{
+		description: "ManageFieldTypes sets types of the fields of the streams",
+		spec: loggingService.Graylog{
+			Streams: []loggingService.Stream{{
+				Name:       util.GraylogAuditStream,
+				Install:    true,
+				FieldTypes: map[string]string{"user": "string", "code": "long"},
+			}},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			seedIndexSets(graylog, util.GraylogAuditIndexSet)
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			indexSets, err := connector.GetAllIndexSets()
+			if err != nil {
+				return err
+			}
+			return connector.ManageFieldTypes(indexSets, cr)
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{"PUT system/indices/mappings": 2}}),
+	},
+	{
+		description: "ManageInputs creates the missing input",
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageInputs(cr)
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{"POST system/inputs": 1}}),
+	},
+	{
+		description: "ManageInputs updates the existing input",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/inputs", map[string]interface{}{"title": "input-12201"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageInputs(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy:  {},
+			forceUpdatePolicy: {graylog: map[string]int{"PUT system/inputs/{id}": 1}},
+			skipPolicy:        {},
+		},
+	},
+	{
+		description: "ManageExtractors creates extractors and deletes the obsolete one",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			id := graylog.add("system/inputs", map[string]interface{}{"title": "input-12201"})
+			graylog.add("system/inputs/"+id+"/extractors",
+				map[string]interface{}{"title": util.GraylogKubernetesExtractorName},
+				map[string]interface{}{"title": "os_extractor"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageExtractors(cr, connector.Capabilities)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {graylog: map[string]int{"POST system/inputs/{id}/extractors": 4, "PUT system/messageprocessors/config": 1}},
+			forceUpdatePolicy: {graylog: map[string]int{
+				"POST system/inputs/{id}/extractors": 4, "PUT system/inputs/{id}/extractors/{id}": 1,
+				"DELETE system/inputs/{id}/extractors/{id}": 1, "PUT system/messageprocessors/config": 1,
+			}},
+			skipPolicy: {graylog: map[string]int{"PUT system/messageprocessors/config": 1}},
+		},
+	},
+	{
+		description: "ManageGrokPatterns creates missing patterns or updates existing ones",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/grok", map[string]interface{}{"name": "TZ", "pattern": "UTC"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageGrokPatterns(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy:  {graylog: map[string]int{"POST system/grok": 80}},
+			forceUpdatePolicy: {graylog: map[string]int{"PUT system/grok/{id}": 1}},
+			skipPolicy:        {},
+		},
+	},
+	{
+		description: "ManageProcessingRules creates missing rules",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("streams", map[string]interface{}{"title": util.GraylogAuditStream}, map[string]interface{}{"title": util.GraylogSystemStream})
+			graylog.add("system/pipelines/rule", map[string]interface{}{"title": util.GraylogRemoveKubernetesRule})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageProcessingRules(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy:  {graylog: map[string]int{"POST system/pipelines/rule": 5}},
+			forceUpdatePolicy: {graylog: map[string]int{"POST system/pipelines/rule": 5, "PUT system/pipelines/rule/{id}": 1}},
+			skipPolicy:        {},
+		},
+	},
+	{
+		description: "ManagePipelines updates the pipeline and connects it to the default stream",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/pipelines/pipeline", map[string]interface{}{"title": "Logs routing"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManagePipelines(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy:  {graylog: map[string]int{"POST system/pipelines/connections/to_stream": 1}},
+			forceUpdatePolicy: {graylog: map[string]int{"PUT system/pipelines/pipeline/{id}": 1, "POST system/pipelines/connections/to_stream": 1}},
+			skipPolicy:        {graylog: map[string]int{"POST system/pipelines/connections/to_stream": 1}},
+		},
+	},
+	{
+		description: "ManageSavedSearches uploads missing saved searches and views",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("views", map[string]interface{}{"title": "Cloud events"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageSavedSearches(context.Background(), cr, clientSet)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy:  {graylog: map[string]int{"POST views/search": 1, "POST views": 1}},
+			forceUpdatePolicy: {graylog: map[string]int{"POST views/search": 2, "POST views": 1}},
+			skipPolicy:        {graylog: map[string]int{"POST views": 1}},
+		},
+	},
+	{
+		description: "ManageCustomSavedSearches replaces the existing view",
+		files: map[string]string{
+			"saved-searches/errors-search.json": `{"id": "65a000000000000000000001", "queries": []}`,
+			"saved-searches/errors-view.json":   `{"id": "65a000000000000000000002", "title": "Errors", "search_id": "65a000000000000000000001"}`,
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("views", map[string]interface{}{"id": "65a000000000000000000002", "title": "Errors"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageCustomSavedSearches(context.Background(), cr, clientSet)
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{"POST views/search": 1, "DELETE views/{id}": 1, "POST views": 1}}),
+	},
+	{
+		description: "ManageCustomSavedSearches uploads the inline saved searches",
+		spec: loggingService.Graylog{
+			SavedSearches: []loggingService.SavedSearchSource{{
+				ContentSource: loggingService.ContentSource{Inline: map[string]string{
+					"errors-view.json":   `{"id": "65a000000000000000000002", "title": "Errors", "search_id": "65a000000000000000000001"}`,
+					"errors-search.json": `{"id": "65a000000000000000000001", "queries": []}`,
+				}},
+			}},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageCustomSavedSearches(context.Background(), cr, clientSet)
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{"POST views/search": 1, "POST views": 1},
+			titles:  map[string][]string{"views": {"Errors"}},
+		}),
+	},
+	{
+		description: "ManageContentPacks uploads and installs content packs from the archive",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.files["/files/content-packs.zip"] = contentPacksArchive(graylog.t, 1)
+			spec.ContentPackPaths = graylog.server.URL + "/files/content-packs.zip"
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			contentPacks, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			return checkContentPacks(contentPacks, loggingService.ContentPackStatus{Source: cr.Spec.Graylog.ContentPackPaths, Id: testContentPackId, Revision: 1})
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{"POST system/content_packs": 1, "POST system/content_packs/" + testContentPackId + "/installations": 1},
+			titles:  map[string][]string{"system/content_packs": {"Test pack"}},
+		}),
+	},
+	{
+		description: "ManageContentPacks installs content packs from the ConfigMap",
+		spec: loggingService.Graylog{
+			ContentPacks: []*loggingService.ContentPackPathHTTPConfig{{
+				ContentSource: loggingService.ContentSource{ConfigMap: &loggingService.ContentObjectReference{Name: "content-packs"}},
+			}},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "content-packs", Namespace: testNamespace},
+				Data:       map[string]string{"test-pack.json": fmt.Sprintf(`{"id": "%s", "rev": 1, "name": "Test pack", "entities": []}`, testContentPackId)},
+			}
+			if _, err := clientSet.CoreV1().ConfigMaps(testNamespace).Create(context.Background(), configMap, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			contentPacks, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			return checkContentPacks(contentPacks, loggingService.ContentPackStatus{Source: "configMap/content-packs", Id: testContentPackId, Revision: 1})
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{"POST system/content_packs": 1, "POST system/content_packs/" + testContentPackId + "/installations": 1},
+			titles:  map[string][]string{"system/content_packs": {"Test pack"}},
+		}),
+	},
+	{
+		description: "ManageContentPacks installs content packs from the archive in the OCI artifact",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			reference := seedOCIArtifact(graylog, "logging/content-packs:1.0.0", "content-packs.zip", contentPacksArchive(graylog.t, 1))
+			spec.ContentPacks = []*loggingService.ContentPackPathHTTPConfig{{ContentSource: loggingService.ContentSource{OCI: reference}}}
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			_, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			return err
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{"POST system/content_packs": 1, "POST system/content_packs/" + testContentPackId + "/installations": 1},
+			titles:  map[string][]string{"system/content_packs": {"Test pack"}},
+		}),
+	},
+	{
+		description: "ManageContentPacks reinstalls the revision of the content pack if the OCI artifact is changed",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			reference := seedOCIArtifact(graylog, "logging/content-packs:1.0.0", "content-packs.zip", contentPacksArchive(graylog.t, 1))
+			spec.ContentPacks = []*loggingService.ContentPackPathHTTPConfig{{ContentSource: loggingService.ContentSource{OCI: reference}}}
+			graylog.add("system/content_packs", map[string]interface{}{"id": testContentPackId, "rev": 1, "name": "Test pack"})
+			graylog.add("system/content_packs/"+testContentPackId+"/installations", map[string]interface{}{"_id": testInstallationId, "content_pack_revision": 1})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			source := "oci://" + cr.Spec.Graylog.ContentPacks[0].OCI
+			cr.Status.ContentPacks = []loggingService.ContentPackStatus{{Source: source, Id: testContentPackId, Revision: 1, InstallationId: testInstallationId, Digest: "sha256:previous"}}
+			contentPacks, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			if len(contentPacks) != 1 || contentPacks[0].InstallationId == testInstallationId || !strings.HasPrefix(contentPacks[0].Digest, "sha256:") || contentPacks[0].Digest == "sha256:previous" {
+				return fmt.Errorf("expected the new installation with the new digest, got %v", contentPacks)
+			}
+			// The next run with the same digest doesn't reinstall the content pack
+			cr.Status.ContentPacks = contentPacks
+			_, err = connector.ManageContentPacks(context.Background(), cr, clientSet)
+			return err
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{
+				"DELETE system/content_packs/" + testContentPackId + "/installations/{id}": 1, "DELETE system/content_packs/" + testContentPackId + "/1": 1,
+				"POST system/content_packs": 1, "POST system/content_packs/" + testContentPackId + "/installations": 1,
+			},
+			titles: map[string][]string{"system/content_packs": {"Test pack"}},
+		}),
+	},
+	{
+		description: "ManageContentPacks verifies the checksum of the content pack",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			archive := contentPacksArchive(graylog.t, 1)
+			graylog.files["/files/content-packs.zip"] = archive
+			spec.ContentPacks = []*loggingService.ContentPackPathHTTPConfig{{
+				URL:      graylog.server.URL + "/files/content-packs.zip",
+				Checksum: fmt.Sprintf("sha256:%x", sha256.Sum256(archive)),
+			}}
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			_, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			return err
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{
+			"POST system/content_packs": 1, "POST system/content_packs/" + testContentPackId + "/installations": 1,
+		}}),
+	},
+	{
+		description: "ManageContentPacks doesn't install the content pack with the wrong checksum",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.files["/files/content-packs.zip"] = contentPacksArchive(graylog.t, 1)
+			spec.ContentPacks = []*loggingService.ContentPackPathHTTPConfig{{
+				URL:      graylog.server.URL + "/files/content-packs.zip",
+				Checksum: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("another archive"))),
+			}}
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			_, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			return err
+		},
+		results: anyPolicy(manageResult{isError: true}),
+	},
+	{
+		description: "ManageContentPacks skips the installed revision of the content pack",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.files["/files/content-packs.zip"] = contentPacksArchive(graylog.t, 1)
+			spec.ContentPackPaths = graylog.server.URL + "/files/content-packs.zip"
+			graylog.add("system/content_packs", map[string]interface{}{"id": testContentPackId, "rev": 1, "name": "Test pack"})
+			graylog.add("system/content_packs/"+testContentPackId+"/installations", map[string]interface{}{"_id": testInstallationId})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			installed := loggingService.ContentPackStatus{Source: cr.Spec.Graylog.ContentPackPaths, Id: testContentPackId, Revision: 1, InstallationId: testInstallationId}
+			cr.Status.ContentPacks = []loggingService.ContentPackStatus{installed}
+			contentPacks, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			return checkContentPacks(contentPacks, installed)
+		},
+		results: anyPolicy(manageResult{}),
+	},
+	{
+		description: "ManageContentPacks adopts the existing installation of the revision without status",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.files["/files/content-packs.zip"] = contentPacksArchive(graylog.t, 1)
+			spec.ContentPackPaths = graylog.server.URL + "/files/content-packs.zip"
+			graylog.add("system/content_packs", map[string]interface{}{"id": testContentPackId, "rev": 1, "name": "Test pack"})
+			graylog.add("system/content_packs/"+testContentPackId+"/installations", map[string]interface{}{"_id": testInstallationId, "content_pack_revision": 1})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			contentPacks, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			return checkContentPacks(contentPacks, loggingService.ContentPackStatus{Source: cr.Spec.Graylog.ContentPackPaths, Id: testContentPackId, Revision: 1, InstallationId: testInstallationId})
+		},
+		results: anyPolicy(manageResult{}),
+	},
+	{
+		description: "ManageContentPacks installs the new revision of the content pack and uninstalls the previous one",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.files["/files/content-packs-2.zip"] = contentPacksArchive(graylog.t, 2)
+			spec.ContentPackPaths = graylog.server.URL + "/files/content-packs-2.zip"
+			graylog.add("system/content_packs", map[string]interface{}{"id": testContentPackId, "rev": 1, "name": "Test pack"})
+			graylog.add("system/content_packs/"+testContentPackId+"/installations", map[string]interface{}{"_id": testInstallationId})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			cr.Status.ContentPacks = []loggingService.ContentPackStatus{{Source: "http://nexus/content-packs-1.zip", Id: testContentPackId, Revision: 1, InstallationId: testInstallationId}}
+			contentPacks, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			return checkContentPacks(contentPacks, loggingService.ContentPackStatus{Source: cr.Spec.Graylog.ContentPackPaths, Id: testContentPackId, Revision: 2})
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{
+				"POST system/content_packs":                                                1,
+				"POST system/content_packs/" + testContentPackId + "/installations":        1,
+				"DELETE system/content_packs/" + testContentPackId + "/installations/{id}": 1,
+				"DELETE system/content_packs/" + testContentPackId + "/1":                  1,
+			},
+			titles: map[string][]string{"system/content_packs": {"Test pack"}},
+		}),
+	},
+	{
+		description: "ManageContentPacks uninstalls the content pack removed from the spec",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/content_packs", map[string]interface{}{"id": testContentPackId, "rev": 1, "name": "Test pack"})
+			graylog.add("system/content_packs/"+testContentPackId+"/installations", map[string]interface{}{"_id": testInstallationId})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			cr.Status.ContentPacks = []loggingService.ContentPackStatus{{Source: "http://nexus/content-packs.zip", Id: testContentPackId, Revision: 1, InstallationId: testInstallationId}}
+			contentPacks, err := connector.ManageContentPacks(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			return checkContentPacks(contentPacks)
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{
+			"DELETE system/content_packs/" + testContentPackId + "/installations/{id}": 1,
+			"DELETE system/content_packs/" + testContentPackId:                         1,
+		}}),
+	},
+	{
+		description: "ManageDashboards reinstalls the existing default content pack",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/content_packs", map[string]interface{}{"id": oobContentPackId, "rev": 1, "name": "Dashboards"})
+			graylog.add("system/content_packs/"+oobContentPackId+"/installations", map[string]interface{}{"content_pack_id": oobContentPackId})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageDashboards(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {},
+			forceUpdatePolicy: {graylog: map[string]int{
+				"DELETE system/content_packs/" + oobContentPackId + "/installations/{id}": 1,
+				"DELETE system/content_packs/" + oobContentPackId:                         1,
+				"POST system/content_packs":                                               1,
+				"POST system/content_packs/" + oobContentPackId + "/installations":        1,
+			}},
+			skipPolicy: {},
+		},
+	},
+	{
+		description: "ManageUserAccounts creates missing roles and users",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("streams", map[string]interface{}{"title": util.GraylogAuditStream}, map[string]interface{}{"title": util.GraylogSystemStream})
+			graylog.add("dashboards", map[string]interface{}{"title": "Sources by Service"})
+			graylog.add("roles", map[string]interface{}{"name": "operator"})
+			graylog.add("users", map[string]interface{}{"username": "operator"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageUserAccounts(cr, connector.Capabilities)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {
+				graylog: map[string]int{"POST roles": 1, "POST users": 2},
+				titles:  map[string][]string{"roles": {"Admin", "AuditViewer", readerRole, "operator"}, "users": {"auditViewer", "graylog_api_th_user", "operator"}},
+			},
+			forceUpdatePolicy: {
+				graylog: map[string]int{"POST roles": 1, "PUT roles/operator": 1, "POST users": 2, "PUT users/{id}": 1},
+				titles:  map[string][]string{"roles": {"Admin", "AuditViewer", readerRole, "operator"}, "users": {"auditViewer", "graylog_api_th_user", "operator"}},
+			},
+			skipPolicy: {graylog: map[string]int{"POST roles": 1, "POST users": 2}},
+		},
+	},
+	{
+		description: "ChangeUserPassword changes passwords of existing users and ConfirmLogin checks the login",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("users", map[string]interface{}{"username": "operator"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			for _, user := range []string{"operator", "auditViewer"} {
+				if _, err := connector.ChangeUserPassword(user, "rotated"); err != nil {
+					return err
+				}
+			}
+			return connector.ConfirmLogin("operator", "rotated")
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{"PUT users/{id}/password": 1}}),
+	},
+	{
+		description: "EnsureServiceUser creates the service user and CreateAccessToken replaces its access token",
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			userId, err := connector.EnsureServiceUser()
+			if err != nil {
+				return err
+			}
+			revoked, err := connector.CreateAccessToken(userId)
+			if err != nil {
+				return err
+			}
+			token, err := connector.CreateAccessToken(userId)
+			if err != nil {
+				return err
+			}
+			if valid, err := connector.IsAccessTokenValid(revoked); err != nil || valid {
+				return fmt.Errorf("expected the previous access token to be revoked, got valid: %v, error: %v", valid, err)
+			}
+			connector.UseAccessToken(token)
+			// The existing user and role are not changed
+			if _, err = connector.EnsureServiceUser(); err != nil {
+				return err
+			}
+			return connector.ConfirmLogin(token, accessTokenPassword)
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{
+			"POST roles": 1,
+			"POST users": 1,
+			"POST users/{id}/tokens/logging-operator": 2,
+			"DELETE users/{id}/tokens/{id}":           1,
+		}}),
+	},
+	{
+		description: "ManageCustomUserAccounts creates roles and users from the spec",
+		spec: loggingService.Graylog{
+			Roles: []loggingService.GraylogRole{{Name: "developers", Streams: []string{util.GraylogSystemStream}}},
+			Users: []loggingService.GraylogUser{{
+				Username:       "alice",
+				PasswordSecret: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "graylog-users"}, Key: "alice"},
+			}},
+			NamespaceTeams: &loggingService.GraylogNamespaceTeams{Namespaces: []string{"payments"}},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("streams", map[string]interface{}{"title": util.GraylogSystemStream}, map[string]interface{}{"title": "payments namespace logs"})
+			graylog.add("roles", map[string]interface{}{"name": "developers"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageCustomUserAccounts(context.Background(), cr, clientSet, connector.Capabilities)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy:  {graylog: map[string]int{"POST roles": 1, "POST users": 1}},
+			forceUpdatePolicy: {graylog: map[string]int{"POST roles": 1, "PUT roles/developers": 1, "POST users": 1}},
+			skipPolicy:        {graylog: map[string]int{"POST roles": 1, "POST users": 1}},
+		},
+	},
+	{
+		description: "ManageCustomUserAccounts updates changed users, changes their passwords and deletes removed users and roles",
+		spec: loggingService.Graylog{
+			Roles: []loggingService.GraylogRole{{Name: "developers", Streams: []string{util.GraylogSystemStream}}},
+			Users: []loggingService.GraylogUser{{
+				Username:       "alice",
+				Email:          "alice@example.com",
+				PasswordSecret: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "graylog-users"}, Key: "alice"},
+			}},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("streams", map[string]interface{}{"title": util.GraylogSystemStream})
+			graylog.add("roles", map[string]interface{}{"name": "developers"}, map[string]interface{}{"name": "testers"}, map[string]interface{}{"name": "manual"})
+			graylog.add("users",
+				map[string]interface{}{"username": "alice", "email": "alice@localhost", "password": "previous-password"},
+				map[string]interface{}{"username": "bob"},
+				map[string]interface{}{"username": "manual"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			connector.ExternalObjects = &loggingService.ExternalObjectsStatus{
+				Roles: []string{"developers", "testers"},
+				Users: []string{"alice", "bob"},
+			}
+			// The second reconciliation doesn't change the updated user
+			for i := 0; i < 2; i++ {
+				if err := connector.ManageCustomUserAccounts(context.Background(), cr, clientSet, connector.Capabilities); err != nil {
+					return err
+				}
+			}
+			objects := connector.ExternalObjects
+			if !slices.Equal(objects.Roles, []string{"developers"}) || !slices.Equal(objects.Users, []string{"alice"}) {
+				return fmt.Errorf("unexpected objects in the status %+v", objects)
+			}
+			return connector.ConfirmLogin("alice", "alice-password")
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {
+				graylog: map[string]int{"PUT users/{id}": 1, "PUT users/{id}/password": 1, "DELETE users/{id}": 1, "DELETE roles/testers": 1},
+				titles:  map[string][]string{"roles": {"Admin", readerRole, "developers", "manual"}, "users": {"alice", "manual"}},
+			},
+			forceUpdatePolicy: {
+				graylog: map[string]int{
+					"PUT roles/developers": 2, "PUT users/{id}": 1, "PUT users/{id}/password": 1,
+					"DELETE users/{id}": 1, "DELETE roles/testers": 1,
+				},
+				titles: map[string][]string{"roles": {"Admin", readerRole, "developers", "manual"}, "users": {"alice", "manual"}},
+			},
+			skipPolicy: {
+				graylog: map[string]int{"PUT users/{id}": 1, "PUT users/{id}/password": 1, "DELETE users/{id}": 1, "DELETE roles/testers": 1},
+				titles:  map[string][]string{"roles": {"Admin", readerRole, "developers", "manual"}, "users": {"alice", "manual"}},
+			},
+		},
+	},
+	{
+		description: "ManageAlerts creates notifications and event definitions",
+		spec: loggingService.Graylog{
+			Alerts: &loggingService.GraylogAlerts{
+				InstallDefault:       true,
+				DefaultNotifications: []string{"On-call"},
+				Notifications: []loggingService.GraylogNotification{
+					{Title: "On-call", Type: "http", URLSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "graylog-users"}, Key: "webhook"}},
+					{Title: "Team chat", Type: "slack", URL: "https://hooks.slack.com/services/test", Channel: "#logging"},
+				},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("streams", map[string]interface{}{"title": util.GraylogAuditStream})
+			graylog.add("events/notifications", map[string]interface{}{"title": "On-call"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageAlerts(context.Background(), cr, clientSet)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy:  {graylog: map[string]int{"POST events/notifications": 1, "POST events/definitions": 2}},
+			forceUpdatePolicy: {graylog: map[string]int{"POST events/notifications": 1, "PUT events/notifications/{id}": 1, "POST events/definitions": 2}},
+			skipPolicy:        {graylog: map[string]int{"POST events/notifications": 1, "POST events/definitions": 2}},
+		},
+	},
+	{
+		description: "ManageAlerts skips the default event definitions without the Audit logs stream",
+		spec: loggingService.Graylog{
+			Alerts: &loggingService.GraylogAlerts{
+				InstallDefault: true,
+				EventDefinitions: []loggingService.GraylogEventDefinition{
+					{Title: "Errors", Streams: []string{util.GraylogAllMessagesStream}, Query: "level:3"},
+				},
+			},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageAlerts(context.Background(), cr, clientSet)
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{"POST events/definitions": 1},
+			titles:  map[string][]string{"events/definitions": {"Errors"}},
+		}),
+	},
+	{
+		description: "ManageAlerts fails if the stream of the event definition is not found",
+		spec: loggingService.Graylog{
+			Alerts: &loggingService.GraylogAlerts{
+				EventDefinitions: []loggingService.GraylogEventDefinition{{Title: "Errors", Streams: []string{"Unknown"}}},
+			},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageAlerts(context.Background(), cr, clientSet)
+		},
+		results: anyPolicy(manageResult{isError: true}),
+	},
+	{
+		description: "ManageAlerts deletes the event definitions and notifications removed from the custom resource",
+		spec: loggingService.Graylog{
+			Alerts: &loggingService.GraylogAlerts{
+				Notifications: []loggingService.GraylogNotification{{Title: "On-call", Type: "http", URL: "https://alerts.example.com"}},
+				EventDefinitions: []loggingService.GraylogEventDefinition{
+					{Title: "Errors", Streams: []string{util.GraylogAllMessagesStream}, Query: "level:3", Notifications: []string{"On-call"}},
+				},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("events/notifications",
+				map[string]interface{}{"title": "On-call"},
+				map[string]interface{}{"title": "Removed"},
+				map[string]interface{}{"title": "Manual"})
+			graylog.add("events/definitions",
+				map[string]interface{}{"title": "Errors"},
+				map[string]interface{}{"title": "Removed"},
+				map[string]interface{}{"title": "Manual"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			connector.ExternalObjects = &loggingService.ExternalObjectsStatus{
+				EventDefinitions: []string{"Errors", "Removed"},
+				Notifications:    []string{"On-call", "Removed"},
+			}
+			if err := connector.ManageAlerts(context.Background(), cr, clientSet); err != nil {
+				return err
+			}
+			objects := connector.ExternalObjects
+			if !slices.Equal(objects.EventDefinitions, []string{"Errors"}) || !slices.Equal(objects.Notifications, []string{"On-call"}) {
+				return fmt.Errorf("unexpected objects in the status %+v", objects)
+			}
+			return nil
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {
+				graylog: map[string]int{"DELETE events/definitions/{id}": 1, "DELETE events/notifications/{id}": 1},
+				titles:  map[string][]string{"events/definitions": {"Errors", "Manual"}, "events/notifications": {"Manual", "On-call"}},
+			},
+			forceUpdatePolicy: {
+				graylog: map[string]int{
+					"PUT events/definitions/{id}": 1, "PUT events/notifications/{id}": 1,
+					"DELETE events/definitions/{id}": 1, "DELETE events/notifications/{id}": 1,
+				},
+				titles: map[string][]string{"events/definitions": {"Errors", "Manual"}, "events/notifications": {"Manual", "On-call"}},
+			},
+			skipPolicy: {
+				graylog: map[string]int{"DELETE events/definitions/{id}": 1, "DELETE events/notifications/{id}": 1},
+				titles:  map[string][]string{"events/definitions": {"Errors", "Manual"}, "events/notifications": {"Manual", "On-call"}},
+			},
+		},
+	},
+	{
+		description: "DeleteAlerts deletes the event definitions and notifications of the custom resource",
+		spec: loggingService.Graylog{
+			Alerts: &loggingService.GraylogAlerts{
+				InstallDefault:   true,
+				Notifications:    []loggingService.GraylogNotification{{Title: "On-call", Type: "http", URL: "https://alerts.example.com"}},
+				EventDefinitions: []loggingService.GraylogEventDefinition{{Title: "Errors"}},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("events/notifications", map[string]interface{}{"title": "On-call"}, map[string]interface{}{"title": "Manual"})
+			graylog.add("events/definitions",
+				map[string]interface{}{"title": "Audit: forbidden requests spike"},
+				map[string]interface{}{"title": "Errors"},
+				map[string]interface{}{"title": "Manual"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.DeleteAlerts(cr)
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{"DELETE events/definitions/{id}": 2, "DELETE events/notifications/{id}": 1},
+			titles:  map[string][]string{"events/definitions": {"Manual"}, "events/notifications": {"Manual"}},
+		}),
+	},
+	{
+		description: "ManageOutputs creates outputs and attaches them to the streams",
+		spec: loggingService.Graylog{
+			Outputs: []loggingService.GraylogOutput{
+				{
+					Title:         "SOC Graylog",
+					Type:          "gelf",
+					Configuration: runtime.RawExtension{Raw: []byte(`{"hostname": "soc.example.com", "port": 12201, "protocol": "TCP"}`)},
+					SecretRefs: map[string]corev1.SecretKeySelector{
+						"tls_trust_cert_chain": {LocalObjectReference: corev1.LocalObjectReference{Name: "graylog-users"}, Key: "webhook"},
+					},
+					Streams: []string{util.GraylogAuditStream},
+				},
+				{Title: "Debug", Type: "stdout", Streams: []string{util.GraylogAllMessagesStream}},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("streams", map[string]interface{}{"title": util.GraylogAuditStream})
+			id := graylog.add("system/outputs", map[string]interface{}{"title": "Debug", "type": "org.graylog2.outputs.LoggingOutput"})
+			graylog.add("streams/000000000000000000000003/outputs", map[string]interface{}{"id": id})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageOutputs(context.Background(), cr, clientSet)
+		},
+		results: map[string]manageResult{
+			// Streams of the existing output are synchronized with any policy
+			onlyCreatePolicy: {
+				graylog: map[string]int{"POST system/outputs": 1, "POST streams/{id}/outputs": 2, "DELETE streams/{id}/outputs/{id}": 1},
+				titles:  map[string][]string{"system/outputs": {"Debug", "SOC Graylog"}},
+			},
+			forceUpdatePolicy: {
+				graylog: map[string]int{"POST system/outputs": 1, "PUT system/outputs/{id}": 1, "POST streams/{id}/outputs": 2, "DELETE streams/{id}/outputs/{id}": 1},
+				titles:  map[string][]string{"system/outputs": {"Debug", "SOC Graylog"}},
+			},
+			skipPolicy: {
+				graylog: map[string]int{"POST system/outputs": 1, "POST streams/{id}/outputs": 2, "DELETE streams/{id}/outputs/{id}": 1},
+				titles:  map[string][]string{"system/outputs": {"Debug", "SOC Graylog"}},
+			},
+		},
+	},
+	{
+		description: "ManageOutputs fails if the stream of the output is not found",
+		spec: loggingService.Graylog{
+			Outputs: []loggingService.GraylogOutput{{Title: "SOC Graylog", Type: "gelf", Streams: []string{"Unknown"}}},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageOutputs(context.Background(), cr, clientSet)
+		},
+		results: anyPolicy(manageResult{isError: true}),
+	},
+	{
+		description: "ManageLookupTables creates data adapters, caches and lookup tables",
+		spec: loggingService.Graylog{
+			LookupTables: &loggingService.GraylogLookupTables{
+				DataAdapters: []loggingService.GraylogLookupDataAdapter{
+					{
+						Name: "service-owners",
+						Type: "csv",
+						CSV: &loggingService.GraylogLookupCSVAdapter{
+							ConfigMap:   corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "lookup-data"}, Key: "owners.csv"},
+							KeyColumn:   "namespace",
+							ValueColumn: "owner",
+						},
+					},
+					{Name: "ip-sites", Type: "dsvhttp", DSVHTTP: &loggingService.GraylogLookupDSVHTTPAdapter{URL: "http://sites.example.com/sites.csv"}},
+					{Name: "environments", Type: "inmemory", Entries: map[string]string{"prod-payments": "production"}},
+				},
+				Caches: []loggingService.GraylogLookupCache{{Name: "lookup-cache", Title: "Lookup cache"}},
+				Tables: []loggingService.GraylogLookupTable{
+					{Name: "service-owner", Title: "Service owner", DataAdapter: "service-owners", Cache: "lookup-cache", DefaultValue: "unknown"},
+					{Name: "site", Title: "Site", DataAdapter: "ip-sites", Cache: "lookup-cache"},
+				},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/lookup/caches", map[string]interface{}{"name": "lookup-cache", "title": "Lookup cache", "config": map[string]interface{}{"type": "none"}})
+			graylog.add("system/lookup/tables", map[string]interface{}{"name": "site", "title": "Site"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageLookupTables(cr)
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {
+				graylog: map[string]int{"POST system/lookup/adapters": 3, "POST system/lookup/tables": 1},
+				titles:  map[string][]string{"system/lookup/adapters": {"environments", "ip-sites", "service-owners"}, "system/lookup/tables": {"Service owner", "Site"}},
+			},
+			forceUpdatePolicy: {
+				graylog: map[string]int{"POST system/lookup/adapters": 3, "PUT system/lookup/caches/lookup-cache": 1, "POST system/lookup/tables": 1, "PUT system/lookup/tables/site": 1},
+				titles:  map[string][]string{"system/lookup/adapters": {"environments", "ip-sites", "service-owners"}, "system/lookup/tables": {"Service owner", "Site"}},
+			},
+			skipPolicy: {
+				graylog: map[string]int{"POST system/lookup/adapters": 3, "POST system/lookup/tables": 1},
+				titles:  map[string][]string{"system/lookup/adapters": {"environments", "ip-sites", "service-owners"}, "system/lookup/tables": {"Service owner", "Site"}},
+			},
+		},
+	},
+	{
+		description: "ManageLookupTables deletes lookup tables, caches and data adapters removed from the spec",
+		spec: loggingService.Graylog{
+			LookupTables: &loggingService.GraylogLookupTables{
+				DataAdapters: []loggingService.GraylogLookupDataAdapter{
+					{Name: "ip-sites", Type: "dsvhttp", DSVHTTP: &loggingService.GraylogLookupDSVHTTPAdapter{URL: "http://sites.example.com/sites.csv"}},
+				},
+				Caches: []loggingService.GraylogLookupCache{{Name: "lookup-cache"}},
+				Tables: []loggingService.GraylogLookupTable{{Name: "site", DataAdapter: "ip-sites", Cache: "lookup-cache"}},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/lookup/adapters", map[string]interface{}{"name": "ip-sites"}, map[string]interface{}{"name": "removed"}, map[string]interface{}{"name": "manual"})
+			graylog.add("system/lookup/caches", map[string]interface{}{"name": "lookup-cache"}, map[string]interface{}{"name": "removed"})
+			graylog.add("system/lookup/tables", map[string]interface{}{"name": "site"}, map[string]interface{}{"name": "removed"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			connector.ExternalObjects = &loggingService.ExternalObjectsStatus{
+				LookupTables:       []string{"site", "removed"},
+				LookupCaches:       []string{"lookup-cache", "removed"},
+				LookupDataAdapters: []string{"ip-sites", "removed"},
+			}
+			if err := connector.ManageLookupTables(cr); err != nil {
+				return err
+			}
+			objects := connector.ExternalObjects
+			if !slices.Equal(objects.LookupTables, []string{"site"}) || !slices.Equal(objects.LookupCaches, []string{"lookup-cache"}) ||
+				!slices.Equal(objects.LookupDataAdapters, []string{"ip-sites"}) {
+				return fmt.Errorf("unexpected objects in the status %+v", objects)
+			}
+			return nil
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {
+				graylog: map[string]int{
+					"DELETE system/lookup/tables/removed": 1, "DELETE system/lookup/caches/removed": 1, "DELETE system/lookup/adapters/removed": 1,
+				},
+				titles: map[string][]string{"system/lookup/adapters": {"ip-sites", "manual"}, "system/lookup/caches": {"lookup-cache"}, "system/lookup/tables": {"site"}},
+			},
+			forceUpdatePolicy: {
+				graylog: map[string]int{
+					"PUT system/lookup/adapters/ip-sites": 1, "PUT system/lookup/caches/lookup-cache": 1, "PUT system/lookup/tables/site": 1,
+					"DELETE system/lookup/tables/removed": 1, "DELETE system/lookup/caches/removed": 1, "DELETE system/lookup/adapters/removed": 1,
+				},
+				titles: map[string][]string{"system/lookup/adapters": {"ip-sites", "manual"}, "system/lookup/caches": {"lookup-cache"}, "system/lookup/tables": {"site"}},
+			},
+			skipPolicy: {
+				graylog: map[string]int{
+					"DELETE system/lookup/tables/removed": 1, "DELETE system/lookup/caches/removed": 1, "DELETE system/lookup/adapters/removed": 1,
+				},
+				titles: map[string][]string{"system/lookup/adapters": {"ip-sites", "manual"}, "system/lookup/caches": {"lookup-cache"}, "system/lookup/tables": {"site"}},
+			},
+		},
+	},
+	{
+		description: "ManageLookupTables fails if the data adapter of the lookup table is not found",
+		spec: loggingService.Graylog{
+			LookupTables: &loggingService.GraylogLookupTables{
+				Tables: []loggingService.GraylogLookupTable{{Name: "site", DataAdapter: "ip-sites", Cache: "lookup-cache"}},
+			},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageLookupTables(cr)
+		},
+		results: anyPolicy(manageResult{isError: true}),
+	},
+	{
+		description: "ManageAuthHeaderConfig enables the authentication by the HTTP header",
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageAuthHeaderConfig(cr)
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{"PUT system/authentication/http-header-auth-config": 1}}),
+	},
+	{
+		description: "ManageAuthentication activates the existing authentication backend",
+		spec: loggingService.Graylog{
+			Authentication: &loggingService.GraylogAuthentication{
+				Type: "ldap",
+				LDAP: &loggingService.GraylogLDAP{
+					Servers:                  []string{"ldap.example.com"},
+					SystemUserDN:             "cn=admin,dc=example,dc=com",
+					SystemUserPasswordSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "graylog-users"}, Key: "ldap"},
+					UserSearchBase:           "dc=example,dc=com",
+				},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/authentication/services/backends", map[string]interface{}{"title": "Logging operator ldap"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			_, err := connector.ManageAuthentication(context.Background(), cr, clientSet)
+			return err
+		},
+		results: map[string]manageResult{
+			onlyCreatePolicy: {graylog: map[string]int{
+				"POST system/authentication/services/test/backend/connection": 1, "POST system/authentication/services/configuration": 1,
+			}},
+			forceUpdatePolicy: {graylog: map[string]int{
+				"POST system/authentication/services/test/backend/connection": 1, "POST system/authentication/services/configuration": 1,
+				"PUT system/authentication/services/backends/{id}": 1,
+			}},
+			skipPolicy: {graylog: map[string]int{
+				"POST system/authentication/services/test/backend/connection": 1, "POST system/authentication/services/configuration": 1,
+			}},
+		},
+	},
+	{
+		description: "ManageAuthentication rolls back to the previous backend if the new one is not activated",
+		spec: loggingService.Graylog{
+			Authentication: &loggingService.GraylogAuthentication{
+				Type: "ldap",
+				LDAP: &loggingService.GraylogLDAP{
+					Servers:        []string{"[2001:db8::1]:636"},
+					UserSearchBase: "dc=example,dc=com",
+				},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.activeBackend = graylog.add("system/authentication/services/backends", map[string]interface{}{"title": "Corporate LDAP"})
+			graylog.rejectedBackend = "Logging operator ldap"
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			result, err := connector.ManageAuthentication(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			active, err := connector.GetActiveAuthBackend()
+			if err != nil {
+				return err
+			}
+			if result.Activated || active == nil || active.Title != "Corporate LDAP" {
+				return fmt.Errorf("expected rollback to Corporate LDAP, got %+v with the active backend %+v", result, active)
+			}
+			return nil
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{
+			"POST system/authentication/services/test/backend/connection": 1, "POST system/authentication/services/backends": 1,
+			"POST system/authentication/services/configuration": 2,
+		}}),
+	},
+	{
+		description: "ManageAuthentication deletes the backend with the previous title after the activation",
+		spec: loggingService.Graylog{
+			Authentication: &loggingService.GraylogAuthentication{
+				Type: "ldap",
+				LDAP: &loggingService.GraylogLDAP{
+					Servers:        []string{"ldap.example.com"},
+					UserSearchBase: "dc=example,dc=com",
+				},
+			},
+		},
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/authentication/services/backends", map[string]interface{}{"title": "Corporate LDAP"})
+			graylog.activeBackend = graylog.add("system/authentication/services/backends", map[string]interface{}{"title": "Logging operator active-directory"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			connector.ExternalObjects = &loggingService.ExternalObjectsStatus{AuthBackends: []string{"Logging operator active-directory"}}
+			if _, err := connector.ManageAuthentication(context.Background(), cr, clientSet); err != nil {
+				return err
+			}
+			if !reflect.DeepEqual(connector.ExternalObjects.AuthBackends, []string{"Logging operator ldap"}) {
+				return fmt.Errorf("unexpected authentication backends in the status: %v", connector.ExternalObjects.AuthBackends)
+			}
+			return nil
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{
+				"POST system/authentication/services/test/backend/connection": 1, "POST system/authentication/services/backends": 1,
+				"POST system/authentication/services/configuration": 1, "DELETE system/authentication/services/backends/{id}": 1,
+			},
+			titles: map[string][]string{"system/authentication/services/backends": {"Corporate LDAP", "Logging operator ldap"}},
+		}),
+	},
+	{
+		description: "DeleteAuthentication deactivates and deletes the backend removed from the spec",
+		seed: func(graylog *fakeGraylog, spec *loggingService.Graylog) {
+			graylog.add("system/authentication/services/backends", map[string]interface{}{"title": "Corporate LDAP"})
+			graylog.activeBackend = graylog.add("system/authentication/services/backends", map[string]interface{}{"title": "Logging operator ldap"})
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			connector.ExternalObjects = &loggingService.ExternalObjectsStatus{AuthBackends: []string{"Logging operator ldap"}}
+			if err := connector.DeleteAuthentication(cr); err != nil {
+				return err
+			}
+			active, err := connector.GetActiveAuthBackend()
+			if err != nil {
+				return err
+			}
+			if active != nil || len(connector.ExternalObjects.AuthBackends) != 0 {
+				return fmt.Errorf("expected no active backend and no backends in the status, got %+v and %v", active, connector.ExternalObjects.AuthBackends)
+			}
+			return nil
+		},
+		results: anyPolicy(manageResult{
+			graylog: map[string]int{
+				"POST system/authentication/services/configuration": 1, "DELETE system/authentication/services/backends/{id}": 1,
+			},
+			titles: map[string][]string{"system/authentication/services/backends": {"Corporate LDAP"}},
+		}),
+	},
+	{
+		description: "ManageAuthentication doesn't create the oidc backend without Graylog Enterprise",
+		spec: loggingService.Graylog{
+			Authentication: &loggingService.GraylogAuthentication{
+				Type: "oidc",
+				OIDC: &loggingService.GraylogOIDC{BaseURL: "https://idp.example.com", ClientID: "graylog"},
+			},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			result, err := connector.ManageAuthentication(context.Background(), cr, clientSet)
+			if err != nil {
+				return err
+			}
+			if result.Activated {
+				return fmt.Errorf("expected the oidc backend not activated, got %+v", result)
+			}
+			return nil
+		},
+		results: anyPolicy(manageResult{graylog: map[string]int{}}),
+	},
+	{
+		description: "ManageOpensearchConfigs sends requests from the config files",
+		files: map[string]string{
+			"opensearch-configs/replicas.json": `{"requests": [{"method": "PUT", "url": "graylog_*/_settings", "body": {"index": {"number_of_replicas": 1}}}]}`,
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageOpensearchConfigs(cr)
+		},
+		results: anyPolicy(manageResult{openSearch: map[string]int{"PUT graylog_*/_settings": 1}}),
+	},
+	{
+		description: "ManageArchivesDirectory registers the snapshot repository",
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageArchivesDirectory(cr)
+		},
+		results: anyPolicy(manageResult{openSearch: map[string]int{"PUT _snapshot/archives": 1}}),
+	},
+	{
+		description: "ManageSnapshotPolicies attaches ISM policies to the rotated indices of the streams",
+		spec: loggingService.Graylog{
+			Streams: []loggingService.Stream{{Name: util.GraylogAuditStream, Install: true, RetentionStrategy: snapshotRetention}},
+		},
+		openSearch: map[string]map[string]interface{}{
+			"gray_audit_*/_alias": {
+				"gray_audit_1":       map[string]interface{}{"aliases": map[string]interface{}{}},
+				"gray_audit_2":       map[string]interface{}{"aliases": map[string]interface{}{}},
+				"gray_audit_3":       map[string]interface{}{"aliases": map[string]interface{}{"gray_audit_deflector": map[string]interface{}{}}},
+				"gray_audit_extra_1": map[string]interface{}{"aliases": map[string]interface{}{}},
+			},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			if err := connector.ManageSnapshotPolicies(cr); err != nil {
+				return err
+			}
+			// The second run doesn't replace the policy which is not changed
+			return connector.ManageSnapshotPolicies(cr)
+		},
+		results: anyPolicy(manageResult{openSearch: map[string]int{
+			"PUT _plugins/_ism/policies/graylog-gray_audit-snapshot": 1, "POST _plugins/_ism/add/gray_audit_1,gray_audit_2": 2,
+		}}),
+	},
+	{
+		description: "ManageSnapshotPolicies replaces the changed ISM policy with its sequence number",
+		spec: loggingService.Graylog{
+			Streams: []loggingService.Stream{{Name: util.GraylogAuditStream, Install: true, RetentionStrategy: snapshotRetention, SnapshotAfter: "7d"}},
+		},
+		openSearch: map[string]map[string]interface{}{
+			"_plugins/_ism/policies/graylog-gray_audit-snapshot": {"_seq_no": 5, "policy": map[string]interface{}{"default_state": "hot"}},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			return connector.ManageSnapshotPolicies(cr)
+		},
+		// The index set doesn't have rotated indices, so the policy isn't attached
+		results: anyPolicy(manageResult{openSearch: map[string]int{"PUT _plugins/_ism/policies/graylog-gray_audit-snapshot": 1}}),
+	},
+	{
+		description: "ManageOpenSearchObjects creates cluster settings, index templates and ISM policies",
+		spec: loggingService.Graylog{
+			OpenSearch: &loggingService.OpenSearch{
+				ClusterSettings: map[string]string{"cluster.max_shards_per_node": "2000"},
+				Templates:       []loggingService.OpenSearchIndexTemplate{{Name: "graylog-replicas", IndexPatterns: []string{"graylog_*"}, Settings: map[string]string{"number_of_replicas": "1"}}},
+				Policies:        []loggingService.OpenSearchPolicy{{Name: "graylog-retention", IndexPatterns: []string{"graylog_*"}, DeleteAfter: "30d"}},
+			},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			if err := connector.ManageOpenSearchObjects(cr); err != nil {
+				return err
+			}
+			// The second run doesn't change the objects which are not changed in the spec
+			return connector.ManageOpenSearchObjects(cr)
+		},
+		results: anyPolicy(manageResult{openSearch: map[string]int{
+			"PUT _cluster/settings": 1, "PUT _index_template/graylog-replicas": 1, "PUT _plugins/_ism/policies/graylog-retention": 1,
+		}}),
+	},
+	{
+		description: "ManageOpenSearchObjects removes the settings and deletes the objects removed from the spec",
+		spec: loggingService.Graylog{
+			OpenSearch: &loggingService.OpenSearch{
+				ClusterSettings: map[string]string{"cluster.max_shards_per_node": "2000"},
+				Templates:       []loggingService.OpenSearchIndexTemplate{{Name: "graylog-replicas", IndexPatterns: []string{"graylog_*"}, Settings: map[string]string{"number_of_replicas": "1"}}},
+			},
+		},
+		openSearch: map[string]map[string]interface{}{
+			"_cluster/settings": {
+				"cluster.max_shards_per_node":        "2000",
+				"cluster.routing.allocation.enable":  "all",
+				"indices.recovery.max_bytes_per_sec": "100mb",
+			},
+			"_index_template/graylog-replicas": {
+				"index_patterns": []interface{}{"graylog_*"},
+				"template": map[string]interface{}{"settings": map[string]interface{}{
+					"index.number_of_replicas": "1", "index.refresh_interval": "5s",
+				}},
+			},
+			"_index_template/graylog-old":        {"index_patterns": []interface{}{"graylog_old_*"}},
+			"_plugins/_ism/policies/graylog-old": {"_seq_no": 1, "policy": map[string]interface{}{"default_state": "hot"}},
+		},
+		manage: func(connector *GraylogConnector, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+			connector.ExternalObjects = &loggingService.ExternalObjectsStatus{
+				ClusterSettings:   []string{"cluster.max_shards_per_node", "indices.recovery.max_bytes_per_sec"},
+				IndexTemplates:    []string{"graylog-old", "graylog-replicas"},
+				LifecyclePolicies: []string{"graylog-old"},
+			}
+			if err := connector.ManageOpenSearchObjects(cr); err != nil {
+				return err
+			}
+			objects := connector.ExternalObjects
+			if !slices.Equal(objects.ClusterSettings, []string{"cluster.max_shards_per_node"}) ||
+				!slices.Equal(objects.IndexTemplates, []string{"graylog-replicas"}) || len(objects.LifecyclePolicies) != 0 {
+				return fmt.Errorf("unexpected objects in the status %+v", objects)
+			}
+			settings, err := connector.getOpenSearchObject(clusterSettingsUrl, nil)
+			if err != nil {
+				return err
+			}
+			if persistent := settings["persistent"].(map[string]interface{}); len(persistent) != 2 || persistent["indices.recovery.max_bytes_per_sec"] != nil {
+				return fmt.Errorf("expected the removed setting reset and other settings kept, got %v", persistent)
+			}
+			return nil
+		},
+		results: anyPolicy(manageResult{openSearch: map[string]int{
+			"PUT _cluster/settings": 1, "PUT _index_template/graylog-replicas": 1,
+			"DELETE _index_template/graylog-old": 1, "DELETE _plugins/_ism/policies/graylog-old": 1,
+		}}),
+	},
+}
+
+func Test_Manage(t *testing.T) {
+	defaultDataDir := dataDir
+	t.Cleanup(func() { dataDir = defaultDataDir })
+
+	for _, tt := range manageTests {
+		for policyName, policy := range contentDeployPolicies {
+			t.Run(tt.description+"/"+policyName, func(t *testing.T) {
+				dataDir = t.TempDir()
+				writeDataFiles(t, tt.files)
+
+				graylog := newFakeGraylog(t, "6.0.0")
+				seedDefaultObjects(graylog)
+				openSearch := newFakeOpenSearch(t, "opensearch")
+				for path, object := range tt.openSearch {
+					// The objects are copied, because the fake changes them in the runs with every policy
+					openSearch.objects[path] = copyJSON(t, object)
+				}
+
+				cr := &loggingService.LoggingService{
+					ObjectMeta: metav1.ObjectMeta{Name: "logging-service", Namespace: testNamespace},
+					Spec:       loggingService.LoggingServiceSpec{Graylog: tt.spec.DeepCopy()},
+				}
+				cr.Spec.Graylog.ContentDeployPolicy = policy
+				cr.Spec.Graylog.InputPort = testInputPort
+				if tt.seed != nil {
+					tt.seed(graylog, cr.Spec.Graylog)
+				}
+				connector := newTestConnector(t, cr, graylog, openSearch)
+				graylog.requests, openSearch.requests = nil, nil
+
+				err := tt.manage(connector, cr, fake.NewSimpleClientset(testSecret()))
+				result := tt.results[policyName]
+				if (err != nil) != result.isError {
+					t.Fatalf("expected error: %v, got: %v", result.isError, err)
+				}
+				if result.isError {
+					return
+				}
+				if changes := graylog.changes(); !equalChanges(changes, result.graylog) {
+					t.Errorf("expected Graylog requests %v, got %v", result.graylog, changes)
+				}
+				for path, titles := range result.titles {
+					if actual := graylog.titles(path); !reflect.DeepEqual(actual, titles) {
+						t.Errorf("expected %s %v, got %v", path, titles, actual)
+					}
+				}
+				if changes := openSearch.changes(); !equalChanges(changes, result.openSearch) {
+					t.Errorf("expected OpenSearch requests %v, got %v", result.openSearch, changes)
+				}
+			})
+		}
+	}
+}
+
+// newTestConnector returns the connector to the fake servers with the capabilities of the fake Graylog
+func newTestConnector(t *testing.T, cr *loggingService.LoggingService, graylog *fakeGraylog, openSearch *fakeOpenSearch) *GraylogConnector {
+	restClient := &util.RestClient{Client: graylog.server.Client(), Host: graylog.server.Listener.Addr().String() + "/api/"}
+	client, err := graylogClient.New(restClient, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Errors of the fake are not retried
+	client.Backoff = wait.Backoff{Steps: 1}
+
+	connector := &GraylogConnector{
+		Client:               client,
+		RestClient:           restClient,
+		OpenSearchRestClient: &util.RestClient{Client: openSearch.server.Client(), Host: openSearch.server.URL},
+		Log:                  util.Logger("connector"),
+		Assets:               os.DirFS(".."),
+		EnabledStreams:       GetStreams(cr),
+		ctx:                  context.Background(),
+	}
+	if _, err = connector.ProbeVersion(); err != nil {
+		t.Fatal(err)
+	}
+	return connector
+}
+
+// copyJSON returns the deep copy of the object decoded from its JSON
+func copyJSON(t *testing.T, object map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var copied map[string]interface{}
+	if err = json.Unmarshal(data, &copied); err != nil {
+		t.Fatal(err)
+	}
+	return copied
+}
+
+// seedDefaultObjects adds the objects which are created by Graylog on the start
+func seedDefaultObjects(graylog *fakeGraylog) {
+	graylog.add("streams",
+		map[string]interface{}{"id": "000000000000000000000001", "title": util.GraylogAllMessagesStream},
+		map[string]interface{}{"id": allEventsStreamId, "title": "All events"},
+		map[string]interface{}{"id": "000000000000000000000003", "title": "All system events"})
+	seedIndexSets(graylog, util.GraylogDefaultIndexSet)
+	graylog.add("roles", map[string]interface{}{"name": "Admin"}, map[string]interface{}{"name": readerRole})
+}
+
+func seedIndexSets(graylog *fakeGraylog, titles ...string) {
+	for _, title := range titles {
+		graylog.add("system/indices/index_sets", map[string]interface{}{"title": title})
+	}
+}
+
+func testSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "graylog-users", Namespace: testNamespace},
+		Data: map[string][]byte{
+			"alice":   []byte("alice-password"),
+			"webhook": []byte("https://alerts.example.com/webhook"),
+			"ldap":    []byte("ldap-password"),
+		},
+	}
+}
+
+func writeDataFiles(t *testing.T, files map[string]string) {
+	for name, content := range files {
+		fileName := filepath.Join(dataDir, name)
+		if err := os.MkdirAll(filepath.Dir(fileName), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fileName, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// contentPacksArchive returns the zip archive with the revision of the content pack in the content packs directory
+func contentPacksArchive(t *testing.T, revision int) []byte {
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+	file, err := archive.Create(contentPacksDir + "/test-pack.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fmt.Fprintf(file, `{"id": "%s", "rev": %d, "name": "Test pack", "entities": []}`, testContentPackId, revision); err != nil {
+		t.Fatal(err)
+	}
+	if err = archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// seedOCIArtifact serves the manifest and the layer of the OCI artifact with the file
+// by the OCI Distribution API and returns the reference of the artifact
+func seedOCIArtifact(graylog *fakeGraylog, reference string, name string, data []byte) string {
+	repository, tag, _ := strings.Cut(reference, ":")
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"layers": []map[string]interface{}{{
+			"mediaType":   "application/zip",
+			"digest":      digest,
+			"size":        len(data),
+			"annotations": map[string]string{"org.opencontainers.image.title": name},
+		}},
+	})
+	if err != nil {
+		graylog.t.Fatal(err)
+	}
+	graylog.files["/v2/"+repository+"/manifests/"+tag] = manifest
+	graylog.files["/v2/"+repository+"/blobs/"+digest] = data
+	return "http://" + graylog.server.Listener.Addr().String() + "/" + reference
+}
+
+// checkContentPacks compares the installed content packs with the expected ones,
+// the ids of the new installations are only checked to be set
+func checkContentPacks(actual []loggingService.ContentPackStatus, expected ...loggingService.ContentPackStatus) error {
+	if len(actual) != len(expected) {
+		return fmt.Errorf("expected content packs %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if expected[i].InstallationId == "" && actual[i].InstallationId != "" {
+			expected[i].InstallationId = actual[i].InstallationId
+		}
+		if actual[i] != expected[i] {
+			return fmt.Errorf("expected content packs %v, got %v", expected, actual)
+		}
+	}
+	return nil
+}
+
+func equalChanges(actual map[string]int, expected map[string]int) bool {
+	if len(actual) == 0 && len(expected) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(actual, expected)
+}
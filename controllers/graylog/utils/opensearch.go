@@ -0,0 +1,540 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+)
+
+const (
+	indexTemplateUrl   = "_index_template/"
+	ilmPolicyUrl       = "_ilm/policy/"
+	clusterSettingsUrl = "_cluster/settings"
+	clusterHealthUrl   = "_cluster/health"
+
+	openSearchDistribution = "opensearch"
+)
+
+// OpenSearchHealth is the health of the OpenSearch cluster
+type OpenSearchHealth struct {
+	Status           string `json:"status"`
+	NumberOfNodes    int    `json:"number_of_nodes"`
+	UnassignedShards int    `json:"unassigned_shards"`
+}
+
+// getOpenSearchObject gets the object from OpenSearch, it returns nil if the object doesn't exist
+func (connector *GraylogConnector) getOpenSearchObject(urlPath string, query url.Values) (map[string]interface{}, error) {
+	response, statusCode, err := connector.SendRequestToOpenSearch(http.MethodGet, withQuery(urlPath, query), nil)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't get OpenSearch object %s. Status code: %v. Response: %s", urlPath, statusCode, response)
+	}
+	var object map[string]interface{}
+	if err = json.Unmarshal(response, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// putOpenSearchObject creates or replaces the object in OpenSearch
+func (connector *GraylogConnector) putOpenSearchObject(urlPath string, query url.Values, data []byte) error {
+	response, statusCode, err := connector.SendRequestToOpenSearch(http.MethodPut, withQuery(urlPath, query), data)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK && statusCode != http.StatusCreated {
+		return fmt.Errorf("can't update OpenSearch object %s. Status code: %v. Response: %s", urlPath, statusCode, response)
+	}
+	return nil
+}
+
+// withQuery adds the query parameters to the path of the request
+func withQuery(urlPath string, query url.Values) string {
+	if len(query) == 0 {
+		return urlPath
+	}
+	return urlPath + "?" + query.Encode()
+}
+
+// deleteOpenSearchObject deletes the object from OpenSearch, the object which doesn't exist is skipped
+func (connector *GraylogConnector) deleteOpenSearchObject(urlPath string) error {
+	response, statusCode, err := connector.SendRequestToOpenSearch(http.MethodDelete, urlPath, nil)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK && statusCode != http.StatusNotFound {
+		return fmt.Errorf("can't delete OpenSearch object %s. Status code: %v. Response: %s", urlPath, statusCode, response)
+	}
+	connector.Log.Info("OpenSearch object " + urlPath + " deleted")
+	return nil
+}
+
+// isOpenSearch checks the distribution of the cluster. Elasticsearch doesn't have ISM, it uses ILM policies instead
+func (connector *GraylogConnector) isOpenSearch() (bool, error) {
+	root, err := connector.getOpenSearchObject("", nil)
+	if err != nil {
+		return false, err
+	}
+	version, _ := root["version"].(map[string]interface{})
+	return version["distribution"] == openSearchDistribution, nil
+}
+
+// GetOpenSearchHealth returns the health of the OpenSearch cluster
+func (connector *GraylogConnector) GetOpenSearchHealth() (*OpenSearchHealth, error) {
+	response, statusCode, err := connector.SendRequestToOpenSearch(http.MethodGet, clusterHealthUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't get OpenSearch cluster health. Status code: %v. Response: %s", statusCode, response)
+	}
+	health := &OpenSearchHealth{}
+	if err = json.Unmarshal(response, health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// ManageOpenSearchObjects creates or updates the cluster settings, index templates and lifecycle policies
+// from the spec. The object is sent to OpenSearch only if it differs from the existing one. The objects which
+// are removed from the spec are deleted, and the removed cluster settings are reset to their defaults
+func (connector *GraylogConnector) ManageOpenSearchObjects(cr *loggingService.LoggingService) error {
+	spec := cr.Spec.Graylog.OpenSearch
+	if spec == nil {
+		spec = &loggingService.OpenSearch{}
+	}
+	objects := connector.externalObjects()
+	if len(spec.ClusterSettings) != 0 || len(objects.ClusterSettings) != 0 {
+		if err := connector.manageClusterSettings(spec.ClusterSettings); err != nil {
+			return err
+		}
+	}
+
+	var templates []string
+	for _, template := range spec.Templates {
+		templates = append(templates, template.Name)
+		if !slices.Contains(objects.IndexTemplates, template.Name) {
+			objects.IndexTemplates = append(objects.IndexTemplates, template.Name)
+		}
+		if err := connector.manageIndexTemplate(template); err != nil {
+			return err
+		}
+	}
+	for _, name := range slices.Clone(objects.IndexTemplates) {
+		if slices.Contains(templates, name) {
+			continue
+		}
+		if err := connector.deleteOpenSearchObject(indexTemplateUrl + name); err != nil {
+			return err
+		}
+		objects.IndexTemplates = slices.DeleteFunc(objects.IndexTemplates, func(item string) bool { return item == name })
+	}
+
+	if len(spec.Policies) == 0 && len(objects.LifecyclePolicies) == 0 {
+		return nil
+	}
+	isOpenSearch, err := connector.isOpenSearch()
+	if err != nil {
+		return err
+	}
+	var policies []string
+	for _, policy := range spec.Policies {
+		policies = append(policies, policy.Name)
+		if !slices.Contains(objects.LifecyclePolicies, policy.Name) {
+			objects.LifecyclePolicies = append(objects.LifecyclePolicies, policy.Name)
+		}
+		if isOpenSearch {
+			err = connector.manageISMPolicy(policy.Name, ismPolicyBody(policy))
+		} else {
+			err = connector.manageILMPolicy(policy)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range slices.Clone(objects.LifecyclePolicies) {
+		if slices.Contains(policies, name) {
+			continue
+		}
+		if err = connector.deleteOpenSearchObject(lifecyclePolicyUrl(name, isOpenSearch)); err != nil {
+			return err
+		}
+		objects.LifecyclePolicies = slices.DeleteFunc(objects.LifecyclePolicies, func(item string) bool { return item == name })
+	}
+	return nil
+}
+
+// DeleteOpenSearchObjects deletes the index templates and lifecycle policies created by the operator.
+// The cluster settings are kept, because their previous values are unknown
+func (connector *GraylogConnector) DeleteOpenSearchObjects(cr *loggingService.LoggingService) error {
+	objects := connector.externalObjects()
+	templates, policies := slices.Clone(objects.IndexTemplates), slices.Clone(objects.LifecyclePolicies)
+	if spec := cr.Spec.Graylog.OpenSearch; spec != nil {
+		for _, template := range spec.Templates {
+			if !slices.Contains(templates, template.Name) {
+				templates = append(templates, template.Name)
+			}
+		}
+		for _, policy := range spec.Policies {
+			if !slices.Contains(policies, policy.Name) {
+				policies = append(policies, policy.Name)
+			}
+		}
+	}
+	for _, name := range templates {
+		if err := connector.deleteOpenSearchObject(indexTemplateUrl + name); err != nil {
+			return err
+		}
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	isOpenSearch, err := connector.isOpenSearch()
+	if err != nil {
+		return err
+	}
+	for _, name := range policies {
+		if err = connector.deleteOpenSearchObject(lifecyclePolicyUrl(name, isOpenSearch)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lifecyclePolicyUrl returns the path of the ISM policy in OpenSearch or the ILM policy in Elasticsearch
+func lifecyclePolicyUrl(name string, isOpenSearch bool) string {
+	if isOpenSearch {
+		return ismPoliciesUrl + name
+	}
+	return ilmPolicyUrl + name
+}
+
+// manageClusterSettings sets the persistent cluster settings from the spec. The settings which were set by
+// the operator before and are removed from the spec are reset, the other settings of the cluster are kept
+func (connector *GraylogConnector) manageClusterSettings(settings map[string]string) error {
+	objects := connector.externalObjects()
+	existing, err := connector.getOpenSearchObject(clusterSettingsUrl, url.Values{"flat_settings": {"true"}})
+	if err != nil {
+		return err
+	}
+	persistent, _ := existing["persistent"].(map[string]interface{})
+	changed := map[string]interface{}{}
+	for key, value := range settings {
+		if existingValue, ok := persistent[key].(string); !ok || existingValue != value {
+			changed[key] = value
+		}
+	}
+	for _, key := range objects.ClusterSettings {
+		if _, found := settings[key]; !found && persistent[key] != nil {
+			changed[key] = nil
+		}
+	}
+	if len(changed) != 0 {
+		body, err := json.Marshal(map[string]interface{}{"persistent": changed})
+		if err != nil {
+			return err
+		}
+		if err = connector.putOpenSearchObject(clusterSettingsUrl, nil, body); err != nil {
+			return err
+		}
+		connector.Log.Info("OpenSearch cluster settings updated")
+	}
+	objects.ClusterSettings = nil
+	for key := range settings {
+		objects.ClusterSettings = append(objects.ClusterSettings, key)
+	}
+	slices.Sort(objects.ClusterSettings)
+	return nil
+}
+
+func (connector *GraylogConnector) manageIndexTemplate(template loggingService.OpenSearchIndexTemplate) error {
+	desired := indexTemplateBody(template)
+	response, err := connector.getOpenSearchObject(indexTemplateUrl+template.Name, url.Values{"flat_settings": {"true"}})
+	if err != nil {
+		return err
+	}
+	var existing interface{}
+	if templates, ok := response["index_templates"].([]interface{}); ok && len(templates) != 0 {
+		existing = templates[0].(map[string]interface{})["index_template"]
+	}
+	body, changed, err := diffOpenSearchObject(existing, desired, indexTemplateSections)
+	if err != nil || !changed {
+		return err
+	}
+	if err = connector.putOpenSearchObject(indexTemplateUrl+template.Name, nil, body); err != nil {
+		return err
+	}
+	connector.Log.Info("OpenSearch index template " + template.Name + " updated")
+	return nil
+}
+
+// manageISMPolicy creates the ISM policy or replaces it if it differs from the desired one
+func (connector *GraylogConnector) manageISMPolicy(name string, desired map[string]interface{}) error {
+	existing, err := connector.getOpenSearchObject(ismPoliciesUrl+name, nil)
+	if err != nil {
+		return err
+	}
+	var query url.Values
+	if existing != nil {
+		// OpenSearch replaces the existing policy only with its sequence number and primary term
+		query = url.Values{
+			"if_seq_no":       {fmt.Sprint(existing["_seq_no"])},
+			"if_primary_term": {fmt.Sprint(existing["_primary_term"])},
+		}
+		existing = map[string]interface{}{"policy": existing["policy"]}
+	}
+	body, changed, err := diffOpenSearchObject(existing, desired, ismPolicySections)
+	if err != nil || !changed {
+		return err
+	}
+	if err = connector.putOpenSearchObject(ismPoliciesUrl+name, query, body); err != nil {
+		return err
+	}
+	connector.Log.Info("OpenSearch ISM policy " + name + " updated")
+	return nil
+}
+
+func (connector *GraylogConnector) manageILMPolicy(policy loggingService.OpenSearchPolicy) error {
+	desired := ilmPolicyBody(policy)
+	response, err := connector.getOpenSearchObject(ilmPolicyUrl+policy.Name, nil)
+	if err != nil {
+		return err
+	}
+	var existing interface{}
+	if found, ok := response[policy.Name].(map[string]interface{}); ok {
+		existing = map[string]interface{}{"policy": found["policy"]}
+	}
+	body, changed, err := diffOpenSearchObject(existing, desired, ilmPolicySections)
+	if err != nil || !changed {
+		return err
+	}
+	if err = connector.putOpenSearchObject(ilmPolicyUrl+policy.Name, nil, body); err != nil {
+		return err
+	}
+	connector.Log.Info("Elasticsearch ILM policy " + policy.Name + " updated")
+	return nil
+}
+
+func indexTemplateBody(template loggingService.OpenSearchIndexTemplate) map[string]interface{} {
+	content := map[string]interface{}{}
+	if len(template.Settings) != 0 {
+		settings := map[string]string{}
+		for key, value := range template.Settings {
+			// OpenSearch stores the index settings with the "index." prefix
+			if !strings.HasPrefix(key, "index.") {
+				key = "index." + key
+			}
+			settings[key] = value
+		}
+		content["settings"] = settings
+	}
+	if len(template.FieldTypes) != 0 {
+		properties := map[string]interface{}{}
+		for field, fieldType := range template.FieldTypes {
+			properties[field] = map[string]string{"type": fieldType}
+		}
+		content["mappings"] = map[string]interface{}{"properties": properties}
+	}
+	body := map[string]interface{}{"index_patterns": template.IndexPatterns}
+	if template.Priority != 0 {
+		body["priority"] = template.Priority
+	}
+	if len(content) != 0 {
+		body["template"] = content
+	}
+	return body
+}
+
+func ismPolicyBody(policy loggingService.OpenSearchPolicy) map[string]interface{} {
+	hot := map[string]interface{}{"name": "hot", "actions": []interface{}{}, "transitions": []interface{}{}}
+	states := []interface{}{hot}
+	last := hot
+	if policy.WarmAfter != "" {
+		actions := []interface{}{}
+		if policy.WarmReplicas != nil {
+			actions = append(actions, map[string]interface{}{"replica_count": map[string]interface{}{"number_of_replicas": *policy.WarmReplicas}})
+		}
+		warm := map[string]interface{}{"name": "warm", "actions": actions, "transitions": []interface{}{}}
+		last["transitions"] = []interface{}{ismTransition("warm", policy.WarmAfter)}
+		states = append(states, warm)
+		last = warm
+	}
+	if policy.DeleteAfter != "" {
+		last["transitions"] = []interface{}{ismTransition("delete", policy.DeleteAfter)}
+		states = append(states, map[string]interface{}{
+			"name":        "delete",
+			"actions":     []interface{}{map[string]interface{}{"delete": map[string]interface{}{}}},
+			"transitions": []interface{}{},
+		})
+	}
+	body := map[string]interface{}{
+		"description":   policy.Description,
+		"default_state": "hot",
+		"states":        states,
+	}
+	if len(policy.IndexPatterns) != 0 {
+		body["ism_template"] = []interface{}{map[string]interface{}{"index_patterns": policy.IndexPatterns, "priority": policy.Priority}}
+	}
+	return map[string]interface{}{"policy": body}
+}
+
+func ismTransition(state string, age string) map[string]interface{} {
+	return map[string]interface{}{"state_name": state, "conditions": map[string]interface{}{"min_index_age": age}}
+}
+
+func ilmPolicyBody(policy loggingService.OpenSearchPolicy) map[string]interface{} {
+	phases := map[string]interface{}{"hot": map[string]interface{}{"actions": map[string]interface{}{}}}
+	if policy.WarmAfter != "" {
+		actions := map[string]interface{}{}
+		if policy.WarmReplicas != nil {
+			actions["allocate"] = map[string]interface{}{"number_of_replicas": *policy.WarmReplicas}
+		}
+		phases["warm"] = map[string]interface{}{"min_age": policy.WarmAfter, "actions": actions}
+	}
+	if policy.DeleteAfter != "" {
+		phases["delete"] = map[string]interface{}{"min_age": policy.DeleteAfter, "actions": map[string]interface{}{"delete": map[string]interface{}{}}}
+	}
+	return map[string]interface{}{"policy": map[string]interface{}{"phases": phases}}
+}
+
+// diffOpenSearchObject returns the body of the desired object and whether the existing object has to be replaced.
+// The sections of the objects which are managed by the operator are compared exactly, so the settings
+// which are removed from the spec are also removed from the existing object
+func diffOpenSearchObject(existing interface{}, desired interface{}, sections func(object interface{}) map[string]interface{}) ([]byte, bool, error) {
+	body, err := json.Marshal(desired)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing == nil {
+		return body, true, nil
+	}
+	var normalized interface{}
+	if err = json.Unmarshal(body, &normalized); err != nil {
+		return nil, false, err
+	}
+	return body, !equalJSON(sections(existing), sections(normalized)), nil
+}
+
+// indexTemplateSections returns the index patterns, the priority, the settings and the mappings of the index template
+func indexTemplateSections(template interface{}) map[string]interface{} {
+	sections := managedSections(template, "index_patterns", "priority")
+	object, _ := template.(map[string]interface{})
+	if content := managedSections(object["template"], "settings", "mappings"); len(content) != 0 {
+		sections["template"] = content
+	}
+	return sections
+}
+
+// ismPolicySections returns the states and the ISM templates of the policy without the fields added by OpenSearch
+func ismPolicySections(policy interface{}) map[string]interface{} {
+	object, _ := policy.(map[string]interface{})
+	sections := managedSections(object["policy"], "description", "default_state", "states", "ism_template")
+	states, _ := sections["states"].([]interface{})
+	for _, state := range states {
+		stateObject, _ := state.(map[string]interface{})
+		actions, _ := stateObject["actions"].([]interface{})
+		for _, action := range actions {
+			if actionObject, ok := action.(map[string]interface{}); ok {
+				delete(actionObject, "retry")
+			}
+		}
+	}
+	templates, _ := sections["ism_template"].([]interface{})
+	for _, template := range templates {
+		if templateObject, ok := template.(map[string]interface{}); ok {
+			delete(templateObject, "last_updated_time")
+		}
+	}
+	return sections
+}
+
+// ilmPolicySections returns the phases of the policy without the default age of the hot phase added by Elasticsearch
+func ilmPolicySections(policy interface{}) map[string]interface{} {
+	object, _ := policy.(map[string]interface{})
+	sections := managedSections(object["policy"], "phases")
+	phases, _ := sections["phases"].(map[string]interface{})
+	for _, phase := range phases {
+		if phaseObject, ok := phase.(map[string]interface{}); ok && phaseObject["min_age"] == "0ms" {
+			delete(phaseObject, "min_age")
+		}
+	}
+	return sections
+}
+
+// managedSections returns the values of the keys of the object. The empty values are skipped, because
+// OpenSearch returns them for the fields which are not set in the spec
+func managedSections(object interface{}, keys ...string) map[string]interface{} {
+	source, _ := object.(map[string]interface{})
+	sections := map[string]interface{}{}
+	for _, key := range keys {
+		switch value := source[key].(type) {
+		case nil:
+			continue
+		case string:
+			if value == "" {
+				continue
+			}
+		case float64:
+			if value == 0 {
+				continue
+			}
+		case map[string]interface{}:
+			if len(value) == 0 {
+				continue
+			}
+		case []interface{}:
+			if len(value) == 0 {
+				continue
+			}
+		}
+		sections[key] = source[key]
+	}
+	return sections
+}
+
+// equalJSON compares the decoded JSON values. OpenSearch returns the numbers of the settings as strings,
+// so the number is equal to the string with the same value
+func equalJSON(existing interface{}, desired interface{}) bool {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		existingValue, ok := existing.(map[string]interface{})
+		if !ok || len(existingValue) != len(desiredValue) {
+			return false
+		}
+		for key, value := range desiredValue {
+			if _, found := existingValue[key]; !found || !equalJSON(existingValue[key], value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		existingValue, ok := existing.([]interface{})
+		if !ok || len(existingValue) != len(desiredValue) {
+			return false
+		}
+		for i := range desiredValue {
+			if !equalJSON(existingValue[i], desiredValue[i]) {
+				return false
+			}
+		}
+		return true
+	case float64:
+		if number, ok := existing.(string); ok {
+			return number == strconv.FormatFloat(desiredValue, 'f', -1, 64)
+		}
+		return existing == desired
+	default:
+		return existing == desired
+	}
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// outputTypes are the class names of the outputs by the short types, other types are used as the class names
+var outputTypes = map[string]string{
+	"gelf":   "org.graylog2.outputs.GelfOutput",
+	"stdout": "org.graylog2.outputs.LoggingOutput",
+}
+
+// ManageOutputs creates the outputs described in the custom resource and synchronizes their streams.
+// Existing outputs are updated only with the force-update content deploy policy
+func (connector *GraylogConnector) ManageOutputs(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+	if len(cr.Spec.Graylog.Outputs) == 0 {
+		return nil
+	}
+
+	outputs, err := connector.Client.ListOutputs(connector.context())
+	if err != nil {
+		return err
+	}
+	streams, err := connector.GetAllStreams()
+	if err != nil {
+		return err
+	}
+	for _, o := range cr.Spec.Graylog.Outputs {
+		streamIds := make([]string, 0, len(o.Streams))
+		for _, title := range o.Streams {
+			id := GetIdByTitle(streams, title)
+			if id == "" {
+				return fmt.Errorf("stream %s of output %s not found", title, o.Title)
+			}
+			streamIds = append(streamIds, id)
+		}
+
+		id := getOutputIdByTitle(outputs, o.Title)
+		switch {
+		case id == "":
+			body, err := connector.outputBody(ctx, o, cr.GetNamespace(), clientSet)
+			if err != nil {
+				return err
+			}
+			created, err := connector.Client.CreateOutput(connector.context(), *body)
+			if err != nil {
+				return fmt.Errorf("can't create output %s: %w", o.Title, err)
+			}
+			connector.recordCreated("output", o.Title)
+			id = created.Id
+		case cr.Spec.Graylog.IsForceUpdate():
+			body, err := connector.outputBody(ctx, o, cr.GetNamespace(), clientSet)
+			if err != nil {
+				return err
+			}
+			if err = connector.Client.UpdateOutput(connector.context(), id, *body); err != nil {
+				return fmt.Errorf("can't update output %s: %w", o.Title, err)
+			}
+			connector.recordUpdated("output", o.Title)
+		}
+		// Streams are synchronized with any policy, so the attachments which failed in the previous run are retried
+		if err = connector.syncOutputStreams(o.Title, id, streams, streamIds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncOutputStreams attaches the output to the streams with the ids and detaches it from other streams
+func (connector *GraylogConnector) syncOutputStreams(title string, outputId string, streams []Entity, streamIds []string) error {
+	expected := map[string]bool{}
+	for _, streamId := range streamIds {
+		expected[streamId] = true
+	}
+	for _, stream := range streams {
+		outputs, err := connector.Client.ListStreamOutputs(connector.context(), stream.Id)
+		if err != nil {
+			return err
+		}
+		attached := slices.ContainsFunc(outputs, func(output graylogClient.Output) bool {
+			return output.Id == outputId
+		})
+		switch {
+		case expected[stream.Id] && !attached:
+			if err = connector.Client.AddStreamOutputs(connector.context(), stream.Id, []string{outputId}); err != nil {
+				return fmt.Errorf("can't attach output %s to stream %s: %w", title, stream.Title, err)
+			}
+		case !expected[stream.Id] && attached:
+			if err = connector.Client.RemoveStreamOutput(connector.context(), stream.Id, outputId); err != nil && !graylogClient.IsNotFound(err) {
+				return fmt.Errorf("can't detach output %s from stream %s: %w", title, stream.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+// outputBody returns the output with the configuration where the values of the fields from secretRefs
+// are read from the Secrets
+func (connector *GraylogConnector) outputBody(ctx context.Context, o loggingService.GraylogOutput, namespace string, clientSet kubernetes.Interface) (*graylogClient.Output, error) {
+	outputType, found := outputTypes[o.Type]
+	if !found {
+		outputType = o.Type
+	}
+
+	configuration := map[string]interface{}{}
+	if len(o.Configuration.Raw) != 0 {
+		if err := json.Unmarshal(o.Configuration.Raw, &configuration); err != nil {
+			return nil, fmt.Errorf("can't parse configuration of output %s: %w", o.Title, err)
+		}
+	}
+	for field, ref := range o.SecretRefs {
+		secret, err := clientSet.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		value, found := secret.Data[ref.Key]
+		if !found {
+			return nil, fmt.Errorf("can't find key %s in Secret %s for output %s", ref.Key, ref.Name, o.Title)
+		}
+		configuration[field] = string(value)
+	}
+	return &graylogClient.Output{Title: o.Title, Type: outputType, Configuration: configuration}, nil
+}
+
+// DeleteOutputs deletes the outputs described in the custom resource, Graylog detaches them from the streams
+func (connector *GraylogConnector) DeleteOutputs(cr *loggingService.LoggingService) error {
+	if len(cr.Spec.Graylog.Outputs) == 0 {
+		return nil
+	}
+	outputs, err := connector.Client.ListOutputs(connector.context())
+	if err != nil {
+		return err
+	}
+	for _, o := range cr.Spec.Graylog.Outputs {
+		id := getOutputIdByTitle(outputs, o.Title)
+		if id == "" {
+			continue
+		}
+		if err = connector.Client.DeleteOutput(connector.context(), id); err != nil && !graylogClient.IsNotFound(err) {
+			return fmt.Errorf("can't delete output %s: %w", o.Title, err)
+		}
+		connector.Log.Info(fmt.Sprintf("Graylog output %s deleted", o.Title))
+	}
+	return nil
+}
+
+func getOutputIdByTitle(outputs []graylogClient.Output, title string) string {
+	for _, output := range outputs {
+		if output.Title == title {
+			return output.Id
+		}
+	}
+	return ""
+}
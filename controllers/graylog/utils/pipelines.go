@@ -3,51 +3,38 @@ package utils
 import (
 	"encoding/json"
 	"errors"
-	"net/http"
+	"fmt"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 )
 
-type PipelinePattern struct {
-	PipelineIds []string `json:"pipeline_ids"`
-	StreamId    string   `json:"stream_id"`
-}
-
 func (connector *GraylogConnector) GetAllPipelines() ([]Entity, error) {
-	streams, err := connector.GetData(pipelineUrl, "", nil)
+	pipelines, err := connector.Client.ListPipelines(connector.context())
 	if err != nil {
 		return nil, err
 	}
-	return streams, nil
-}
-
-func CreatePipelineBody(pipelineId string, streamId string) (string, error) {
-	pattern := PipelinePattern{PipelineIds: []string{pipelineId}, StreamId: streamId}
-	config, err := json.Marshal(pattern)
-
-	if err != nil {
-		return "", err
+	entities := make([]Entity, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		entities = append(entities, Entity{Id: pipeline.Id, Title: pipeline.Title})
 	}
-	return string(config), nil
+	return entities, nil
 }
 
 func (connector *GraylogConnector) UpdatePipeline(pipelines []Entity, cr *loggingService.LoggingService) error {
 	logsRoutingPipelineId := GetIdByTitle(pipelines, "Logs routing")
 
 	if logsRoutingPipelineId != "" {
-		data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, util.GraylogPipeline), util.GraylogPipeline, cr.ToParams())
+		pipeline, err := connector.pipelineBody(cr)
 		if err != nil {
 			return err
 		}
 
-		_, statusCode, err := connector.PUT(pipelineUrl+"/"+logsRoutingPipelineId, data)
-		if err != nil {
-			return err
-		}
-		if statusCode != http.StatusOK {
-			return errors.New("can't update pipeline " + logsRoutingPipelineId)
+		if _, err = connector.Client.UpdatePipeline(connector.context(), logsRoutingPipelineId, pipeline); err != nil {
+			return fmt.Errorf("can't update pipeline %s: %w", logsRoutingPipelineId, err)
 		}
+		connector.recordUpdated("pipeline", "Logs routing")
 	} else {
 		if err := connector.CreatePipeline(pipelines, cr); err != nil {
 			return err
@@ -61,18 +48,15 @@ func (connector *GraylogConnector) CreatePipeline(pipelines []Entity, cr *loggin
 	logsRoutingPipelineId := GetIdByTitle(pipelines, "Logs routing")
 
 	if logsRoutingPipelineId == "" {
-		data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, util.GraylogPipeline), util.GraylogPipeline, cr.ToParams())
+		pipeline, err := connector.pipelineBody(cr)
 		if err != nil {
 			return err
 		}
 
-		_, statusCode, err := connector.POST(pipelineUrl, data)
-		if err != nil {
-			return err
-		}
-		if statusCode != http.StatusOK {
-			return errors.New("can't create pipeline")
+		if _, err = connector.Client.CreatePipeline(connector.context(), pipeline); err != nil {
+			return fmt.Errorf("can't create pipeline: %w", err)
 		}
+		connector.recordCreated("pipeline", "Logs routing")
 	}
 
 	return nil
@@ -94,20 +78,23 @@ func (connector *GraylogConnector) ConnectPipeline(pipelines []Entity) error {
 		}
 	}
 
-	data, err := CreatePipelineBody(logsRoutingPipelineId, streamId)
-	if err != nil {
-		return err
+	connection := graylogClient.PipelineConnection{PipelineIds: []string{logsRoutingPipelineId}, StreamId: streamId}
+	if err = connector.Client.ConnectPipelines(connector.context(), connection); err != nil {
+		return fmt.Errorf("can't connect pipeline: %w", err)
 	}
 
-	_, statusCode, err := connector.POST("system/pipelines/connections/to_stream", data)
+	return nil
+}
+
+// pipelineBody returns the "Logs routing" pipeline from the template
+func (connector *GraylogConnector) pipelineBody(cr *loggingService.LoggingService) (graylogClient.Pipeline, error) {
+	var pipeline graylogClient.Pipeline
+	data, err := connector.pipelineData(cr)
 	if err != nil {
-		return err
-	}
-	if statusCode != http.StatusOK {
-		return errors.New("can't connect pipeline")
+		return pipeline, err
 	}
-
-	return nil
+	err = json.Unmarshal([]byte(data), &pipeline)
+	return pipeline, err
 }
 
 func (connector *GraylogConnector) CreateOrUpdatePipelines(pipelines []Entity, cr *loggingService.LoggingService) error {
@@ -1,35 +1,24 @@
 package utils
 
 import (
-	"encoding/json"
 	"errors"
-	"net/http"
+	"fmt"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 )
 
-type RulePattern struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Source      string `json:"source"`
-}
-
 func (connector *GraylogConnector) GetAllProcessingRules() ([]Entity, error) {
-	rules, err := connector.GetData(processingRulesUrl, "", nil)
+	rules, err := connector.Client.ListPipelineRules(connector.context())
 	if err != nil {
 		return nil, err
 	}
-	return rules, nil
-}
-
-func CreateRuleBody(title string, description string, source string) (string, error) {
-	pattern := RulePattern{Title: title, Description: description, Source: source}
-	config, err := json.Marshal(pattern)
-	if err != nil {
-		return "", err
+	entities := make([]Entity, 0, len(rules))
+	for _, rule := range rules {
+		entities = append(entities, Entity{Id: rule.Id, Title: rule.Title})
 	}
-	return string(config), nil
+	return entities, nil
 }
 
 func (connector *GraylogConnector) CreateRuleData(streams []Entity, title string, template string) (string, error) {
@@ -47,7 +36,15 @@ func (connector *GraylogConnector) CreateRuleData(streams []Entity, title string
 		"streamId": streamId,
 	}
 
-	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, template), template, settings)
+	var content string
+	if stream := connector.customStream(title); stream != nil {
+		// The source of the pipeline rule of the custom stream is set in the CR
+		template, content = stream.RuleTitle, stream.PipelineRule
+	} else {
+		content = util.MustAssetReader(connector.Assets, template)
+	}
+
+	data, err := util.ParseTemplate(content, template, settings)
 	if err != nil {
 		return "", err
 	}
@@ -56,35 +53,21 @@ func (connector *GraylogConnector) CreateRuleData(streams []Entity, title string
 }
 
 func (connector *GraylogConnector) UpdateRule(ruleId string, title string, description string, source string) error {
-	data, err := CreateRuleBody(title, description, source)
-	if err != nil {
-		return err
-	}
-
-	_, statusCode, err := connector.PUT(processingRulesUrl+"/"+ruleId, data)
-	if err != nil {
-		return err
-	}
-	if (statusCode != http.StatusOK) && (statusCode != http.StatusCreated) {
-		return errors.New("Can't update rule: " + title)
+	rule := graylogClient.PipelineRule{Title: title, Description: description, Source: source}
+	if _, err := connector.Client.UpdatePipelineRule(connector.context(), ruleId, rule); err != nil {
+		return fmt.Errorf("can't update rule %s: %w", title, err)
 	}
+	connector.recordUpdated("processing rule", title)
 
 	return nil
 }
 
 func (connector *GraylogConnector) CreateRule(title string, description string, source string) error {
-	data, err := CreateRuleBody(title, description, source)
-	if err != nil {
-		return err
-	}
-
-	_, statusCode, err := connector.POST(processingRulesUrl, data)
-	if err != nil {
-		return err
-	}
-	if (statusCode != http.StatusOK) && (statusCode != http.StatusCreated) {
-		return errors.New("Can't create rule: " + title)
+	rule := graylogClient.PipelineRule{Title: title, Description: description, Source: source}
+	if _, err := connector.Client.CreatePipelineRule(connector.context(), rule); err != nil {
+		return fmt.Errorf("can't create rule %s: %w", title, err)
 	}
+	connector.recordCreated("processing rule", title)
 
 	return nil
 }
@@ -211,6 +194,9 @@ func (connector *GraylogConnector) GetProcessingRules() map[string]string {
 				break
 			}
 		}
+		if connector.EnabledStreams[i].Custom && connector.EnabledStreams[i].RuleTitle != "" {
+			ruleToStream[connector.EnabledStreams[i].RuleTitle] = stream
+		}
 	}
 	return ruleToStream
 }
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"os"
@@ -10,7 +11,9 @@ import (
 	"strings"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -27,11 +30,15 @@ func FindById(slice []Entity, val string) (int, bool) {
 }
 
 func (connector *GraylogConnector) GetAllViews() ([]Entity, error) {
-	views, err := connector.GetData("views", "views", nil)
+	views, err := connector.Client.ListViews(connector.context())
 	if err != nil {
 		return nil, err
 	}
-	return views, nil
+	entities := make([]Entity, 0, len(views))
+	for _, view := range views {
+		entities = append(entities, Entity{Id: view.Id, Title: view.Title})
+	}
+	return entities, nil
 }
 
 func (connector *GraylogConnector) Upload(path string, template string, cr *loggingService.LoggingService) error {
@@ -50,6 +57,7 @@ func (connector *GraylogConnector) Upload(path string, template string, cr *logg
 		connector.Log.V(util.Debug).Info("Response: " + response)
 		return errors.New("can't upload the content. Path: " + path)
 	}
+	connector.recordCreated("view", filepath.Base(template))
 
 	return nil
 }
@@ -59,7 +67,13 @@ func (connector *GraylogConnector) UploadCustomSavedSearch(name string, cr *logg
 	if err != nil {
 		return err
 	}
-	data, err := util.ParseTemplate(fileContent, filepath.Join(dataDir, savedSearchesDir, name), cr.ToParams())
+	return connector.uploadCustomSavedSearch(name, fileContent, filepath.Join(dataDir, savedSearchesDir, name), cr)
+}
+
+// uploadCustomSavedSearch uploads the search or replaces the view from the template with the name
+// of the file, the source is the path of the template for the errors
+func (connector *GraylogConnector) uploadCustomSavedSearch(name string, fileContent string, source string, cr *loggingService.LoggingService) error {
+	data, err := util.ParseTemplate(fileContent, source, cr.ToParams())
 	if err != nil {
 		return err
 	}
@@ -83,8 +97,7 @@ func (connector *GraylogConnector) UploadCustomSavedSearch(name string, cr *logg
 			viewId := viewIds[2]
 			_, found := FindById(views, viewId)
 			if found {
-				_, _, err := connector.DELETE("views/" + viewId)
-				if err != nil {
+				if err = connector.Client.DeleteView(connector.context(), viewId); err != nil && !graylogClient.IsNotFound(err) {
 					return err
 				}
 			}
@@ -184,7 +197,9 @@ func (connector *GraylogConnector) CreateOrUpdateSavedSearches(savedSearches []E
 	return nil
 }
 
-func (connector *GraylogConnector) ManageCustomSavedSearches(cr *loggingService.LoggingService) error {
+// ManageCustomSavedSearches uploads the saved searches from the content packs archives
+// and from the sources of savedSearches
+func (connector *GraylogConnector) ManageCustomSavedSearches(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
 	if _, err := os.Stat(filepath.Join(dataDir, savedSearchesDir)); err == nil {
 		connector.Log.V(util.Debug).Info("Start processing custom saved searches")
 		files, err := os.ReadDir(filepath.Join(dataDir, savedSearchesDir))
@@ -201,11 +216,23 @@ func (connector *GraylogConnector) ManageCustomSavedSearches(cr *loggingService.
 	} else {
 		return err
 	}
+
+	for _, source := range cr.Spec.Graylog.SavedSearches {
+		files, err := util.FetchContent(ctx, &source.ContentSource, source.HTTPConfig, clientSet, cr.GetNamespace())
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err = connector.uploadCustomSavedSearch(file.Name, string(file.Data), file.Name, cr); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
-func (connector *GraylogConnector) ManageSavedSearches(cr *loggingService.LoggingService) error {
-	savedSearches, err := connector.GetData("views", "views", nil)
+func (connector *GraylogConnector) ManageSavedSearches(ctx context.Context, cr *loggingService.LoggingService, clientSet kubernetes.Interface) error {
+	savedSearches, err := connector.GetAllViews()
 	if err != nil {
 		return err
 	}
@@ -218,7 +245,7 @@ func (connector *GraylogConnector) ManageSavedSearches(cr *loggingService.Loggin
 		return err
 	}
 
-	if err = connector.ManageCustomSavedSearches(cr); err != nil {
+	if err = connector.ManageCustomSavedSearches(ctx, cr, clientSet); err != nil {
 		return err
 	}
 
@@ -1,70 +1,71 @@
 package utils
 
 import (
-	"encoding/json"
 	"errors"
-	"net/http"
+	"fmt"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 )
 
-type StreamPattern struct {
-	MatchingType string `json:"matching_type"`
-	Description  string `json:"description"`
-	Title        string `json:"title"`
-	IndexSetId   string `json:"index_set_id"`
-}
-
 func (connector *GraylogConnector) GetAllStreams() ([]Entity, error) {
-	streams, err := connector.GetData("streams", "streams", nil)
+	streams, err := connector.Client.ListStreams(connector.context())
 	if err != nil {
 		return nil, err
 	}
-	return streams, nil
+	entities := make([]Entity, 0, len(streams))
+	for _, stream := range streams {
+		entities = append(entities, Entity{Id: stream.Id, Title: stream.Title})
+	}
+	return entities, nil
 }
 
-func CreateStreamBody(description string, title string, indexSets []Entity, indexSetName string) (string, error) {
-	indexSetId := GetIdByTitle(indexSets, indexSetName)
+func CreateStreamBody(stream Stream, indexSets []Entity) (graylogClient.Stream, error) {
+	indexSetId := GetIdByTitle(indexSets, stream.IndexSet)
+	if indexSetId == "" {
+		return graylogClient.Stream{}, errors.New("index set " + stream.IndexSet + " of the stream " + stream.Title + " not found")
+	}
 
-	pattern := StreamPattern{MatchingType: "AND", Description: description, Title: title, IndexSetId: indexSetId}
-	config, err := json.Marshal(pattern)
-	if err != nil {
-		return "", err
+	body := graylogClient.Stream{MatchingType: "AND", Description: stream.Description, Title: stream.Title, IndexSetId: indexSetId}
+	if stream.Custom {
+		body.MatchingType = stream.MatchingType
+		body.Rules = streamRules(stream)
+		body.RemoveMatchesFromDefaultStream = stream.RemoveFromDefault
 	}
-	return string(config), nil
+	return body, nil
 }
 
-func (connector *GraylogConnector) UpdateStream(streamId string, indexSets []Entity, indexSetName string, description string, title string) error {
-	data, err := CreateStreamBody(description, title, indexSets, indexSetName)
+func (connector *GraylogConnector) UpdateStream(streamId string, indexSets []Entity, stream Stream) error {
+	body, err := CreateStreamBody(stream, indexSets)
 	if err != nil {
 		return err
 	}
-	_, statusCode, err := connector.PUT("streams/"+streamId, data)
-	if err != nil {
-		return err
+	if err = connector.Client.UpdateStream(connector.context(), streamId, body); err != nil {
+		return fmt.Errorf("can't update stream %s: %w", stream.Title, err)
 	}
-	if statusCode != http.StatusOK {
-		return errors.New("can't update stream " + title)
+	if stream.Custom {
+		if err = connector.ReplaceStreamRules(streamId, stream); err != nil {
+			return err
+		}
 	}
+	connector.recordUpdated("stream", stream.Title)
 
 	return nil
 }
 
-func (connector *GraylogConnector) UpdateOrCreateStream(streams []Entity, indexSets []Entity, indexSetName string, description string, title string) error {
-	streamId := GetIdByTitle(streams, title)
+func (connector *GraylogConnector) UpdateOrCreateStream(streams []Entity, indexSets []Entity, stream Stream) error {
+	streamId := GetIdByTitle(streams, stream.Title)
 
 	if streamId != "" {
 		if err := connector.UpdateStream(
 			streamId,
 			indexSets,
-			indexSetName,
-			description,
-			title); err != nil {
+			stream); err != nil {
 			return err
 		}
 	} else {
-		if err := connector.OnlyCreateStream(streams, indexSets, indexSetName, description, title); err != nil {
+		if err := connector.OnlyCreateStream(streams, indexSets, stream); err != nil {
 			return err
 		}
 	}
@@ -74,10 +75,8 @@ func (connector *GraylogConnector) UpdateOrCreateStream(streams []Entity, indexS
 
 func (connector *GraylogConnector) UpdateStreams(streams []Entity, indexSets []Entity) error {
 
-	streamToIndex := connector.GetStreams()
-
-	for s, i := range streamToIndex {
-		if err := connector.UpdateOrCreateStream(streams, indexSets, i, util.GraylogStreamsDescriptions[s], s); err != nil {
+	for _, stream := range connector.EnabledStreams {
+		if err := connector.UpdateOrCreateStream(streams, indexSets, stream); err != nil {
 			return err
 		}
 	}
@@ -85,31 +84,25 @@ func (connector *GraylogConnector) UpdateStreams(streams []Entity, indexSets []E
 	return nil
 }
 
-func (connector *GraylogConnector) CreateStream(indexSets []Entity, indexSetName string, description string, title string) error {
-	data, err := CreateStreamBody(description, title, indexSets, indexSetName)
-	if err != nil {
-		return err
-	}
-
-	_, statusCode, err := connector.POST("streams/", data)
+func (connector *GraylogConnector) CreateStream(indexSets []Entity, stream Stream) error {
+	body, err := CreateStreamBody(stream, indexSets)
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusCreated {
-		return errors.New("can't create stream " + title)
+	if _, err = connector.Client.CreateStream(connector.context(), body); err != nil {
+		return fmt.Errorf("can't create stream %s: %w", stream.Title, err)
 	}
+	connector.recordCreated("stream", stream.Title)
 	return nil
 }
 
-func (connector *GraylogConnector) OnlyCreateStream(streams []Entity, indexSets []Entity, indexSetName string, description string, title string) error {
-	streamId := GetIdByTitle(streams, title)
+func (connector *GraylogConnector) OnlyCreateStream(streams []Entity, indexSets []Entity, stream Stream) error {
+	streamId := GetIdByTitle(streams, stream.Title)
 
 	if streamId == "" {
 		if err := connector.CreateStream(
 			indexSets,
-			indexSetName,
-			description,
-			title); err != nil {
+			stream); err != nil {
 			return err
 		}
 	}
@@ -119,10 +112,8 @@ func (connector *GraylogConnector) OnlyCreateStream(streams []Entity, indexSets
 
 func (connector *GraylogConnector) CreateStreams(streams []Entity, indexSets []Entity) error {
 
-	streamToIndex := connector.GetStreams()
-
-	for s, i := range streamToIndex {
-		if err := connector.OnlyCreateStream(streams, indexSets, i, util.GraylogStreamsDescriptions[s], s); err != nil {
+	for _, stream := range connector.EnabledStreams {
+		if err := connector.OnlyCreateStream(streams, indexSets, stream); err != nil {
 			return err
 		}
 	}
@@ -131,12 +122,8 @@ func (connector *GraylogConnector) CreateStreams(streams []Entity, indexSets []E
 }
 
 func (connector *GraylogConnector) ResumeStream(id string) error {
-	_, statusCode, err := connector.POST("streams/"+id+"/resume", "")
-	if err != nil {
-		return err
-	}
-	if statusCode != http.StatusNoContent {
-		return errors.New("can't resume stream " + id)
+	if err := connector.Client.ResumeStream(connector.context(), id); err != nil {
+		return fmt.Errorf("can't resume stream %s: %w", id, err)
 	}
 	return nil
 }
@@ -175,6 +162,10 @@ func (connector *GraylogConnector) CreateOrUpdateStreams(streams []Entity, index
 }
 
 func (connector *GraylogConnector) ManageStreams(cr *loggingService.LoggingService) error {
+	if err := connector.validateCustomStreams(); err != nil {
+		return err
+	}
+
 	streams, err := connector.GetAllStreams()
 	if err != nil {
 		return err
@@ -203,14 +194,8 @@ func (connector *GraylogConnector) ManageStreams(cr *loggingService.LoggingServi
 func (connector *GraylogConnector) GetStreams() map[string]string {
 	streamToIndex := make(map[string]string)
 
-	for i := range connector.EnabledStreams {
-		stream := connector.EnabledStreams[i].Title
-		for s, i := range util.GraylogStreamsIndexTitles {
-			if s == stream {
-				streamToIndex[s] = i
-				break
-			}
-		}
+	for _, stream := range connector.EnabledStreams {
+		streamToIndex[stream.Title] = stream.IndexSet
 	}
 	return streamToIndex
 }
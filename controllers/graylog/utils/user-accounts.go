@@ -3,51 +3,43 @@ package utils
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
 	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 )
 
-type User struct {
-	Id       string `json:"id"`
-	Username string `json:"username"`
-}
-
+// GetRole returns the status code of the request of the role, it is 404 if the role doesn't exist
 func (connector *GraylogConnector) GetRole(role string) (int, error) {
-	_, code, err := connector.GET("roles/" + role)
-	if err != nil {
-		return code, err
+	if _, err := connector.Client.GetRole(connector.context(), role); err != nil {
+		if code := graylogClient.StatusCode(err); code != 0 {
+			return code, nil
+		}
+		return -1, err
 	}
-	return code, err
+	return http.StatusOK, nil
 }
 
 func (connector *GraylogConnector) GetUser(user string) (int, error) {
-	_, code, err := connector.GET("users/" + user)
+	url := "users/" + user
+	if connector.Capabilities != nil && !connector.Capabilities.UserLookupByName {
+		id := connector.GetUserIdByName(user)
+		if id == "" {
+			return http.StatusNotFound, nil
+		}
+		url = "users/id/" + id
+	}
+	_, code, err := connector.GET(url)
 	if err != nil {
 		return code, err
 	}
 	return code, err
 }
 
-func (connector *GraylogConnector) GetAllUsers() ([]User, error) {
-	users, err := connector.GetRawData("users")
-
-	if err != nil {
-		return nil, err
-	}
-
-	var data map[string]json.RawMessage
-
-	if err = json.Unmarshal([]byte(users), &data); err != nil {
-		return nil, err
-	}
-
-	var parsedData []User
-	if err = json.Unmarshal(data["users"], &parsedData); err != nil {
-		return nil, err
-	}
-	return parsedData, nil
+func (connector *GraylogConnector) GetAllUsers() ([]graylogClient.User, error) {
+	return connector.Client.ListUsers(connector.context())
 }
 
 func (connector *GraylogConnector) GetUserIdByName(username string) string {
@@ -172,56 +164,93 @@ func (connector *GraylogConnector) CreateAuditViewerData(dashboards []Entity, te
 }
 
 func (connector *GraylogConnector) CreateRole(data string) error {
-	_, statusCode, err := connector.POST("roles", data)
-	if err != nil {
+	var role graylogClient.Role
+	if err := json.Unmarshal([]byte(data), &role); err != nil {
 		return err
 	}
-	if statusCode != http.StatusCreated {
-		return errors.New("can't create role " + data)
+	if err := connector.Client.CreateRole(connector.context(), role); err != nil {
+		return fmt.Errorf("can't create role %s: %w", role.Name, err)
 	}
+	connector.recordCreated("role", role.Name)
 	return nil
 }
 
-func (connector *GraylogConnector) CreateUser(template string, cr *loggingService.LoggingService) error {
-	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, template), template, cr.ToParams())
-	if err != nil {
+func (connector *GraylogConnector) CreateUser(data string) error {
+	var user graylogClient.User
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
 		return err
 	}
+	if password, found := connector.UserPasswords[user.Username]; found {
+		user.Password = password
+	}
+	if err := connector.Client.CreateUser(connector.context(), user); err != nil {
+		return fmt.Errorf("can't create user %s: %w", user.Username, err)
+	}
+	connector.recordCreated("user", user.Username)
+	return nil
+}
 
-	_, statusCode, err := connector.POST("users", data)
-	if err != nil {
+func (connector *GraylogConnector) UpdateUser(username string, data string) error {
+	var user graylogClient.User
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
 		return err
 	}
-	if statusCode != http.StatusCreated {
-		return errors.New("can't create user " + data)
+	if err := connector.Client.UpdateUser(connector.context(), connector.GetUserIdByName(username), user); err != nil {
+		return fmt.Errorf("can't update user %s: %w", username, err)
 	}
+	connector.recordUpdated("user", username)
 	return nil
 }
 
-func (connector *GraylogConnector) UpdateUser(user string, template string, cr *loggingService.LoggingService) error {
-	data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, template), template, cr.ToParams())
-	if err != nil {
-		return err
+// ChangeUserPassword sets the password of the existing user. It returns false if the user doesn't exist
+func (connector *GraylogConnector) ChangeUserPassword(username string, password string) (bool, error) {
+	id := connector.GetUserIdByName(username)
+	if id == "" {
+		return false, nil
+	}
+	if err := connector.Client.ChangeUserPassword(connector.context(), id, password); err != nil {
+		return true, fmt.Errorf("can't change password of user %s: %w", username, err)
 	}
+	connector.EventRecorder.Normal(util.ReasonGraylogObjectUpdated, fmt.Sprintf("Password of Graylog user %s changed", username))
+	return true, nil
+}
 
-	_, statusCode, err := connector.PUT("users/"+connector.GetUserIdByName(user), data)
+// ConfirmLogin checks that Graylog accepts the credentials of the user
+func (connector *GraylogConnector) ConfirmLogin(username string, password string) error {
+	statusCode, err := connector.login(username, password)
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusNoContent {
-		return errors.New("can't update user " + data)
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("login of user %s failed. Status code: %v", username, statusCode)
 	}
 	return nil
 }
 
-func (connector *GraylogConnector) UpdateRole(role string, data string) error {
-	_, statusCode, err := connector.PUT("roles/"+role, data)
+// login sends the request with the credentials and returns its status code
+func (connector *GraylogConnector) login(username string, password string) (int, error) {
+	restClient := &util.RestClient{
+		Client: connector.RestClient.Client,
+		Auth:   &util.Сreds{Name: username, Password: password},
+		Host:   connector.RestClient.Host,
+	}
+	apiClient, err := graylogClient.New(restClient, connector.TLSEnabled)
 	if err != nil {
+		return -1, err
+	}
+	_, statusCode, err := apiClient.Raw(connector.context(), http.MethodGet, systemUrl, "")
+	return statusCode, err
+}
+
+func (connector *GraylogConnector) UpdateRole(name string, data string) error {
+	var role graylogClient.Role
+	if err := json.Unmarshal([]byte(data), &role); err != nil {
 		return err
 	}
-	if statusCode != http.StatusOK {
-		return errors.New("can't update role " + role)
+	if err := connector.Client.UpdateRole(connector.context(), name, role); err != nil {
+		return fmt.Errorf("can't update role %s: %w", name, err)
 	}
+	connector.recordUpdated("role", name)
 	return nil
 }
 
@@ -241,15 +270,15 @@ func (connector *GraylogConnector) CreateOrUpdateRole(role string, data string,
 	return nil
 }
 
-func (connector *GraylogConnector) CreateOrUpdateUser(user string, template string, cr *loggingService.LoggingService) error {
+func (connector *GraylogConnector) CreateOrUpdateUser(user string, data string, cr *loggingService.LoggingService) error {
 	userStatus, _ := connector.GetUser(user)
 
 	if userStatus == http.StatusNotFound {
-		if err := connector.CreateUser(template, cr); err != nil {
+		if err := connector.CreateUser(data); err != nil {
 			return err
 		}
 	} else if (cr.Spec.Graylog.IsForceUpdate()) && (userStatus == http.StatusOK) {
-		if err := connector.UpdateUser(user, template, cr); err != nil {
+		if err := connector.UpdateUser(user, data); err != nil {
 			return err
 		}
 	}
@@ -280,32 +309,31 @@ func (connector *GraylogConnector) CreateOrUpdateRoles(dashboards []Entity, cr *
 }
 
 func (connector *GraylogConnector) CreateOrUpdateUsers(cr *loggingService.LoggingService) error {
-	if err := connector.CreateOrUpdateUser("operator", util.GraylogOperatorUser, cr); err != nil {
-		return err
-	}
-
-	if err := connector.CreateOrUpdateUser("auditViewer", util.GraylogAuditViewerUser, cr); err != nil {
-		return err
-	}
-
-	if err := connector.CreateOrUpdateUser("graylog_api_th_user", util.GraylogAdminWithTrustedHeader, cr); err != nil {
-		return err
+	users := [][2]string{
+		{"operator", util.GraylogOperatorUser},
+		{"auditViewer", util.GraylogAuditViewerUser},
+		{"graylog_api_th_user", util.GraylogAdminWithTrustedHeader},
+	}
+	for _, user := range users {
+		data, err := util.ParseTemplate(util.MustAssetReader(connector.Assets, user[1]), user[1], cr.ToParams())
+		if err != nil {
+			return err
+		}
+		if err = connector.CreateOrUpdateUser(user[0], data, cr); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (connector *GraylogConnector) ManageUserAccounts(cr *loggingService.LoggingService, installGraylog5 bool) error {
+func (connector *GraylogConnector) GetAllDashboards(capabilities *GraylogCapabilities) ([]Entity, error) {
 	// Dashboards API has different names of field with returned dashboards depending on the Graylog version
-	// Graylog 4: "views"
-	// Graylog 5: "elements"
-	var getDashboardDataField string
-	if installGraylog5 {
-		getDashboardDataField = "elements"
-	} else {
-		getDashboardDataField = "views"
-	}
-	dashboards, err := connector.GetData("dashboards", getDashboardDataField, nil)
+	return connector.GetData("dashboards", capabilities.DashboardsField, nil)
+}
+
+func (connector *GraylogConnector) ManageUserAccounts(cr *loggingService.LoggingService, capabilities *GraylogCapabilities) error {
+	dashboards, err := connector.GetAllDashboards(capabilities)
 	if err != nil {
 		return err
 	}
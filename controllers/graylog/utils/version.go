@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+)
+
+const (
+	systemUrl = "system"
+
+	// LatestGraylogMajorVersion is the newest major version of Graylog supported by the operator
+	LatestGraylogMajorVersion = 6
+)
+
+var versionRegexp = regexp.MustCompile(`([0-9]+)\.([0-9]+)\.([0-9]+)`)
+
+// GraylogVersion is the version of Graylog
+type GraylogVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseGraylogVersion finds the version in the string like "5.2.3+9aee303" or "graylog/graylog:5.2.3"
+func ParseGraylogVersion(value string) (GraylogVersion, bool) {
+	version := versionRegexp.FindStringSubmatch(value)
+	if version == nil {
+		return GraylogVersion{}, false
+	}
+	major, _ := strconv.Atoi(version[1])
+	minor, _ := strconv.Atoi(version[2])
+	patch, _ := strconv.Atoi(version[3])
+	return GraylogVersion{Major: major, Minor: minor, Patch: patch}, true
+}
+
+func (version GraylogVersion) AtLeast(major int, minor int) bool {
+	return version.Major > major || (version.Major == major && version.Minor >= minor)
+}
+
+func (version GraylogVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch)
+}
+
+// GraylogCapabilities describes the APIs and features of the running Graylog which differ between versions
+type GraylogCapabilities struct {
+	Version GraylogVersion
+	// Extractors contains assets of the extractors in the format of the extractors API of the version.
+	// The extractors are not created if it's empty, so the messages are processed by the pipelines only
+	Extractors map[string]string
+	// DashboardsField is the field of the dashboards API response with the list of dashboards (views)
+	DashboardsField string
+	// UserLookupByName is true if the user can be got by its username, otherwise the user is found by its id
+	UserLookupByName bool
+	// TimeSizeOptimizingRotation and FieldTypes are available since Graylog 5.1
+	TimeSizeOptimizingRotation bool
+	FieldTypes                 bool
+}
+
+// NewGraylogCapabilities returns the capabilities of the Graylog version
+func NewGraylogCapabilities(version GraylogVersion) *GraylogCapabilities {
+	capabilities := &GraylogCapabilities{
+		Version:                    version,
+		DashboardsField:            "elements",
+		UserLookupByName:           version.Major < 6,
+		TimeSizeOptimizingRotation: version.AtLeast(5, 1),
+		FieldTypes:                 version.AtLeast(5, 1),
+	}
+	switch {
+	case version.Major <= 4:
+		capabilities.Extractors = util.Graylog4Extractors
+		capabilities.DashboardsField = "views"
+	case version.Major == 5:
+		capabilities.Extractors = util.Graylog5Extractors
+	default:
+		capabilities.Extractors = util.Graylog6Extractors
+	}
+	return capabilities
+}
+
+// ProbeVersion gets the version of the running Graylog and sets capabilities of the connector
+func (connector *GraylogConnector) ProbeVersion() (*GraylogCapabilities, error) {
+	response, statusCode, err := connector.GET(systemUrl)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't get Graylog version. Status code: %v. Response: %s", statusCode, response)
+	}
+	var system struct {
+		Version string `json:"version"`
+	}
+	if err = json.Unmarshal([]byte(response), &system); err != nil {
+		return nil, err
+	}
+	version, ok := ParseGraylogVersion(system.Version)
+	if !ok {
+		return nil, fmt.Errorf("can't parse Graylog version %s", system.Version)
+	}
+	connector.Capabilities = NewGraylogCapabilities(version)
+	return connector.Capabilities, nil
+}
+
+// ImageVersion finds the version in the tag of the image. Images pinned by digest usually don't have it
+func ImageVersion(image string) (GraylogVersion, bool) {
+	name := strings.Split(image[strings.LastIndex(image, "/")+1:], "@")[0]
+	tag := strings.Index(name, ":")
+	if tag < 0 {
+		return GraylogVersion{}, false
+	}
+	return ParseGraylogVersion(name[tag+1:])
+}
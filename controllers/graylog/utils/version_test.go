@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"testing"
+
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+)
+
+var parseGraylogVersionTests = []struct {
+	value   string
+	version GraylogVersion
+	ok      bool
+}{
+	{value: "5.2.3+9aee303", version: GraylogVersion{Major: 5, Minor: 2, Patch: 3}, ok: true},
+	{value: "6.0.0-rc.1", version: GraylogVersion{Major: 6}, ok: true},
+	{value: "graylog/graylog:4.3.15", version: GraylogVersion{Major: 4, Minor: 3, Patch: 15}, ok: true},
+	{value: "6.0", ok: false},
+	{value: "", ok: false},
+}
+
+func Test_ParseGraylogVersion(t *testing.T) {
+	for _, tt := range parseGraylogVersionTests {
+		version, ok := ParseGraylogVersion(tt.value)
+		if ok != tt.ok || version != tt.version {
+			t.Errorf("%q: expected %v (%v), got %v (%v)", tt.value, tt.version, tt.ok, version, ok)
+		}
+	}
+}
+
+var imageVersionTests = []struct {
+	description string
+	image       string
+	version     GraylogVersion
+	ok          bool
+}{
+	{
+		description: "Version in the tag",
+		image:       "graylog/graylog:5.2.7",
+		version:     GraylogVersion{Major: 5, Minor: 2, Patch: 7},
+		ok:          true,
+	},
+	{
+		description: "Registry host with the port",
+		image:       "registry.example.com:5000/graylog/graylog:6.0.5-1",
+		version:     GraylogVersion{Major: 6, Minor: 0, Patch: 5},
+		ok:          true,
+	},
+	{
+		description: "Port of the registry is not the version",
+		image:       "registry.example.com:5000/graylog/graylog",
+	},
+	{
+		description: "Image without the tag",
+		image:       "graylog/graylog",
+	},
+	{
+		description: "Image pinned by digest without the tag",
+		image:       "graylog/graylog@sha256:4b6f8e2a1c3d5e7f9a0b2c4d6e8f0a1b3c5d7e9f1a2b4c6d8e0f2a3b5c7d9e1f",
+	},
+	{
+		description: "Digest doesn't hide the version in the tag",
+		image:       "graylog/graylog:5.1.13@sha256:4b6f8e2a1c3d5e7f9a0b2c4d6e8f0a1b3c5d7e9f1a2b4c6d8e0f2a3b5c7d9e1f",
+		version:     GraylogVersion{Major: 5, Minor: 1, Patch: 13},
+		ok:          true,
+	},
+	{
+		description: "Tag without the version",
+		image:       "graylog/graylog:latest",
+	},
+}
+
+func Test_ImageVersion(t *testing.T) {
+	for _, tt := range imageVersionTests {
+		version, ok := ImageVersion(tt.image)
+		if ok != tt.ok || version != tt.version {
+			t.Errorf("%s: expected %v (%v), got %v (%v)", tt.description, tt.version, tt.ok, version, ok)
+		}
+	}
+}
+
+var graylogCapabilitiesTests = []struct {
+	version                    GraylogVersion
+	extractors                 map[string]string
+	dashboardsField            string
+	userLookupByName           bool
+	timeSizeOptimizingRotation bool
+}{
+	{
+		version:          GraylogVersion{Major: 4, Minor: 3, Patch: 15},
+		extractors:       util.Graylog4Extractors,
+		dashboardsField:  "views",
+		userLookupByName: true,
+	},
+	{
+		version:          GraylogVersion{Major: 5, Minor: 0, Patch: 13},
+		extractors:       util.Graylog5Extractors,
+		dashboardsField:  "elements",
+		userLookupByName: true,
+	},
+	{
+		version:                    GraylogVersion{Major: 5, Minor: 1},
+		extractors:                 util.Graylog5Extractors,
+		dashboardsField:            "elements",
+		userLookupByName:           true,
+		timeSizeOptimizingRotation: true,
+	},
+	{
+		version:                    GraylogVersion{Major: 6, Minor: 0, Patch: 5},
+		extractors:                 util.Graylog6Extractors,
+		dashboardsField:            "elements",
+		timeSizeOptimizingRotation: true,
+	},
+}
+
+func Test_NewGraylogCapabilities(t *testing.T) {
+	for _, tt := range graylogCapabilitiesTests {
+		capabilities := NewGraylogCapabilities(tt.version)
+		if capabilities.Version != tt.version {
+			t.Errorf("%s: expected version %v, got %v", tt.version, tt.version, capabilities.Version)
+		}
+		if len(capabilities.Extractors) != len(tt.extractors) {
+			t.Errorf("%s: expected %d extractors, got %d", tt.version, len(tt.extractors), len(capabilities.Extractors))
+		}
+		for name, asset := range tt.extractors {
+			if capabilities.Extractors[name] != asset {
+				t.Errorf("%s: expected extractor %s asset %s, got %s", tt.version, name, asset, capabilities.Extractors[name])
+			}
+		}
+		if capabilities.DashboardsField != tt.dashboardsField {
+			t.Errorf("%s: expected dashboards field %s, got %s", tt.version, tt.dashboardsField, capabilities.DashboardsField)
+		}
+		if capabilities.UserLookupByName != tt.userLookupByName {
+			t.Errorf("%s: expected user lookup by name %v, got %v", tt.version, tt.userLookupByName, capabilities.UserLookupByName)
+		}
+		if capabilities.TimeSizeOptimizingRotation != tt.timeSizeOptimizingRotation || capabilities.FieldTypes != tt.timeSizeOptimizingRotation {
+			t.Errorf("%s: expected time size optimizing rotation and field types %v, got %v and %v", tt.version,
+				tt.timeSizeOptimizingRotation, capabilities.TimeSizeOptimizingRotation, capabilities.FieldTypes)
+		}
+	}
+}
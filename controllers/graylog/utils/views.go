@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	graylogClient "github.com/Netcracker/qubership-logging-operator/controllers/graylog/client"
+)
+
+// viewSearch is the search from the spec of the GraylogView. Only the filters of the queries are changed by the operator
+type viewSearch struct {
+	Queries    []map[string]interface{} `json:"queries"`
+	Parameters json.RawMessage          `json:"parameters,omitempty"`
+}
+
+// ManageView creates or updates the view of the GraylogView in Graylog and compares the view in Graylog
+// with the view applied by the operator. The changes made in Graylog are reverted or only reported
+// by the drift policy, the view deleted in Graylog is drifted too.
+// The ids of the view and its search are saved in the status. It returns true if the view is drifted
+func (connector *GraylogConnector) ManageView(view *loggingService.GraylogView) (bool, error) {
+	status := &view.Status
+	var current *graylogClient.View
+	if status.ViewId != "" {
+		existing, err := connector.Client.GetView(connector.context(), status.ViewId)
+		if err != nil && !graylogClient.IsNotFound(err) {
+			return false, err
+		}
+		current = existing
+	}
+
+	drifted := false
+	if status.ViewId != "" && status.ObservedGeneration == view.GetGeneration() {
+		hash := ""
+		if current != nil {
+			var err error
+			if hash, err = connector.viewHash(current); err != nil {
+				return false, err
+			}
+		}
+		if hash == status.AppliedHash {
+			return false, nil
+		}
+		drifted = true
+		if view.IsReportDrift() {
+			return true, nil
+		}
+	}
+
+	search, err := connector.createViewSearch(view)
+	if err != nil {
+		return drifted, err
+	}
+	state := json.RawMessage(view.Spec.State.Raw)
+	if len(state) == 0 {
+		state = json.RawMessage("{}")
+	}
+	body := graylogClient.View{
+		Type:        view.Spec.Type,
+		Title:       view.Spec.Title,
+		Summary:     view.Spec.Summary,
+		Description: view.Spec.Description,
+		SearchId:    search.Id,
+		State:       state,
+	}
+	// The previous search of the view is deleted by the cleanup job of Graylog
+	if current == nil {
+		created, err := connector.Client.CreateView(connector.context(), body)
+		if err != nil {
+			return drifted, fmt.Errorf("can't create view %s: %w", view.Spec.Title, err)
+		}
+		status.ViewId = created.Id
+		connector.recordCreated("view", view.Spec.Title)
+	} else {
+		body.Id = current.Id
+		if _, err = connector.Client.UpdateView(connector.context(), current.Id, body); err != nil {
+			return drifted, fmt.Errorf("can't update view %s: %w", view.Spec.Title, err)
+		}
+		connector.recordUpdated("view", view.Spec.Title)
+	}
+
+	// The hash is calculated by the view read from Graylog, because Graylog adds the defaults to the state
+	applied, err := connector.Client.GetView(connector.context(), status.ViewId)
+	if err != nil {
+		return drifted, err
+	}
+	if status.AppliedHash, err = connector.viewHash(applied); err != nil {
+		return drifted, err
+	}
+	status.SearchId = applied.SearchId
+	status.ObservedGeneration = view.GetGeneration()
+	return drifted, nil
+}
+
+// DeleteView deletes the view of the GraylogView from Graylog
+func (connector *GraylogConnector) DeleteView(view *loggingService.GraylogView) error {
+	if view.Status.ViewId == "" {
+		return nil
+	}
+	if err := connector.Client.DeleteView(connector.context(), view.Status.ViewId); err != nil && !graylogClient.IsNotFound(err) {
+		return err
+	}
+	connector.Log.Info(fmt.Sprintf("View %s is deleted from Graylog", view.Spec.Title))
+	return nil
+}
+
+// createViewSearch creates the search of the view. The queries search the streams of the view by their ids
+func (connector *GraylogConnector) createViewSearch(view *loggingService.GraylogView) (*graylogClient.Search, error) {
+	var search viewSearch
+	if err := json.Unmarshal(view.Spec.Search.Raw, &search); err != nil {
+		return nil, fmt.Errorf("can't parse search of view %s: %w", view.Spec.Title, err)
+	}
+	if len(search.Queries) == 0 {
+		return nil, fmt.Errorf("search of view %s has no queries", view.Spec.Title)
+	}
+
+	if len(view.Spec.Streams) > 0 {
+		streams, err := connector.GetAllStreams()
+		if err != nil {
+			return nil, err
+		}
+		filters := make([]interface{}, 0, len(view.Spec.Streams))
+		for _, title := range view.Spec.Streams {
+			id := GetIdByTitle(streams, title)
+			if id == "" {
+				return nil, fmt.Errorf("stream %s of view %s is not found", title, view.Spec.Title)
+			}
+			filters = append(filters, map[string]interface{}{"type": "stream", "id": id})
+		}
+		for _, query := range search.Queries {
+			query["filter"] = map[string]interface{}{"type": "or", "filters": filters}
+		}
+	}
+
+	queries, err := json.Marshal(search.Queries)
+	if err != nil {
+		return nil, err
+	}
+	created, err := connector.Client.CreateSearch(connector.context(), graylogClient.Search{Queries: queries, Parameters: search.Parameters})
+	if err != nil {
+		return nil, fmt.Errorf("can't create search of view %s: %w", view.Spec.Title, err)
+	}
+	return created, nil
+}
+
+// viewHash returns the hash of the content of the view and its search which can be changed in Graylog.
+// The hash of the view without the search is empty, so it differs from the applied one
+func (connector *GraylogConnector) viewHash(view *graylogClient.View) (string, error) {
+	search, err := connector.Client.GetSearch(connector.context(), view.SearchId)
+	if graylogClient.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	// The JSON fields are decoded and encoded again, so the keys of the objects are sorted
+	content := map[string]interface{}{
+		"type":        view.Type,
+		"title":       view.Title,
+		"summary":     view.Summary,
+		"description": view.Description,
+	}
+	for field, value := range map[string]json.RawMessage{"state": view.State, "queries": search.Queries, "parameters": search.Parameters} {
+		if len(value) == 0 {
+			continue
+		}
+		var decoded interface{}
+		if err = json.Unmarshal(value, &decoded); err != nil {
+			return "", err
+		}
+		content[field] = decoded
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
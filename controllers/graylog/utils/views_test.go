@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	testViewSearch = `{"queries":[{"id":"q1","query":{"type":"elasticsearch","query_string":"level:3"},"timerange":{"type":"relative","range":300},"search_types":[]}]}`
+	testViewState  = `{"q1":{"widgets":[],"positions":{}}}`
+)
+
+var manageViewTests = []struct {
+	description string
+	spec        loggingService.GraylogViewSpec
+	// change is run after the view is applied, then the view is managed again
+	change func(graylog *fakeGraylog, view *loggingService.GraylogView)
+	// filters are the ids of the streams in the filter of the query after the first run
+	filters []string
+	// graylog are the requests which change objects in the second run
+	graylog map[string]int
+	drifted bool
+	title   string
+	isError bool
+}{
+	{
+		description: "view is created with the stream filter and not changed again",
+		spec:        testViewSpec("All events", "All system events"),
+		filters:     []string{allEventsStreamId, "000000000000000000000003"},
+		title:       "Errors",
+	},
+	{
+		description: "changes of the view in Graylog are reverted",
+		spec:        testViewSpec(),
+		change: func(graylog *fakeGraylog, view *loggingService.GraylogView) {
+			setFakeField(graylog, "views", view.Status.ViewId, "title", "Errors changed in UI")
+		},
+		graylog: map[string]int{"POST views/search": 1, "PUT views/{id}": 1},
+		drifted: true,
+		title:   "Errors",
+	},
+	{
+		description: "changes of the search in Graylog are reverted",
+		spec:        testViewSpec(),
+		change: func(graylog *fakeGraylog, view *loggingService.GraylogView) {
+			setFakeField(graylog, "views/search", view.Status.SearchId, "queries", []interface{}{})
+		},
+		graylog: map[string]int{"POST views/search": 1, "PUT views/{id}": 1},
+		drifted: true,
+		title:   "Errors",
+	},
+	{
+		description: "changes of the view in Graylog are reported",
+		spec:        withDriftPolicy(testViewSpec(), loggingService.DriftPolicyReport),
+		change: func(graylog *fakeGraylog, view *loggingService.GraylogView) {
+			setFakeField(graylog, "views", view.Status.ViewId, "title", "Errors changed in UI")
+		},
+		drifted: true,
+		title:   "Errors changed in UI",
+	},
+	{
+		description: "view deleted in Graylog is created again",
+		spec:        testViewSpec(),
+		change: func(graylog *fakeGraylog, view *loggingService.GraylogView) {
+			graylog.mu.Lock()
+			defer graylog.mu.Unlock()
+			graylog.collections["views"] = nil
+		},
+		graylog: map[string]int{"POST views/search": 1, "POST views": 1},
+		drifted: true,
+		title:   "Errors",
+	},
+	{
+		description: "changed spec is applied with the report policy",
+		spec:        withDriftPolicy(testViewSpec(), loggingService.DriftPolicyReport),
+		change: func(graylog *fakeGraylog, view *loggingService.GraylogView) {
+			view.Spec.Title = "Errors of the day"
+			view.Generation++
+		},
+		graylog: map[string]int{"POST views/search": 1, "PUT views/{id}": 1},
+		title:   "Errors of the day",
+	},
+	{
+		description: "unknown stream",
+		spec:        testViewSpec("Unknown stream"),
+		isError:     true,
+	},
+	{
+		description: "search without queries",
+		spec: loggingService.GraylogViewSpec{
+			Type:   loggingService.GraylogViewTypeSearch,
+			Title:  "Errors",
+			Search: runtime.RawExtension{Raw: []byte(`{"queries":[]}`)},
+		},
+		isError: true,
+	},
+}
+
+func Test_ManageView(t *testing.T) {
+	for _, tt := range manageViewTests {
+		t.Run(tt.description, func(t *testing.T) {
+			graylog := newFakeGraylog(t, "6.0.0")
+			seedDefaultObjects(graylog)
+			cr := &loggingService.LoggingService{Spec: loggingService.LoggingServiceSpec{Graylog: &loggingService.Graylog{}}}
+			connector := newTestConnector(t, cr, graylog, newFakeOpenSearch(t, "opensearch"))
+
+			view := &loggingService.GraylogView{
+				ObjectMeta: metav1.ObjectMeta{Name: "errors", Namespace: testNamespace, Generation: 1},
+				Spec:       *tt.spec.DeepCopy(),
+			}
+			drifted, err := connector.ManageView(view)
+			if (err != nil) != tt.isError {
+				t.Fatalf("expected error: %v, got: %v", tt.isError, err)
+			}
+			if tt.isError {
+				return
+			}
+			if drifted {
+				t.Fatalf("created view is drifted")
+			}
+			if view.Status.ViewId == "" || view.Status.SearchId == "" || view.Status.AppliedHash == "" {
+				t.Fatalf("status of the view is not set: %+v", view.Status)
+			}
+			if tt.filters != nil {
+				if filters := fakeSearchFilters(t, graylog, view.Status.SearchId); !reflect.DeepEqual(filters, tt.filters) {
+					t.Errorf("expected stream filters %v, got %v", tt.filters, filters)
+				}
+			}
+
+			if tt.change != nil {
+				tt.change(graylog, view)
+			}
+			graylog.requests = nil
+			if drifted, err = connector.ManageView(view); err != nil {
+				t.Fatal(err)
+			}
+			if drifted != tt.drifted {
+				t.Errorf("expected drifted: %v, got: %v", tt.drifted, drifted)
+			}
+			if changes := graylog.changes(); !equalChanges(changes, tt.graylog) {
+				t.Errorf("expected Graylog requests %v, got %v", tt.graylog, changes)
+			}
+			if titles := graylog.titles("views"); !reflect.DeepEqual(titles, []string{tt.title}) {
+				t.Errorf("expected views %v, got %v", []string{tt.title}, titles)
+			}
+		})
+	}
+}
+
+func testViewSpec(streams ...string) loggingService.GraylogViewSpec {
+	return loggingService.GraylogViewSpec{
+		Type:    loggingService.GraylogViewTypeSearch,
+		Title:   "Errors",
+		Streams: streams,
+		Search:  runtime.RawExtension{Raw: []byte(testViewSearch)},
+		State:   runtime.RawExtension{Raw: []byte(testViewState)},
+	}
+}
+
+func withDriftPolicy(spec loggingService.GraylogViewSpec, policy string) loggingService.GraylogViewSpec {
+	spec.DriftPolicy = policy
+	return spec
+}
+
+// setFakeField changes the field of the object in Graylog as it's done in the UI
+func setFakeField(graylog *fakeGraylog, path string, id string, field string, value interface{}) {
+	graylog.mu.Lock()
+	defer graylog.mu.Unlock()
+	index := graylog.find(path, "id", id)
+	if index < 0 {
+		graylog.t.Fatalf("object %s is not found in %s", id, path)
+	}
+	graylog.collections[path][index][field] = value
+}
+
+// fakeSearchFilters returns the ids of the streams in the filters of the queries of the search
+func fakeSearchFilters(t *testing.T, graylog *fakeGraylog, id string) []string {
+	graylog.mu.Lock()
+	defer graylog.mu.Unlock()
+	index := graylog.find("views/search", "id", id)
+	if index < 0 {
+		t.Fatalf("search %s is not found", id)
+	}
+	data, err := json.Marshal(graylog.collections["views/search"][index]["queries"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries []struct {
+		Filter struct {
+			Filters []struct {
+				Id string `json:"id"`
+			} `json:"filters"`
+		} `json:"filter"`
+	}
+	if err = json.Unmarshal(data, &queries); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, query := range queries {
+		for _, filter := range query.Filter.Filters {
+			ids = append(ids, filter.Id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	"github.com/Netcracker/qubership-logging-operator/controllers/graylog"
+	graylogUtils "github.com/Netcracker/qubership-logging-operator/controllers/graylog/utils"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// GraylogViewReconciler applies the dashboards and the saved searches of GraylogView resources
+// to Graylog of the LoggingService in the same namespace and checks them for the changes made in Graylog
+type GraylogViewReconciler struct {
+	Scheme *runtime.Scheme
+	Client client.Client
+	// ClientSet reads the Secrets of Graylog, it is created from the config of the manager if it's not set
+	ClientSet kubernetes.Interface
+	Log       logr.Logger
+	Recorder  record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=logging.qubership.org,resources=graylogviews,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=logging.qubership.org,resources=graylogviews/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=logging.qubership.org,resources=graylogviews/finalizers,verbs=update
+
+// Reconcile creates or updates the view in Graylog and requeues the GraylogView to detect the drift of the view
+func (r *GraylogViewReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	view := &loggingService.GraylogView{}
+	if err := r.Client.Get(ctx, request.NamespacedName, view); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	eventRecorder := util.NewEventRecorder(r.Recorder, view)
+
+	if !view.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, r.reconcileDeletion(ctx, view, eventRecorder)
+	}
+	if controllerutil.AddFinalizer(view, util.GraylogViewFinalizer) {
+		if err := r.Client.Update(ctx, view); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	original := view.DeepCopy()
+	cr, err := r.findLoggingService(ctx, view.GetNamespace())
+	switch {
+	case err != nil:
+		return ctrl.Result{}, err
+	case cr == nil:
+		setViewCondition(view, loggingService.GraylogViewReady, false, "GraylogNotFound",
+			"LoggingService with Graylog is not found in the namespace "+view.GetNamespace())
+		return ctrl.Result{RequeueAfter: view.GetDriftCheckInterval()}, r.patchStatus(ctx, view, original)
+	case cr.IsPaused(loggingService.PausedGraylogContent):
+		r.Log.Info(fmt.Sprintf("Management of Graylog content is paused by the annotation %s, skip view %s", loggingService.PausedAnnotation, view.GetName()))
+		return ctrl.Result{RequeueAfter: view.GetDriftCheckInterval()}, nil
+	}
+
+	drifted, err := r.manageView(ctx, cr, view, eventRecorder)
+	if err != nil {
+		r.Log.Error(err, "Can not apply GraylogView "+view.GetName())
+		eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Can not apply view %s: %s", view.Spec.Title, err.Error()))
+		setViewCondition(view, loggingService.GraylogViewReady, false, "ApplyFailed", err.Error())
+		if patchErr := r.patchStatus(ctx, view, original); patchErr != nil {
+			r.Log.Error(patchErr, "Can not update status of GraylogView "+view.GetName())
+		}
+		return ctrl.Result{}, err
+	}
+
+	setViewCondition(view, loggingService.GraylogViewReady, true, "Applied", fmt.Sprintf("View %s is applied to Graylog", view.Spec.Title))
+	switch {
+	case drifted && view.IsReportDrift():
+		message := fmt.Sprintf("View %s is changed in Graylog, the changes are kept by the drift policy %s", view.Spec.Title, view.Spec.DriftPolicy)
+		if !meta.IsStatusConditionTrue(view.Status.Conditions, loggingService.GraylogViewDrifted) {
+			view.Status.LastDriftTime = &metav1.Time{Time: time.Now()}
+			eventRecorder.Warning(util.ReasonGraylogViewDrifted, message)
+		}
+		setViewCondition(view, loggingService.GraylogViewDrifted, true, "DriftDetected", message)
+	case drifted:
+		view.Status.LastDriftTime = &metav1.Time{Time: time.Now()}
+		eventRecorder.Normal(util.ReasonGraylogViewReverted, fmt.Sprintf("Changes of view %s in Graylog are reverted", view.Spec.Title))
+		setViewCondition(view, loggingService.GraylogViewDrifted, false, "DriftReverted", "Changes of the view in Graylog are reverted")
+	default:
+		setViewCondition(view, loggingService.GraylogViewDrifted, false, "InSync", "View in Graylog matches the applied view")
+	}
+	return ctrl.Result{RequeueAfter: view.GetDriftCheckInterval()}, r.patchStatus(ctx, view, original)
+}
+
+// manageView applies the view to Graylog of the LoggingService and returns true if the view is drifted
+func (r *GraylogViewReconciler) manageView(ctx context.Context, cr *loggingService.LoggingService, view *loggingService.GraylogView, eventRecorder util.EventRecorder) (bool, error) {
+	connector, err := r.createConnector(ctx, cr, eventRecorder)
+	if err != nil {
+		return false, err
+	}
+	return connector.ManageView(view)
+}
+
+// reconcileDeletion deletes the view from Graylog and removes the finalizer. The view is kept
+// if the LoggingService is deleted, because Graylog is uninstalled or its content is retained
+func (r *GraylogViewReconciler) reconcileDeletion(ctx context.Context, view *loggingService.GraylogView, eventRecorder util.EventRecorder) error {
+	if !controllerutil.ContainsFinalizer(view, util.GraylogViewFinalizer) {
+		return nil
+	}
+	cr, err := r.findLoggingService(ctx, view.GetNamespace())
+	if err != nil {
+		return err
+	}
+	if cr != nil && cr.GetDeletionTimestamp().IsZero() {
+		connector, err := r.createConnector(ctx, cr, eventRecorder)
+		if err != nil {
+			return err
+		}
+		if err = connector.DeleteView(view); err != nil {
+			eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Can not delete view %s: %s", view.Spec.Title, err.Error()))
+			return err
+		}
+	}
+	controllerutil.RemoveFinalizer(view, util.GraylogViewFinalizer)
+	return r.Client.Update(ctx, view)
+}
+
+func (r *GraylogViewReconciler) createConnector(ctx context.Context, cr *loggingService.LoggingService, eventRecorder util.EventRecorder) (*graylogUtils.GraylogConnector, error) {
+	graylogReconciler := graylog.NewGraylogReconciler(r.Client, r.Scheme, util.NewStatusUpdater(r.Client, cr), eventRecorder)
+	return graylogReconciler.CreateContentConnector(ctx, cr, r.ClientSet)
+}
+
+// findLoggingService returns the LoggingService which installs Graylog in the namespace
+func (r *GraylogViewReconciler) findLoggingService(ctx context.Context, namespace string) (*loggingService.LoggingService, error) {
+	list := &loggingService.LoggingServiceList{}
+	if err := r.Client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if list.Items[i].Spec.Graylog != nil && list.Items[i].Spec.Graylog.IsInstall() {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *GraylogViewReconciler) patchStatus(ctx context.Context, view *loggingService.GraylogView, original *loggingService.GraylogView) error {
+	return r.Client.Status().Patch(ctx, view, client.MergeFrom(original))
+}
+
+func setViewCondition(view *loggingService.GraylogView, conditionType string, status bool, reason string, message string) {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: view.GetGeneration(),
+	}
+	if status {
+		condition.Status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&view.Status.Conditions, condition)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// The updates of the status don't trigger the reconciliation, the drift is checked by the requeue
+func (r *GraylogViewReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.ClientSet == nil {
+		clientSet, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+		r.ClientSet = clientSet
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingService.GraylogView{}, builder.WithPredicates(graylogViewPredicate())).
+		Complete(r)
+}
+
+func graylogViewPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+				e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+	}
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var graylogViewTests = []struct {
+	description string
+	view        *loggingService.GraylogView
+	// objects are other objects in the namespace
+	objects      []client.Object
+	requeueAfter time.Duration
+	// readyReason is the reason of the Ready condition, the view is expected to be deleted if it's empty
+	readyReason string
+}{
+	{
+		description:  "View without LoggingService gets the finalizer and is requeued",
+		view:         graylogView(nil, nil),
+		requeueAfter: loggingService.DefaultDriftCheckInterval,
+		readyReason:  "GraylogNotFound",
+	},
+	{
+		description: "View is not applied to LoggingService without Graylog",
+		view:        graylogView(&metav1.Duration{Duration: time.Minute}, nil),
+		objects: []client.Object{&loggingService.LoggingService{
+			ObjectMeta: metav1.ObjectMeta{Name: "logging-service", Namespace: "logging"},
+		}},
+		requeueAfter: time.Minute,
+		readyReason:  "GraylogNotFound",
+	},
+	{
+		description: "Deleted view is released without LoggingService",
+		view:        graylogView(nil, &metav1.Time{Time: time.Now()}),
+	},
+}
+
+func Test_GraylogViewReconcile(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	if err := loggingService.AddToScheme(testScheme); err != nil {
+		t.Error("can't add test schema in arrays of schemas")
+	}
+	for _, tt := range graylogViewTests {
+		t.Run(tt.description, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(tt.view).
+				WithObjects(tt.objects...).
+				WithStatusSubresource(tt.view).
+				Build()
+			reconciler := &GraylogViewReconciler{
+				Client:    fakeClient,
+				Scheme:    testScheme,
+				ClientSet: k8sfake.NewSimpleClientset(),
+				Log:       util.Logger("test"),
+			}
+
+			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tt.view)})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.RequeueAfter != tt.requeueAfter {
+				t.Errorf("expected requeue after %s, got %s", tt.requeueAfter, result.RequeueAfter)
+			}
+
+			view := &loggingService.GraylogView{}
+			err = fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(tt.view), view)
+			if tt.readyReason == "" {
+				if !errors.IsNotFound(err) {
+					t.Errorf("expected GraylogView to be deleted after removing of the finalizer, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(view.Finalizers, []string{util.GraylogViewFinalizer}) {
+				t.Errorf("expected finalizer %s, got %v", util.GraylogViewFinalizer, view.Finalizers)
+			}
+			condition := meta.FindStatusCondition(view.Status.Conditions, loggingService.GraylogViewReady)
+			if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != tt.readyReason {
+				t.Errorf("expected Ready condition with reason %s, got %+v", tt.readyReason, condition)
+			}
+		})
+	}
+}
+
+func graylogView(driftCheckInterval *metav1.Duration, deletionTimestamp *metav1.Time) *loggingService.GraylogView {
+	view := &loggingService.GraylogView{
+		ObjectMeta: metav1.ObjectMeta{Name: "errors", Namespace: "logging", DeletionTimestamp: deletionTimestamp},
+		Spec: loggingService.GraylogViewSpec{
+			Type:               loggingService.GraylogViewTypeSearch,
+			Title:              "Errors",
+			DriftCheckInterval: driftCheckInterval,
+		},
+	}
+	if deletionTimestamp != nil {
+		view.Finalizers = []string{util.GraylogViewFinalizer}
+	}
+	return view
+}
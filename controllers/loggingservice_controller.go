@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -16,12 +17,16 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -38,12 +43,14 @@ type LoggingServiceReconciler struct {
 	Client                   client.Client
 	Log                      logr.Logger
 	StatusUpdater            util.StatusUpdater
+	Recorder                 record.EventRecorder
 	DynamicParameters        util.DynamicParameters
 }
 
 // +kubebuilder:rbac:groups=logging.qubership.org,resources=loggingservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=logging.qubership.org,resources=loggingservices/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=logging.qubership.org,resources=loggingservices/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -59,7 +66,7 @@ func (r *LoggingServiceReconciler) Reconcile(context context.Context, request ct
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Owned objects are automatically garbage collected. Additional cleanup is done by the finalizer.
 			// Return and don't requeue
 			return reconcile.Result{Requeue: false}, nil
 		}
@@ -67,8 +74,19 @@ func (r *LoggingServiceReconciler) Reconcile(context context.Context, request ct
 		return reconcile.Result{}, err
 	}
 	r.StatusUpdater = util.NewStatusUpdater(r.Client, customResourceInstance)
+	eventRecorder := util.NewEventRecorder(r.Recorder, customResourceInstance)
 	clientSet := kubernetes.NewForConfigOrDie(r.Config)
-	if !r.ReconcileLoggingServiceCluster(context, customResourceInstance, clientSet) {
+
+	if !customResourceInstance.GetDeletionTimestamp().IsZero() {
+		return r.reconcileDeletion(context, customResourceInstance, clientSet, eventRecorder)
+	}
+	if err = r.ensureFinalizer(context, customResourceInstance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	isReconcileSuccess := r.ReconcileLoggingServiceCluster(context, customResourceInstance, clientSet, eventRecorder)
+	util.ObserveReconcile(util.LoggingServiceComponentName, initialTime, isReconcileSuccess)
+	if !isReconcileSuccess {
 		var reconcileTime = time.Since(initialTime)
 		r.Log.V(util.Error).Info(fmt.Sprintf("Reconcile of Logging Service was failed with error in %s. Next reconcile cycle after %s",
 			util.ToString(reconcileTime), r.TimeoutOnFailedReconcile.String()))
@@ -82,15 +100,30 @@ func (r *LoggingServiceReconciler) Reconcile(context context.Context, request ct
 
 	var reconcileTime = time.Since(initialTime)
 
-	r.StatusUpdater.UpdateStatus(util.LoggingServiceStatus, util.Success, true, "Reconcile of Logging service succeeded")
+	if customResourceInstance.IsPausedAll() {
+		r.StatusUpdater.UpdateStatus(util.LoggingServiceStatus, util.Paused, true, fmt.Sprintf("Reconcile of Logging service is paused by the annotation %s", loggingService.PausedAnnotation))
+	} else {
+		r.StatusUpdater.UpdateStatus(util.LoggingServiceStatus, util.Success, true, "Reconcile of Logging service succeeded")
+	}
 	r.TimeoutOnFailedReconcile = InitialTimeoutOnFailedReconcile
 
 	r.Log.Info(fmt.Sprintf("Reconcile a cycle of Logging Service successfully finished in %s", util.ToString(reconcileTime)))
 
-	return ctrl.Result{}, nil
+	var requeueAfter time.Duration
+	if customResourceInstance.GetAnnotations()[loggingService.PausedAnnotation] != "" {
+		// Requeue to refresh the health of the paused components in the status
+		requeueAfter = util.PausedHealthCheckInterval
+	}
+	if graylog := customResourceInstance.Spec.Graylog; graylog != nil && graylog.UsesOCI() {
+		// The OCI artifacts are not watched, so their tags are checked for the new pushes by the requeue
+		if interval := graylog.GetOCIPollInterval(); requeueAfter == 0 || interval < requeueAfter {
+			requeueAfter = interval
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (r *LoggingServiceReconciler) ReconcileLoggingServiceCluster(ctx context.Context, customResourceInstance *loggingService.LoggingService, clientSet kubernetes.Interface) bool {
+func (r *LoggingServiceReconciler) ReconcileLoggingServiceCluster(ctx context.Context, customResourceInstance *loggingService.LoggingService, clientSet kubernetes.Interface, eventRecorder util.EventRecorder) bool {
 	//If operator reconcile failed, then we keep failed status for all following reconcile cycles, before first success
 	if !r.StatusUpdater.IsStatusFailed(util.LoggingServiceStatus) {
 		r.StatusUpdater.UpdateStatus(util.LoggingServiceStatus, util.InProgress, false, "Logging Service reconcile cycle in progress")
@@ -102,47 +135,79 @@ func (r *LoggingServiceReconciler) ReconcileLoggingServiceCluster(ctx context.Co
 
 	var isDeployServiceSuccess = true
 	var pendingComponents []util.Component
+	var err error
 
-	graylogReconciler := graylog.NewGraylogReconciler(r.Client, r.Scheme, r.StatusUpdater)
-	if err := graylogReconciler.Run(ctx, customResourceInstance, clientSet); err != nil {
-		isDeployServiceSuccess = false
-		r.Log.Error(err, "Deploy of Graylog is failed")
-		graylogReconciler.StatusUpdater.UpdateStatus(util.GraylogStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+	if !r.isComponentPaused(customResourceInstance, loggingService.PausedGraylog) {
+		graylogReconciler := graylog.NewGraylogReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder)
+		graylogStart := time.Now()
+		err = graylogReconciler.Run(ctx, customResourceInstance, clientSet)
+		util.ObserveReconcile(util.GraylogComponentName, graylogStart, err == nil)
+		if err != nil {
+			isDeployServiceSuccess = false
+			r.Log.Error(err, "Deploy of Graylog is failed")
+			eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Deploy of Graylog is failed: %s", err.Error()))
+			graylogReconciler.StatusUpdater.UpdateStatus(util.GraylogStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+		}
 	}
 
-	fluentdReconciler := fluentd.NewFluentdReconciler(r.Client, r.Scheme, r.StatusUpdater, &pendingComponents, r.DynamicParameters)
-	if err := fluentdReconciler.Run(customResourceInstance); err != nil {
-		isDeployServiceSuccess = false
-		r.Log.Error(err, "Deploy of Fluentd is failed")
-		fluentdReconciler.StatusUpdater.UpdateStatus(util.FluentdStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+	if !r.isComponentPaused(customResourceInstance, loggingService.PausedFluentd) {
+		fluentdReconciler := fluentd.NewFluentdReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents, r.DynamicParameters)
+		fluentdStart := time.Now()
+		err = fluentdReconciler.Run(customResourceInstance)
+		util.ObserveReconcile(util.FluentdComponentName, fluentdStart, err == nil)
+		if err != nil {
+			isDeployServiceSuccess = false
+			r.Log.Error(err, "Deploy of Fluentd is failed")
+			eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Deploy of Fluentd is failed: %s", err.Error()))
+			fluentdReconciler.StatusUpdater.UpdateStatus(util.FluentdStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+		}
 	}
 
-	fluentbitReconciler := fluentbit.NewFluentbitReconciler(r.Client, r.Scheme, r.StatusUpdater, &pendingComponents, r.DynamicParameters)
-	if err := fluentbitReconciler.Run(customResourceInstance); err != nil {
-		isDeployServiceSuccess = false
-		r.Log.Error(err, "Deploy of Fluentbit is failed")
-		fluentbitReconciler.StatusUpdater.UpdateStatus(util.FluentbitStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+	if !r.isComponentPaused(customResourceInstance, loggingService.PausedFluentbit) {
+		fluentbitReconciler := fluentbit.NewFluentbitReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents, r.DynamicParameters)
+		fluentbitStart := time.Now()
+		err = fluentbitReconciler.Run(customResourceInstance)
+		util.ObserveReconcile(util.FluentbitComponentName, fluentbitStart, err == nil)
+		if err != nil {
+			isDeployServiceSuccess = false
+			r.Log.Error(err, "Deploy of Fluentbit is failed")
+			eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Deploy of Fluentbit is failed: %s", err.Error()))
+			fluentbitReconciler.StatusUpdater.UpdateStatus(util.FluentbitStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+		}
 	}
 
-	fluentsReconciler := fluentbit_forwarder_aggregator.NewHAFluentReconciler(r.Client, r.Scheme, r.StatusUpdater, &pendingComponents, r.DynamicParameters)
-	if err := fluentsReconciler.Run(customResourceInstance); err != nil {
-		isDeployServiceSuccess = false
-		r.Log.Error(err, "Deploy of Fluentbit forwarder-aggregator is failed")
-		fluentsReconciler.StatusUpdater.UpdateStatus(util.FluentbitStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+	if !r.isComponentPaused(customResourceInstance, loggingService.PausedFluentbitAggregator) {
+		fluentsReconciler := fluentbit_forwarder_aggregator.NewHAFluentReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents, r.DynamicParameters)
+		fluentsStart := time.Now()
+		err = fluentsReconciler.Run(customResourceInstance)
+		util.ObserveReconcile(util.ForwarderFluentbitComponentName, fluentsStart, err == nil)
+		if err != nil {
+			isDeployServiceSuccess = false
+			r.Log.Error(err, "Deploy of Fluentbit forwarder-aggregator is failed")
+			eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Deploy of Fluentbit forwarder-aggregator is failed: %s", err.Error()))
+			fluentsReconciler.StatusUpdater.UpdateStatus(util.FluentbitStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+		}
 	}
 
-	eventsReaderReconciler := events_reader.NewEventsReaderReconciler(r.Client, r.Scheme, r.StatusUpdater, &pendingComponents)
-	if err := eventsReaderReconciler.Run(customResourceInstance); err != nil {
-		isDeployServiceSuccess = false
-		r.Log.Error(err, "Deploy of Cloud Events Reader is failed")
-		eventsReaderReconciler.StatusUpdater.UpdateStatus(util.EventsReaderStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+	if !r.isComponentPaused(customResourceInstance, loggingService.PausedEventsReader) {
+		eventsReaderReconciler := events_reader.NewEventsReaderReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents)
+		eventsReaderStart := time.Now()
+		err = eventsReaderReconciler.Run(customResourceInstance)
+		util.ObserveReconcile(util.EventsReaderComponentName, eventsReaderStart, err == nil)
+		if err != nil {
+			isDeployServiceSuccess = false
+			r.Log.Error(err, "Deploy of Cloud Events Reader is failed")
+			eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Deploy of Cloud Events Reader is failed: %s", err.Error()))
+			eventsReaderReconciler.StatusUpdater.UpdateStatus(util.EventsReaderStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
+		}
 	}
 
-	statusReconciler := util.NewComponentsPendingReconciler(r.Client, r.Scheme, r.StatusUpdater, &pendingComponents)
+	statusReconciler := util.NewComponentsPendingReconciler(r.Client, r.Scheme, r.StatusUpdater, eventRecorder, &pendingComponents)
 	status, err := statusReconciler.Run(customResourceInstance)
 	if err != nil {
 		isDeployServiceSuccess = false
 		r.Log.Error(err, "Failed waiting for component statuses")
+		eventRecorder.Warning(util.ReasonReconcileFailed, fmt.Sprintf("Failed waiting for component statuses: %s", err.Error()))
 		statusReconciler.StatusUpdater.UpdateStatus(util.ComponentPendingStatus, util.Failed, false, fmt.Sprintf("Reason: %s", err.Error()))
 	} else if !status {
 		isDeployServiceSuccess = false
@@ -189,18 +254,114 @@ func (r *LoggingServiceReconciler) updateDynamicParameters(customResourceInstanc
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Changes of the Graylog Secret with credentials trigger the reconciliation of the LoggingService which uses it.
 func (r *LoggingServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	secretPredicate, err := graylogSecretPredicate()
+	if err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&loggingService.LoggingService{}).
-		WithEventFilter(ignoreDeletionPredicate()).
+		For(&loggingService.LoggingService{}, builder.WithPredicates(ignoreDeletionPredicate())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.graylogSecretRequests), builder.WithPredicates(secretPredicate)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.contentSourceRequests), builder.WithPredicates(contentSourcePredicate())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.contentSourceRequests), builder.WithPredicates(contentSourcePredicate())).
 		Complete(r)
 }
 
+// graylogSecretPredicate selects the Secrets by the label util.GraylogSecretSelector
+// and ignores updates which don't change the data of the Secret
+func graylogSecretPredicate() (predicate.Predicate, error) {
+	selector, err := metav1.ParseToLabelSelector(util.GraylogSecretSelector)
+	if err != nil {
+		return nil, err
+	}
+	labelPredicate, err := predicate.LabelSelectorPredicate(*selector)
+	if err != nil {
+		return nil, err
+	}
+	return predicate.And(labelPredicate, predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSecret, okOld := e.ObjectOld.(*corev1.Secret)
+			newSecret, okNew := e.ObjectNew.(*corev1.Secret)
+			return !okOld || !okNew || !reflect.DeepEqual(oldSecret.Data, newSecret.Data)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			// Graylog keeps running with the last credentials
+			return false
+		},
+	}), nil
+}
+
+// graylogSecretRequests returns the requests for the LoggingServices which use the Secret as Graylog credentials
+func (r *LoggingServiceReconciler) graylogSecretRequests(ctx context.Context, secret client.Object) []reconcile.Request {
+	list := &loggingService.LoggingServiceList{}
+	if err := r.Client.List(ctx, list, client.InNamespace(secret.GetNamespace())); err != nil {
+		r.Log.Error(err, "Can not list LoggingServices for the Secret", "secret", secret.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cr := range list.Items {
+		if cr.Spec.Graylog != nil && cr.Spec.Graylog.GraylogSecretName == secret.GetName() {
+			r.Log.Info(fmt.Sprintf("Secret %s with Graylog credentials is changed, reconcile LoggingService %s", secret.GetName(), cr.GetName()))
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)})
+		}
+	}
+	return requests
+}
+
+// contentSourcePredicate ignores updates of the ConfigMaps and the Secrets which don't change their data
+// and the deletions, because the installed content is kept in Graylog
+func contentSourcePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			switch oldObject := e.ObjectOld.(type) {
+			case *corev1.ConfigMap:
+				newObject, ok := e.ObjectNew.(*corev1.ConfigMap)
+				return !ok || !reflect.DeepEqual(oldObject.Data, newObject.Data) || !reflect.DeepEqual(oldObject.BinaryData, newObject.BinaryData)
+			case *corev1.Secret:
+				newObject, ok := e.ObjectNew.(*corev1.Secret)
+				return !ok || !reflect.DeepEqual(oldObject.Data, newObject.Data)
+			}
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+	}
+}
+
+// contentSourceRequests returns the requests for the LoggingServices which read content packs
+// or saved searches from the ConfigMap or the Secret
+func (r *LoggingServiceReconciler) contentSourceRequests(ctx context.Context, object client.Object) []reconcile.Request {
+	kind := "ConfigMap"
+	if _, ok := object.(*corev1.Secret); ok {
+		kind = "Secret"
+	}
+	list := &loggingService.LoggingServiceList{}
+	if err := r.Client.List(ctx, list, client.InNamespace(object.GetNamespace())); err != nil {
+		r.Log.Error(err, "Can not list LoggingServices for the "+kind, "name", object.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cr := range list.Items {
+		if cr.Spec.Graylog != nil && cr.Spec.Graylog.UsesContentObject(kind, object.GetName()) {
+			r.Log.Info(fmt.Sprintf("%s %s with Graylog content is changed, reconcile LoggingService %s", kind, object.GetName(), cr.GetName()))
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)})
+		}
+	}
+	return requests
+}
+
 func ignoreDeletionPredicate() predicate.Predicate {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			// Ignore updates to CR status in which case metadata.Generation does not change
-			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+			// Ignore updates to CR status in which case metadata.Generation does not change,
+			// but process the start of the deletion to run the finalizer
+			// and changes of the pause and the credentials rotation annotations
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+				e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero() ||
+				e.ObjectOld.GetAnnotations()[loggingService.PausedAnnotation] != e.ObjectNew.GetAnnotations()[loggingService.PausedAnnotation] ||
+				e.ObjectOld.GetAnnotations()[loggingService.CredentialsRotationAnnotation] != e.ObjectNew.GetAnnotations()[loggingService.CredentialsRotationAnnotation]
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			// Evaluates to false if the object has been confirmed deleted.
@@ -1,13 +1,21 @@
 package controllers
 
 import (
+	"context"
+	"reflect"
+	"strings"
 	"testing"
 
 	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 var containerRuntimeTests = []struct {
@@ -189,3 +197,247 @@ func Test_updateDynamicParameters(t *testing.T) {
 		})
 	}
 }
+
+var deletionPolicyTests = []struct {
+	description       string
+	deletionPolicy    string
+	existingResources []client.Object
+	deletedResources  []client.Object
+}{
+	{
+		"Retain policy keeps Kubernetes resources of components",
+		"",
+		[]client.Object{
+			&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "graylog-claim", Namespace: "logging"}},
+		},
+		[]client.Object{},
+	},
+	{
+		"DeleteKubernetesOnly policy deletes Kubernetes resources of components",
+		"DeleteKubernetesOnly",
+		[]client.Object{
+			&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "graylog-claim", Namespace: "logging"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "logging-fluentbit", Namespace: "logging"}},
+		},
+		[]client.Object{
+			&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "graylog-claim", Namespace: "logging"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "logging-fluentbit", Namespace: "logging"}},
+		},
+	},
+}
+
+func Test_reconcileDeletion(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	if err := loggingService.AddToScheme(testScheme); err != nil {
+		t.Error("can't add test schema in arrays of schemas")
+	}
+	if err := corev1.AddToScheme(testScheme); err != nil {
+		t.Error("can't add test schema in arrays of schemas")
+	}
+	for _, tt := range deletionPolicyTests {
+		t.Run(tt.description, func(t *testing.T) {
+			deletionTimestamp := metav1.Now()
+			cr := &loggingService.LoggingService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "logging-service",
+					Namespace:         "logging",
+					Finalizers:        []string{util.LoggingServiceFinalizer},
+					DeletionTimestamp: &deletionTimestamp,
+				},
+				Spec: loggingService.LoggingServiceSpec{
+					DeletionPolicy: tt.deletionPolicy,
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(cr).
+				WithObjects(tt.existingResources...).
+				WithStatusSubresource(cr).
+				Build()
+			reconciler := &LoggingServiceReconciler{
+				Client:        fakeClient,
+				Scheme:        testScheme,
+				Log:           util.Logger("test"),
+				StatusUpdater: util.NewStatusUpdater(fakeClient, cr),
+			}
+
+			if _, err := reconciler.reconcileDeletion(context.TODO(), cr, nil, util.NewEventRecorder(record.NewFakeRecorder(10), cr)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(cr), &loggingService.LoggingService{}); !errors.IsNotFound(err) {
+				t.Errorf("expected LoggingService to be deleted after removing of the finalizer, got %v", err)
+			}
+			for _, resource := range tt.existingResources {
+				err := fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(resource), resource.DeepCopyObject().(client.Object))
+				isDeleted := false
+				for _, deleted := range tt.deletedResources {
+					if deleted.GetName() == resource.GetName() {
+						isDeleted = true
+					}
+				}
+				if isDeleted && !errors.IsNotFound(err) {
+					t.Errorf("expected %s to be deleted, got %v", resource.GetName(), err)
+				}
+				if !isDeleted && err != nil {
+					t.Errorf("expected %s to be kept, got %v", resource.GetName(), err)
+				}
+			}
+		})
+	}
+}
+
+var graylogSecretTests = []struct {
+	description string
+	old         *corev1.Secret
+	secret      *corev1.Secret
+	requests    []string
+}{
+	{
+		"Changed password of the Graylog Secret triggers reconciliation of the LoggingService using it",
+		graylogSecret("graylog-secret", "graylog=secret", "admin"),
+		graylogSecret("graylog-secret", "graylog=secret", "changed"),
+		[]string{"logging/logging-service"},
+	},
+	{
+		"Update of the Graylog Secret without changes of data is ignored",
+		graylogSecret("graylog-secret", "graylog=secret", "admin"),
+		graylogSecret("graylog-secret", "graylog=secret", "admin"),
+		nil,
+	},
+	{
+		"Secret without the label is ignored",
+		graylogSecret("graylog-secret", "", "admin"),
+		graylogSecret("graylog-secret", "", "changed"),
+		nil,
+	},
+	{
+		"Secret which is not used by LoggingServices is ignored",
+		graylogSecret("other-secret", "graylog=secret", "admin"),
+		graylogSecret("other-secret", "graylog=secret", "changed"),
+		nil,
+	},
+}
+
+func graylogSecret(name string, label string, password string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "logging"},
+		Data:       map[string][]byte{"user": []byte("admin"), "password": []byte(password)},
+	}
+	if key, value, found := strings.Cut(label, "="); found {
+		secret.Labels = map[string]string{key: value}
+	}
+	return secret
+}
+
+func Test_graylogSecretRequests(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	if err := loggingService.AddToScheme(testScheme); err != nil {
+		t.Error("can't add test schema in arrays of schemas")
+	}
+	secretPredicate, err := graylogSecretPredicate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tt := range graylogSecretTests {
+		t.Run(tt.description, func(t *testing.T) {
+			cr := &loggingService.LoggingService{
+				ObjectMeta: metav1.ObjectMeta{Name: "logging-service", Namespace: "logging"},
+				Spec: loggingService.LoggingServiceSpec{
+					Graylog: &loggingService.Graylog{GraylogSecretName: "graylog-secret"},
+				},
+			}
+			reconciler := &LoggingServiceReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build(),
+				Log:    util.Logger("test"),
+			}
+
+			var requests []string
+			if secretPredicate.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.secret}) {
+				for _, request := range reconciler.graylogSecretRequests(context.TODO(), tt.secret) {
+					requests = append(requests, request.String())
+				}
+			}
+			if !reflect.DeepEqual(requests, tt.requests) {
+				t.Errorf("expected requests %v, got %v", tt.requests, requests)
+			}
+		})
+	}
+}
+
+var contentSourceTests = []struct {
+	description string
+	old         client.Object
+	object      client.Object
+	requests    []string
+}{
+	{
+		"Changed ConfigMap with content packs triggers reconciliation of the LoggingService using it",
+		contentConfigMap("content-packs", `{"rev": 1}`),
+		contentConfigMap("content-packs", `{"rev": 2}`),
+		[]string{"logging/logging-service"},
+	},
+	{
+		"Update of the ConfigMap without changes of data is ignored",
+		contentConfigMap("content-packs", `{"rev": 1}`),
+		contentConfigMap("content-packs", `{"rev": 1}`),
+		nil,
+	},
+	{
+		"Changed Secret with saved searches triggers reconciliation of the LoggingService using it",
+		graylogSecret("saved-searches", "", "admin"),
+		graylogSecret("saved-searches", "", "changed"),
+		[]string{"logging/logging-service"},
+	},
+	{
+		"ConfigMap which is not used by LoggingServices is ignored",
+		contentConfigMap("other-content", `{"rev": 1}`),
+		contentConfigMap("other-content", `{"rev": 2}`),
+		nil,
+	},
+}
+
+func contentConfigMap(name string, contentPack string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "logging"},
+		Data:       map[string]string{"content-pack.json": contentPack},
+	}
+}
+
+func Test_contentSourceRequests(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	if err := loggingService.AddToScheme(testScheme); err != nil {
+		t.Error("can't add test schema in arrays of schemas")
+	}
+	for _, tt := range contentSourceTests {
+		t.Run(tt.description, func(t *testing.T) {
+			cr := &loggingService.LoggingService{
+				ObjectMeta: metav1.ObjectMeta{Name: "logging-service", Namespace: "logging"},
+				Spec: loggingService.LoggingServiceSpec{
+					Graylog: &loggingService.Graylog{
+						ContentPacks: []*loggingService.ContentPackPathHTTPConfig{{
+							ContentSource: loggingService.ContentSource{ConfigMap: &loggingService.ContentObjectReference{Name: "content-packs"}},
+						}},
+						SavedSearches: []loggingService.SavedSearchSource{{
+							ContentSource: loggingService.ContentSource{Secret: &loggingService.ContentObjectReference{Name: "saved-searches"}},
+						}},
+					},
+				},
+			}
+			reconciler := &LoggingServiceReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build(),
+				Log:    util.Logger("test"),
+			}
+
+			var requests []string
+			if contentSourcePredicate().Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.object}) {
+				for _, request := range reconciler.contentSourceRequests(context.TODO(), tt.object) {
+					requests = append(requests, request.String())
+				}
+			}
+			if !reflect.DeepEqual(requests, tt.requests) {
+				t.Errorf("expected requests %v, got %v", tt.requests, requests)
+			}
+		})
+	}
+}
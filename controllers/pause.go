@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+)
+
+const (
+	daemonSetKind   = "DaemonSet"
+	deploymentKind  = "Deployment"
+	statefulSetKind = "StatefulSet"
+)
+
+type workload struct {
+	name string
+	kind string
+}
+
+// pausedComponent describes how to report the health of the component while its reconciliation is paused
+type pausedComponent struct {
+	statusName  string
+	isInstalled func(cr *loggingService.LoggingService) bool
+	workloads   []workload
+}
+
+var pausedComponents = map[string]pausedComponent{
+	loggingService.PausedGraylog: {
+		statusName: util.GraylogStatus,
+		isInstalled: func(cr *loggingService.LoggingService) bool {
+			return cr.Spec.Graylog.IsInstall()
+		},
+		workloads: []workload{{util.GraylogStatefulsetName, statefulSetKind}},
+	},
+	loggingService.PausedFluentd: {
+		statusName: util.FluentdStatus,
+		isInstalled: func(cr *loggingService.LoggingService) bool {
+			return cr.Spec.Fluentd.IsInstall()
+		},
+		workloads: []workload{{util.FluentdComponentName, daemonSetKind}},
+	},
+	loggingService.PausedFluentbit: {
+		statusName: util.FluentbitStatus,
+		isInstalled: func(cr *loggingService.LoggingService) bool {
+			return cr.Spec.Fluentbit.IsInstall() && (cr.Spec.Fluentbit.Aggregator == nil || !cr.Spec.Fluentbit.Aggregator.Install)
+		},
+		workloads: []workload{{util.FluentbitComponentName, daemonSetKind}},
+	},
+	loggingService.PausedFluentbitAggregator: {
+		statusName: util.HAFluentStatus,
+		isInstalled: func(cr *loggingService.LoggingService) bool {
+			return cr.Spec.Fluentbit.IsInstall() && cr.Spec.Fluentbit.Aggregator != nil && cr.Spec.Fluentbit.Aggregator.Install
+		},
+		workloads: []workload{
+			{util.ForwarderFluentbitComponentName, daemonSetKind},
+			{util.AggregatorFluentbitComponentName, statefulSetKind},
+		},
+	},
+	loggingService.PausedEventsReader: {
+		statusName: util.EventsReaderStatus,
+		isInstalled: func(cr *loggingService.LoggingService) bool {
+			return cr.Spec.CloudEventsReader.IsInstall()
+		},
+		workloads: []workload{{util.EventsReaderComponentName, deploymentKind}},
+	},
+}
+
+// isComponentPaused returns true if the reconciliation of the component is paused.
+// Resources of the paused component are not changed, only their health is reported in the status.
+func (r *LoggingServiceReconciler) isComponentPaused(cr *loggingService.LoggingService, component string) bool {
+	if !cr.IsPaused(component) {
+		return false
+	}
+	r.Log.Info(fmt.Sprintf("Reconciliation of %s is paused by the annotation %s", component, loggingService.PausedAnnotation))
+
+	paused, found := pausedComponents[component]
+	if !found || !paused.isInstalled(cr) {
+		return true
+	}
+
+	podManager := util.NewPodManager(r.Client, cr.GetNamespace(), r.Log)
+	var notAvailable []string
+	for _, w := range paused.workloads {
+		var isAvailable bool
+		var err error
+		switch w.kind {
+		case daemonSetKind:
+			isAvailable, err = podManager.IsDaemonSetAvailable(w.name)
+		case deploymentKind:
+			isAvailable, err = podManager.IsDeploymentReplicasSynchronised(w.name)
+		case statefulSetKind:
+			isAvailable, err = podManager.IsStatefulsetReplicasSynchronised(w.name)
+		}
+		if err != nil || !isAvailable {
+			notAvailable = append(notAvailable, w.name)
+		}
+	}
+
+	message := fmt.Sprintf("Reconciliation is paused by the annotation %s. ", loggingService.PausedAnnotation)
+	if len(notAvailable) == 0 {
+		message += "All workloads are available"
+	} else {
+		message += "Not available: " + strings.Join(notAvailable, ", ")
+	}
+	r.StatusUpdater.UpdateStatus(paused.statusName, util.Paused, len(notAvailable) == 0, message)
+	return true
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"fmt"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	events_reader "github.com/Netcracker/qubership-logging-operator/controllers/events-reader"
+	"github.com/Netcracker/qubership-logging-operator/controllers/fluentbit"
+	fluentbit_forwarder_aggregator "github.com/Netcracker/qubership-logging-operator/controllers/fluentbit-forwarder-aggregator"
+	"github.com/Netcracker/qubership-logging-operator/controllers/fluentd"
+	"github.com/Netcracker/qubership-logging-operator/controllers/graylog"
+	util "github.com/Netcracker/qubership-logging-operator/controllers/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Render builds Kubernetes resources of all Logging components enabled in the custom resource
+// in the same order as they are reconciled, without access to the cluster.
+// The container runtime type is taken from the custom resource or the default one is used
+// because it can't be discovered from the nodes.
+func Render(cr *loggingService.LoggingService) ([]client.Object, error) {
+	dynamicParameters := util.DynamicParameters{ContainerRuntimeType: cr.Spec.ContainerRuntimeType}
+	if dynamicParameters.ContainerRuntimeType == "" {
+		dynamicParameters.ContainerRuntimeType = DefaultContainerRuntimeType
+	}
+
+	var objects []client.Object
+	if cr.Spec.Graylog != nil && cr.Spec.Graylog.IsInstall() {
+		manifests, err := graylog.Manifests(cr)
+		if err != nil {
+			return nil, fmt.Errorf("can't render Graylog manifests: %w", err)
+		}
+		objects = append(objects, manifests...)
+	}
+	if cr.Spec.Fluentd != nil && cr.Spec.Fluentd.IsInstall() {
+		manifests, err := fluentd.Manifests(cr, dynamicParameters)
+		if err != nil {
+			return nil, fmt.Errorf("can't render Fluentd manifests: %w", err)
+		}
+		objects = append(objects, manifests...)
+	}
+	if cr.Spec.Fluentbit != nil && cr.Spec.Fluentbit.IsInstall() {
+		if cr.Spec.Fluentbit.Aggregator != nil && cr.Spec.Fluentbit.Aggregator.Install {
+			manifests, err := fluentbit_forwarder_aggregator.Manifests(cr, dynamicParameters)
+			if err != nil {
+				return nil, fmt.Errorf("can't render Fluentbit-Forwarder-Aggregator manifests: %w", err)
+			}
+			objects = append(objects, manifests...)
+		} else {
+			manifests, err := fluentbit.Manifests(cr, dynamicParameters)
+			if err != nil {
+				return nil, fmt.Errorf("can't render Fluentbit manifests: %w", err)
+			}
+			objects = append(objects, manifests...)
+		}
+	}
+	if cr.Spec.CloudEventsReader != nil && cr.Spec.CloudEventsReader.IsInstall() {
+		manifests, err := events_reader.Manifests(cr)
+		if err != nil {
+			return nil, fmt.Errorf("can't render Events Reader manifests: %w", err)
+		}
+		objects = append(objects, manifests...)
+	}
+	return objects, nil
+}
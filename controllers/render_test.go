@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"testing"
+
+	loggingService "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var renderTests = []struct {
+	description string
+	spec        loggingService.LoggingServiceSpec
+	objects     []string
+	isError     bool
+}{
+	{
+		"Nothing is rendered if no components are enabled",
+		loggingService.LoggingServiceSpec{},
+		nil,
+		false,
+	},
+	{
+		"Graylog, Fluentbit and Events Reader are rendered",
+		loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				DockerImage: "graylog:5.2.7",
+				AuthProxy:   &loggingService.AuthProxy{},
+			},
+			Fluentbit: &loggingService.Fluentbit{
+				DockerImage:     "fluent-bit:3.0.0",
+				ConfigmapReload: &loggingService.ConfigmapReload{DockerImage: "configmap-reload:0.13"},
+			},
+			CloudEventsReader: &loggingService.CloudEventsReader{DockerImage: "events-reader:1.0"},
+		},
+		[]string{
+			"ServiceAccount/logging-graylog", "ConfigMap/graylog-service", "StatefulSet/graylog", "Service/graylog-service",
+			"ConfigMap/logging-fluentbit", "DaemonSet/logging-fluentbit", "Service/logging-fluentbit",
+			"Deployment/events-reader", "Service/events-reader",
+		},
+		false,
+	},
+	{
+		"Graylog cluster with MongoDB replica set is rendered",
+		loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				DockerImage: "graylog:5.2.7",
+				AuthProxy:   &loggingService.AuthProxy{},
+				Replicas:    3,
+				MongoDB: &loggingService.GraylogMongoDB{
+					ReplicaSet: &loggingService.MongoDBReplicaSet{Install: true},
+				},
+			},
+		},
+		[]string{
+			"ServiceAccount/logging-graylog", "ConfigMap/graylog-service", "StatefulSet/graylog", "Service/graylog-service",
+			"Service/graylog-mongo", "StatefulSet/graylog-mongo", "PodDisruptionBudget/graylog-mongo",
+			"Service/graylog-nodes", "Service/graylog-input", "PodDisruptionBudget/graylog",
+		},
+		false,
+	},
+	{
+		"Graylog with MongoDB backups on the persistent volume is rendered",
+		loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				DockerImage:  "graylog:5.2.7",
+				MongoDBImage: "mongo:5.0.19",
+				AuthProxy:    &loggingService.AuthProxy{},
+				Backup: &loggingService.GraylogBackup{
+					Install:               true,
+					PersistentVolumeClaim: "graylog-backup",
+				},
+			},
+		},
+		[]string{
+			"ServiceAccount/logging-graylog", "ConfigMap/graylog-service", "StatefulSet/graylog", "Service/graylog-service",
+			"Service/graylog-embedded-mongo", "CronJob/graylog-mongo-backup",
+		},
+		false,
+	},
+	{
+		"Graylog with MongoDB backups in S3 is rendered",
+		loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				DockerImage:  "graylog:5.2.7",
+				MongoDBImage: "mongo:5.0.19",
+				AuthProxy:    &loggingService.AuthProxy{},
+				Backup: &loggingService.GraylogBackup{
+					Install:       true,
+					RetentionDays: 14,
+					S3: &loggingService.BackupS3{
+						Endpoint:        "http://minio.minio.svc:9000",
+						Bucket:          "graylog-backups",
+						Prefix:          "logging",
+						Image:           "minio/mc:latest",
+						AccessKeySecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "graylog-backup-s3"}, Key: "accessKey"},
+						SecretKeySecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "graylog-backup-s3"}, Key: "secretKey"},
+					},
+				},
+			},
+		},
+		[]string{
+			"ServiceAccount/logging-graylog", "ConfigMap/graylog-service", "StatefulSet/graylog", "Service/graylog-service",
+			"Service/graylog-embedded-mongo", "CronJob/graylog-mongo-backup",
+		},
+		false,
+	},
+	{
+		"Graylog with entries of the lookup tables is rendered",
+		loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				DockerImage: "graylog:5.2.7",
+				AuthProxy:   &loggingService.AuthProxy{},
+				LookupTables: &loggingService.GraylogLookupTables{
+					DataAdapters: []loggingService.GraylogLookupDataAdapter{
+						{Name: "environments", Type: "inmemory", Entries: map[string]string{"prod-payments": "production"}},
+					},
+				},
+			},
+		},
+		[]string{
+			"ServiceAccount/logging-graylog", "ConfigMap/graylog-service", "StatefulSet/graylog", "Service/graylog-service",
+			"ConfigMap/graylog-lookup-tables",
+		},
+		false,
+	},
+	{
+		"MongoDB upgrade steps require versions",
+		loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				DockerImage: "graylog:5.2.7",
+				AuthProxy:   &loggingService.AuthProxy{},
+				MongoDBUpgrade: &loggingService.MongoDBUpgrade{
+					Steps: []loggingService.MongoDBUpgradeStep{{Version: "latest", Image: "mongo:6.0"}},
+				},
+			},
+		},
+		nil,
+		true,
+	},
+	{
+		"Graylog backups require the storage",
+		loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				DockerImage: "graylog:5.2.7",
+				AuthProxy:   &loggingService.AuthProxy{},
+				Backup:      &loggingService.GraylogBackup{Install: true},
+			},
+		},
+		nil,
+		true,
+	},
+	{
+		"Graylog cluster requires MongoDB outside of the Graylog pod",
+		loggingService.LoggingServiceSpec{
+			Graylog: &loggingService.Graylog{
+				DockerImage: "graylog:5.2.7",
+				AuthProxy:   &loggingService.AuthProxy{},
+				Replicas:    2,
+			},
+		},
+		nil,
+		true,
+	},
+	{
+		"Aggregator with Graylog output requires Graylog host",
+		loggingService.LoggingServiceSpec{
+			Fluentbit: &loggingService.Fluentbit{
+				Aggregator: &loggingService.FluentbitAggregator{Install: true, GraylogOutput: true},
+			},
+		},
+		nil,
+		true,
+	},
+}
+
+func TestRender(t *testing.T) {
+	for _, test := range renderTests {
+		t.Run(test.description, func(t *testing.T) {
+			cr := &loggingService.LoggingService{
+				ObjectMeta: metav1.ObjectMeta{Name: "logging-service", Namespace: "logging"},
+				Spec:       test.spec,
+			}
+			objects, err := Render(cr)
+			if (err != nil) != test.isError {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, o := range objects {
+				names = append(names, o.GetObjectKind().GroupVersionKind().Kind+"/"+o.GetName())
+			}
+			if len(names) != len(test.objects) {
+				t.Fatalf("Expected objects %v, but got %v", test.objects, names)
+			}
+			for i := range names {
+				if names[i] != test.objects[i] {
+					t.Errorf("Expected object %s, but got %s", test.objects[i], names[i])
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package utils
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"reflect"
 	"strings"
 	"time"
 
@@ -23,6 +26,10 @@ var (
 	logger      = Logger("util")
 )
 
+// passwordCharacters are used in the generated passwords. Special characters are not used,
+// because the passwords are put into the configuration files and URLs of Graylog clients
+const passwordCharacters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
 func ToString(duration time.Duration) string {
 	return (duration - (duration % time.Millisecond)).String()
 }
@@ -59,6 +66,7 @@ type ComponentReconciler struct {
 	Scheme        *runtime.Scheme
 	Log           logr.Logger
 	StatusUpdater StatusUpdater
+	EventRecorder EventRecorder
 }
 
 type DynamicParameters struct {
@@ -83,6 +91,7 @@ func (r *ComponentReconciler) CreateResource(cr *loggingService.LoggingService,
 		return err
 	}
 	r.Log.Info("Successful creating", ResourceKey, res)
+	r.EventRecorder.Normal(ReasonComponentCreated, fmt.Sprintf("%s %s created", resourceKind(o), o.GetName()))
 	return nil
 }
 
@@ -102,11 +111,26 @@ func (r *ComponentReconciler) GetResource(o K8sResource) error {
 }
 
 func (r *ComponentReconciler) UpdateResource(o K8sResource) error {
+	// Remember the current resource version to find out whether the update changed anything
+	resourceVersion := o.GetResourceVersion()
+	if resourceVersion == "" {
+		if current, ok := o.DeepCopyObject().(K8sResource); ok && r.GetResource(current) == nil {
+			resourceVersion = current.GetResourceVersion()
+		}
+	}
 	// Update object
 	if err := r.Client.Update(context.TODO(), o); err != nil {
 		return err
 	}
 	r.Log.Info("Successful updating", ResourceKey, o.GetObjectKind().GroupVersionKind().Kind)
+	if o.GetResourceVersion() != resourceVersion {
+		if _, isConfigMap := o.(*core.ConfigMap); isConfigMap {
+			IncConfigMapUpdates(o.GetName())
+			r.EventRecorder.Normal(ReasonConfigChanged, fmt.Sprintf("ConfigMap %s changed", o.GetName()))
+		} else {
+			r.EventRecorder.Normal(ReasonComponentUpdated, fmt.Sprintf("%s %s updated", resourceKind(o), o.GetName()))
+		}
+	}
 	return nil
 }
 
@@ -119,6 +143,15 @@ func (r *ComponentReconciler) DeleteResource(o K8sResource) error {
 	return nil
 }
 
+// resourceKind returns kind of the resource. Typed objects usually have an empty GroupVersionKind,
+// so the kind is taken from the Go type in this case.
+func resourceKind(o K8sResource) string {
+	if kind := o.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.Indirect(reflect.ValueOf(o)).Type().Name()
+}
+
 // ResourceExists returns true if the given resource kind exists
 // in the given api groupversion
 func ResourceExists(dc discovery.DiscoveryInterface, apiGroupVersion, kind string) (bool, error) {
@@ -158,3 +191,17 @@ func GetAggregatorIds(num int) []int {
 	}
 	return slice
 }
+
+// GeneratePassword returns the random password of the length
+func GeneratePassword(length int) (string, error) {
+	password := make([]byte, length)
+	max := big.NewInt(int64(len(passwordCharacters)))
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = passwordCharacters[n.Int64()]
+	}
+	return string(password), nil
+}
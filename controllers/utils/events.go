@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	EventSourceComponent = "logging-operator"
+
+	ReasonComponentCreated     = "ComponentCreated"
+	ReasonComponentUpdated     = "ComponentUpdated"
+	ReasonConfigChanged        = "ConfigChanged"
+	ReasonGraylogObjectCreated = "GraylogObjectCreated"
+	ReasonGraylogObjectUpdated = "GraylogObjectUpdated"
+	ReasonGraylogViewDrifted   = "GraylogViewDrifted"
+	ReasonGraylogViewReverted  = "GraylogViewDriftReverted"
+	ReasonMongoUpgradeStarted  = "MongoUpgradeStepStarted"
+	ReasonMongoUpgradeFinished = "MongoUpgradeStepFinished"
+	ReasonMongoUpgradeFailed   = "MongoUpgradeStepFailed"
+	ReasonMongoRestoreStarted  = "MongoRestoreStarted"
+	ReasonMongoRestoreFinished = "MongoRestoreFinished"
+	ReasonMongoRestoreFailed   = "MongoRestoreFailed"
+	ReasonAuthBackendActivated = "AuthBackendActivated"
+	ReasonAuthBackendFailed    = "AuthBackendFailed"
+	ReasonCredentialsGenerated = "CredentialsGenerated"
+	ReasonCredentialsRotated   = "CredentialsRotated"
+	ReasonCredentialsFailed    = "CredentialsRotationFailed"
+	ReasonValidationFailed     = "ValidationFailed"
+	ReasonReconcileFailed      = "ReconcileFailed"
+)
+
+var (
+	// EventsBurstSize and EventsQPS limit the number of Events with the same reason
+	// which can be sent for the LoggingService, so a failing reconcile loop can't flood etcd.
+	// Similar Events are also aggregated by the client-go correlator.
+	EventsBurstSize         = 25
+	EventsQPS       float32 = 1. / 60.
+)
+
+// EventRecorder emits Kubernetes Events on the reconciled LoggingService or GraylogView
+type EventRecorder struct {
+	recorder record.EventRecorder
+	resource runtime.Object
+}
+
+func NewEventRecorder(recorder record.EventRecorder, resource runtime.Object) EventRecorder {
+	return EventRecorder{
+		recorder: recorder,
+		resource: resource,
+	}
+}
+
+// NewKubernetesEventRecorder creates the recorder which sends rate-limited Events to the Kubernetes API
+func NewKubernetesEventRecorder(config *rest.Config, scheme *runtime.Scheme, namespace string) (record.EventRecorder, error) {
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcaster := record.NewBroadcasterWithCorrelatorOptions(record.CorrelatorOptions{
+		BurstSize: EventsBurstSize,
+		QPS:       EventsQPS,
+		// Rate-limit every reason separately to avoid losing milestones because of repeated failures
+		SpamKeyFunc: func(event *corev1.Event) string {
+			return fmt.Sprintf("%s/%s/%s/%s", event.Source.Component, event.InvolvedObject.Namespace, event.InvolvedObject.Name, event.Reason)
+		},
+	})
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientSet.CoreV1().Events(namespace)})
+
+	return broadcaster.NewRecorder(scheme, corev1.EventSource{Component: EventSourceComponent}), nil
+}
+
+func (r *EventRecorder) Normal(reason string, message string) {
+	r.event(corev1.EventTypeNormal, reason, message)
+}
+
+func (r *EventRecorder) Warning(reason string, message string) {
+	r.event(corev1.EventTypeWarning, reason, message)
+}
+
+func (r *EventRecorder) event(eventType string, reason string, message string) {
+	if r.recorder == nil || r.resource == nil {
+		return
+	}
+	r.recorder.Event(r.resource, eventType, reason, message)
+}
@@ -4,16 +4,20 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"embed"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -21,6 +25,7 @@ import (
 
 	"github.com/Masterminds/sprig"
 	v11 "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -52,8 +57,8 @@ func ReadFile(filePath string) (string, error) {
 
 // MustAssetReader loads and return the asset for the given name as bytes reader.
 // Panics when the asset loading would return an error.
-func MustAssetReader(assets embed.FS, asset string) string {
-	content, _ := assets.ReadFile(asset)
+func MustAssetReader(assets fs.FS, asset string) string {
+	content, _ := fs.ReadFile(assets, asset)
 	return string(content)
 }
 
@@ -133,37 +138,9 @@ func DownloadFileTLS(ctx context.Context, contentPackPath *v11.ContentPackPathHT
 		return err
 	}
 
-	var user *Сreds
-	var tlsConfig *tls.Config
-
-	if contentPackPath != nil {
-		if contentPackPath.HTTPConfig != nil {
-			var name, pwd, token string
-			name, pwd, token, tlsConfig, err = contentPackPath.HTTPConfig.GetCredentialsAndCertificates(ctx, clientSet, namespace)
-			if err != nil {
-				return err
-			}
-			if (name != "" && pwd != "") || token != "" {
-				user = &Сreds{
-					Name:     name,
-					Password: pwd,
-					Token:    token,
-				}
-			}
-		}
-	}
-
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	transport.MaxIdleConnsPerHost = transport.MaxIdleConns
-	transport.MaxConnsPerHost = transport.MaxIdleConns
-	transport.TLSClientConfig = tlsConfig
-	client := &http.Client{
-		CheckRedirect: func(r *http.Request, via []*http.Request) error {
-			r.URL.Opaque = r.URL.Path
-			return nil
-		},
-		Transport: transport,
-		Timeout:   time.Duration(ConnectionTimeout) * time.Second,
+	restClient, err := NewRestClient(ctx, contentPackPath.HTTPConfig, clientSet, namespace)
+	if err != nil {
+		return err
 	}
 
 	var urlFile *url.URL
@@ -175,11 +152,6 @@ func DownloadFileTLS(ctx context.Context, contentPackPath *v11.ContentPackPathHT
 	// Preventing path traversal
 	urlFile = urlFile.ResolveReference(urlFile)
 
-	restClient := &RestClient{
-		Client: client,
-		Auth:   user,
-	}
-
 	var request *http.Request
 	request, err = http.NewRequest(http.MethodGet, urlFile.String(), nil)
 	if err != nil {
@@ -206,6 +178,129 @@ func DownloadFileTLS(ctx context.Context, contentPackPath *v11.ContentPackPathHT
 	return nil
 }
 
+// NewRestClient returns the client with the credentials and the TLS config from the HTTP config
+func NewRestClient(ctx context.Context, httpConfig *v11.HTTPConfig, clientSet kubernetes.Interface, namespace string) (*RestClient, error) {
+	var user *Сreds
+	var tlsConfig *tls.Config
+
+	if httpConfig != nil {
+		name, pwd, token, config, err := httpConfig.GetCredentialsAndCertificates(ctx, clientSet, namespace)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig = config
+		if (name != "" && pwd != "") || token != "" {
+			user = &Сreds{
+				Name:     name,
+				Password: pwd,
+				Token:    token,
+			}
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = transport.MaxIdleConns
+	transport.MaxConnsPerHost = transport.MaxIdleConns
+	transport.TLSClientConfig = tlsConfig
+	client := &http.Client{
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			r.URL.Opaque = r.URL.Path
+			return nil
+		},
+		Transport: transport,
+		Timeout:   time.Duration(ConnectionTimeout) * time.Second,
+	}
+
+	return &RestClient{
+		Client: client,
+		Auth:   user,
+	}, nil
+}
+
+// ContentFile is the file with Graylog content from the content source
+type ContentFile struct {
+	Name string
+	Data []byte
+	// Digest of the manifest of the OCI artifact the file is pulled from, it is empty for other sources
+	Digest string
+}
+
+// FetchContent returns the files from the ConfigMap, the Secret, the inline files or the OCI artifact
+// sorted by their names. The HTTP config is used to pull the OCI artifact
+func FetchContent(ctx context.Context, source *v11.ContentSource, httpConfig *v11.HTTPConfig, clientSet kubernetes.Interface, namespace string) ([]ContentFile, error) {
+	files := map[string][]byte{}
+	switch {
+	case source.ConfigMap != nil:
+		configMap, err := clientSet.CoreV1().ConfigMaps(namespace).Get(ctx, source.ConfigMap.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range configMap.Data {
+			files[key] = []byte(value)
+		}
+		for key, value := range configMap.BinaryData {
+			files[key] = value
+		}
+		return selectContentFiles(files, source.ConfigMap, "ConfigMap")
+	case source.Secret != nil:
+		secret, err := clientSet.CoreV1().Secrets(namespace).Get(ctx, source.Secret.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return selectContentFiles(secret.Data, source.Secret, "Secret")
+	case len(source.Inline) != 0:
+		for name, value := range source.Inline {
+			files[name] = []byte(value)
+		}
+		return selectContentFiles(files, nil, "")
+	case source.OCI != "":
+		return PullOCIArtifact(ctx, source.OCI, httpConfig, clientSet, namespace)
+	}
+	return nil, fmt.Errorf("content source is empty")
+}
+
+// selectContentFiles returns the file with the key of the reference or all files sorted by their names.
+// The names are reduced to the base names, so the files can't be written outside the data directory
+func selectContentFiles(files map[string][]byte, reference *v11.ContentObjectReference, kind string) ([]ContentFile, error) {
+	if reference != nil && reference.Key != "" {
+		data, ok := files[reference.Key]
+		if !ok {
+			return nil, fmt.Errorf("%s %s doesn't contain the key %s", kind, reference.Name, reference.Key)
+		}
+		return []ContentFile{{Name: filepath.Base(reference.Key), Data: data}}, nil
+	}
+	contentFiles := make([]ContentFile, 0, len(files))
+	for name, data := range files {
+		contentFiles = append(contentFiles, ContentFile{Name: filepath.Base(name), Data: data})
+	}
+	sort.Slice(contentFiles, func(i, j int) bool {
+		return contentFiles[i].Name < contentFiles[j].Name
+	})
+	return contentFiles, nil
+}
+
+// VerifyChecksum compares the SHA-256 checksum of the file with the expected one in the format "sha256:<hex>"
+func VerifyChecksum(fileName string, checksum string) error {
+	algorithm, expected, found := strings.Cut(checksum, ":")
+	if !found || !strings.EqualFold(algorithm, "sha256") {
+		return fmt.Errorf("unsupported checksum %s, it should be in the format sha256:<hex>", checksum)
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("checksum of the file %s is sha256:%s, expected %s", filepath.Base(fileName), actual, checksum)
+	}
+	return nil
+}
+
 func (restClient *RestClient) SetAuthHeader(request *http.Request) {
 
 	if restClient.Auth != nil {
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "logging_operator"
+
+	ResultSuccess = "success"
+	ResultFailed  = "failed"
+	ResultTimeout = "timeout"
+
+	LoggingServiceComponentName = "logging-service"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "component_reconcile_duration_seconds",
+		Help:      "Duration of the reconcile of the Logging component",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"component", "result"})
+
+	graylogRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "graylog_request_duration_seconds",
+		Help:      "Latency of the requests to the Graylog REST API",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "endpoint", "code"})
+
+	graylogObjectChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "graylog_object_changes_total",
+		Help:      "Number of the Graylog objects created, updated or deleted by the operator by their types",
+	}, []string{"type", "action"})
+
+	configMapUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "configmap_updates_total",
+		Help:      "Number of the changes of the ConfigMaps managed by the operator",
+	}, []string{"configmap"})
+
+	pendingComponentWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "pending_component_wait_seconds",
+		Help:      "Time of waiting until the Logging component is started",
+		Buckets:   []float64{10, 30, 60, 120, 180, 300, 600},
+	}, []string{"component", "result"})
+
+	// objectIdRegexp matches Graylog object ids (Mongo ObjectId and UUID) in the request path
+	objectIdRegexp = regexp.MustCompile(`^([0-9a-f]{24}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+)
+
+func init() {
+	// Collectors are registered in the controller-runtime registry to be published
+	// on the same endpoint with the default controller metrics
+	metrics.Registry.MustRegister(
+		reconcileDuration,
+		graylogRequestDuration,
+		graylogObjectChanges,
+		configMapUpdates,
+		pendingComponentWait,
+	)
+}
+
+// ObserveReconcile records the duration and the result of the component reconcile started at the given time
+func ObserveReconcile(component string, start time.Time, succeeded bool) {
+	result := ResultSuccess
+	if !succeeded {
+		result = ResultFailed
+	}
+	reconcileDuration.WithLabelValues(component, result).Observe(time.Since(start).Seconds())
+}
+
+// ObserveGraylogRequest records the latency of the request to the Graylog REST API.
+// Status code -1 means that the request was failed without the response.
+func ObserveGraylogRequest(method string, urlPath string, code int, start time.Time) {
+	graylogRequestDuration.WithLabelValues(method, GraylogEndpoint(urlPath), strconv.Itoa(code)).Observe(time.Since(start).Seconds())
+}
+
+// IncGraylogObjectChanges increases the number of the changes of the Graylog objects of the type by the action:
+// created, updated or deleted
+func IncGraylogObjectChanges(objectType string, action string) {
+	graylogObjectChanges.WithLabelValues(objectType, action).Inc()
+}
+
+// IncConfigMapUpdates increases the number of the changes of the ConfigMap
+func IncConfigMapUpdates(name string) {
+	configMapUpdates.WithLabelValues(name).Inc()
+}
+
+// ObservePendingComponent records the time of waiting until the component is started
+func ObservePendingComponent(component string, waitTime time.Duration, result string) {
+	pendingComponentWait.WithLabelValues(component, result).Observe(waitTime.Seconds())
+}
+
+// GraylogEndpoint returns the request path without the query and with object ids replaced by placeholder
+// to keep the cardinality of the endpoint label low
+func GraylogEndpoint(urlPath string) string {
+	if u, err := url.Parse(urlPath); err == nil {
+		urlPath = u.Path
+	}
+	segments := strings.Split(strings.Trim(urlPath, "/"), "/")
+	for i, segment := range segments {
+		if objectIdRegexp.MatchString(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
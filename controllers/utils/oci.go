@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	v11 "github.com/Netcracker/qubership-logging-operator/api/v1alpha1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ociTitleAnnotation = "org.opencontainers.image.title"
+	ociManifestTypes   = "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json"
+	// ociMaxBlobSize limits the size of the manifest and the layers which are read into memory
+	ociMaxBlobSize int64 = 64 << 20
+)
+
+var ociChallengeRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ociManifest is the part of the OCI image manifest with the layers of the artifact
+type ociManifest struct {
+	MediaType string `json:"mediaType"`
+	Layers    []struct {
+		MediaType   string            `json:"mediaType"`
+		Digest      string            `json:"digest"`
+		Size        int64             `json:"size"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+// ociRegistry pulls the manifests and the blobs of the repository by the OCI Distribution API
+type ociRegistry struct {
+	restClient *RestClient
+	baseURL    string
+	repository string
+	// token is the bearer token issued by the authorization service of the registry
+	token string
+}
+
+// PullOCIArtifact downloads the layers of the OCI artifact and returns them as the files named
+// by the annotation org.opencontainers.image.title, e.g. the files pushed by "oras push".
+// The files have the digest of the manifest, so the new push of the tag can be detected.
+// The registry is requested over HTTPS if the reference doesn't start with http://
+func PullOCIArtifact(ctx context.Context, reference string, httpConfig *v11.HTTPConfig, clientSet kubernetes.Interface, namespace string) ([]ContentFile, error) {
+	baseURL, repository, tag, err := parseOCIReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	restClient, err := NewRestClient(ctx, httpConfig, clientSet, namespace)
+	if err != nil {
+		return nil, err
+	}
+	registry := &ociRegistry{restClient: restClient, baseURL: baseURL, repository: repository}
+
+	data, err := registry.get(ctx, "manifests/"+tag, ociManifestTypes)
+	if err != nil {
+		return nil, fmt.Errorf("can't get manifest of OCI artifact %s: %w", reference, err)
+	}
+	digest := manifestDigest(data)
+	var manifest ociManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("can't parse manifest of OCI artifact %s: %w", reference, err)
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, fmt.Errorf("OCI artifact %s has no layers, the index manifests are not supported", reference)
+	}
+
+	files := make([]ContentFile, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		name := layer.Annotations[ociTitleAnnotation]
+		if name == "" {
+			logger.Info(fmt.Sprintf("Layer %s of OCI artifact %s has no annotation %s. Skip it", layer.Digest, reference, ociTitleAnnotation))
+			continue
+		}
+		if layer.Size > ociMaxBlobSize {
+			return nil, fmt.Errorf("layer %s of OCI artifact %s is larger than %d bytes", name, reference, ociMaxBlobSize)
+		}
+		blob, err := registry.get(ctx, "blobs/"+layer.Digest, "")
+		if err != nil {
+			return nil, fmt.Errorf("can't get layer %s of OCI artifact %s: %w", name, reference, err)
+		}
+		if err = verifyDigest(blob, layer.Digest); err != nil {
+			return nil, fmt.Errorf("layer %s of OCI artifact %s: %w", name, reference, err)
+		}
+		files = append(files, ContentFile{Name: filepath.Base(name), Data: blob, Digest: digest})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	logger.Info(fmt.Sprintf("Pulled %d files from OCI artifact %s with digest %s", len(files), reference, digest))
+	return files, nil
+}
+
+// parseOCIReference splits the reference [http://]host[:port]/repository[:tag|@digest]
+// into the URL of the registry, the repository and the tag or the digest
+func parseOCIReference(reference string) (baseURL string, repository string, tag string, err error) {
+	scheme := "https"
+	if rest, found := strings.CutPrefix(reference, "http://"); found {
+		scheme, reference = "http", rest
+	} else {
+		reference = strings.TrimPrefix(strings.TrimPrefix(reference, "https://"), "oci://")
+	}
+	host, path, found := strings.Cut(reference, "/")
+	if !found || host == "" || path == "" {
+		return "", "", "", fmt.Errorf("invalid OCI reference %s, it should be host/repository:tag", reference)
+	}
+	if repository, digest, found := strings.Cut(path, "@"); found {
+		return scheme + "://" + host, repository, digest, nil
+	}
+	repository, tag = path, "latest"
+	if i := strings.LastIndex(path, ":"); i > strings.LastIndex(path, "/") {
+		repository, tag = path[:i], path[i+1:]
+	}
+	return scheme + "://" + host, repository, tag, nil
+}
+
+// get requests the path of the repository. The bearer token is requested from the authorization service
+// of the registry if the registry responds with the challenge, e.g. as Docker Hub or Harbor
+func (registry *ociRegistry) get(ctx context.Context, path string, accept string) ([]byte, error) {
+	response, err := registry.do(ctx, path, accept)
+	if err != nil {
+		return nil, err
+	}
+	if challenge := response.Header.Get("WWW-Authenticate"); response.StatusCode == http.StatusUnauthorized &&
+		registry.token == "" && strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		response.Body.Close()
+		if err = registry.authorize(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if response, err = registry.do(ctx, path, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry responded with status code %d", response.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, ociMaxBlobSize))
+}
+
+func (registry *ociRegistry) do(ctx context.Context, path string, accept string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, registry.baseURL+"/v2/"+registry.repository+"/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if registry.token != "" {
+		request.Header.Set("Authorization", "Bearer "+registry.token)
+	} else {
+		registry.restClient.SetAuthHeader(request)
+	}
+	return registry.restClient.Client.Do(request)
+}
+
+// authorize requests the bearer token by the challenge of the registry with the credentials of the HTTP config
+func (registry *ociRegistry) authorize(ctx context.Context, challenge string) error {
+	params := map[string]string{}
+	for _, match := range ociChallengeRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return fmt.Errorf("registry requested the authorization without the realm: %s", challenge)
+	}
+	query := url.Values{}
+	for _, param := range []string{"service", "scope"} {
+		if params[param] != "" {
+			query.Set(param, params[param])
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	registry.restClient.SetAuthHeader(request)
+	response, err := registry.restClient.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("authorization service of the registry responded with status code %d", response.StatusCode)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return err
+	}
+	registry.token = token.Token
+	if registry.token == "" {
+		registry.token = token.AccessToken
+	}
+	if registry.token == "" {
+		return fmt.Errorf("authorization service of the registry returned the empty token")
+	}
+	return nil
+}
+
+// manifestDigest returns the sha256 digest of the manifest. It is the digest of the artifact in the registry,
+// because the manifest is returned as is
+func manifestDigest(manifest []byte) string {
+	hash := sha256.Sum256(manifest)
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+// verifyDigest checks the content of the blob by its sha256 digest
+func verifyDigest(blob []byte, digest string) error {
+	algorithm, expected, _ := strings.Cut(digest, ":")
+	if algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest %s", digest)
+	}
+	hash := sha256.Sum256(blob)
+	if actual := hex.EncodeToString(hash[:]); actual != expected {
+		return fmt.Errorf("digest of the content is sha256:%s, expected %s", actual, digest)
+	}
+	return nil
+}
@@ -164,7 +164,9 @@ func (manager *PodManager) IsStatefulsetReplicasSynchronised(serviceName string)
 	manager.log.V(Debug).Info(fmt.Sprintf("Service: %s. AvailableReplicas: %d, ReadyReplicas: %d, Total replicas: %d.",
 		serviceName, ss.Status.Replicas, ss.Status.ReadyReplicas, *ss.Spec.Replicas))
 
-	return ss.Status.Replicas == *ss.Spec.Replicas && ss.Status.ReadyReplicas == *ss.Spec.Replicas, nil
+	// All replicas must be updated, so the rolling update is finished
+	return ss.Status.ObservedGeneration >= ss.Generation && ss.Status.Replicas == *ss.Spec.Replicas &&
+		ss.Status.ReadyReplicas == *ss.Spec.Replicas && ss.Status.UpdatedReplicas == *ss.Spec.Replicas, nil
 }
 
 func (manager *PodManager) IsJobSucceeded(jobName string) (bool, error) {
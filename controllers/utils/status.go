@@ -18,6 +18,7 @@ const (
 	InProgress = "In Progress"
 	Success    = "Successful"
 	Failed     = "Failed"
+	Paused     = "Paused"
 )
 
 type StatusUpdater struct {
@@ -99,6 +100,96 @@ func (updater *StatusUpdater) UpdateStatus(reason string, statusType string, sta
 	}
 }
 
+// UpdateMongoDBUpgradeStatus records the progress of the MongoDB upgrade
+func (updater *StatusUpdater) UpdateMongoDBUpgradeStatus(status *loggingService.MongoDBUpgradeStatus) {
+	updater.resource.Status.MongoDBUpgrade = status
+	if err := updater.patch(); err != nil {
+		updater.log.Error(err, "Update the status of MongoDB upgrade failed")
+	}
+}
+
+// UpdateCredentialsRotationStatus records the completed rotation of Graylog credentials
+func (updater *StatusUpdater) UpdateCredentialsRotationStatus(status *loggingService.CredentialsRotationStatus) {
+	updater.resource.Status.CredentialsRotation = status
+	if err := updater.patch(); err != nil {
+		updater.log.Error(err, "Update the status of credentials rotation failed")
+	}
+}
+
+// UpdateContentPacksStatus records the installed revisions of the content packs
+func (updater *StatusUpdater) UpdateContentPacksStatus(contentPacks []loggingService.ContentPackStatus) {
+	if reflect.DeepEqual(updater.resource.Status.ContentPacks, contentPacks) {
+		return
+	}
+	updater.resource.Status.ContentPacks = contentPacks
+	var err error
+	if len(contentPacks) == 0 {
+		// The merge patch of the whole status omits the empty list, so the list is removed explicitly
+		patch := client.RawPatch(types.MergePatchType, []byte(`{"status":{"contentPacks":null}}`))
+		err = updater.client.Status().Patch(context.TODO(), updater.resource, patch)
+	} else {
+		err = updater.patch()
+	}
+	if err != nil {
+		updater.log.Error(err, "Update the status of content packs failed")
+	}
+}
+
+// UpdateExternalObjectsStatus records the objects created by the operator in Graylog and OpenSearch
+func (updater *StatusUpdater) UpdateExternalObjectsStatus(objects *loggingService.ExternalObjectsStatus) {
+	if objects == nil || reflect.DeepEqual(updater.resource.Status.ExternalObjects, objects) {
+		return
+	}
+	// The merge patch keeps the omitted fields, so the lists which become empty are removed explicitly
+	value, err := mergePatchValue(updater.resource.Status.ExternalObjects, objects)
+	if err == nil {
+		var mergePatch []byte
+		if mergePatch, err = json.Marshal(map[string]interface{}{"status": map[string]interface{}{"externalObjects": value}}); err == nil {
+			err = updater.client.Status().Patch(context.TODO(), updater.resource, client.RawPatch(types.MergePatchType, mergePatch))
+		}
+	}
+	if err != nil {
+		updater.log.Error(err, "Update the status of external objects failed")
+		return
+	}
+	updater.resource.Status.ExternalObjects = objects
+}
+
+// mergePatchValue returns the fields of the current object where the fields of the previous one
+// which are omitted in the current object are set to null
+func mergePatchValue(previous interface{}, current interface{}) (map[string]interface{}, error) {
+	previousFields, err := jsonFields(previous)
+	if err != nil {
+		return nil, err
+	}
+	currentFields, err := jsonFields(current)
+	if err != nil {
+		return nil, err
+	}
+	for field := range previousFields {
+		if _, found := currentFields[field]; !found {
+			currentFields[field] = nil
+		}
+	}
+	return currentFields, nil
+}
+
+func jsonFields(object interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		// The nil object is marshalled to null
+		fields = map[string]interface{}{}
+	}
+	return fields, nil
+}
+
 func (updater *StatusUpdater) patch() error {
 
 	resourceBuf, err := json.Marshal(updater.resource)
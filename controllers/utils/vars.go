@@ -8,7 +8,10 @@ import (
 var (
 	BasePath = "assets/"
 
-	LoggingServiceStatus = "ReconcileCycleStatus"
+	LoggingServiceStatus    = "ReconcileCycleStatus"
+	CleanupStatus           = "CleanupStatus"
+	LoggingServiceFinalizer = "logging.qubership.org/finalizer"
+	GraylogViewFinalizer    = "logging.qubership.org/graylog-view"
 
 	FluentdComponentName      = "logging-fluentd"
 	FluentdStatus             = "ReconcileFluentdStatus"
@@ -55,11 +58,35 @@ var (
 	GraylogStatefulsetName          = "graylog"
 	GraylogClaimName                = "graylog-claim"
 	MongoClaimName                  = "mongo-claim"
+	GraylogNodesServiceName         = "graylog-nodes"
+	GraylogInputServiceName         = "graylog-input"
+	GraylogMongoName                = "graylog-mongo"
+	GraylogEmbeddedMongoServiceName = "graylog-embedded-mongo"
+	GraylogMongoBackupName          = "graylog-mongo-backup"
+	GraylogMongoRestoreJobName      = "graylog-mongo-restore"
+	GraylogMongoPreUpgradeBackupJob = "graylog-mongo-pre-upgrade-backup"
+	GraylogMongoProbeJobName        = "graylog-mongo-probe"
+	GraylogLookupTablesConfigMap    = "graylog-lookup-tables"
+	GraylogLookupTablesPath         = "/usr/share/graylog/lookup-tables"
 	GraylogStatus                   = "ReconcileGraylogStatus"
+	GraylogAuthenticationStatus     = "GraylogAuthenticationStatus"
+	OpenSearchHealthStatus          = "OpenSearchHealthStatus"
 	GraylogConfig                   = "config/"
 	GraylogServiceAccount           = path.Join(BasePath, "service-account.yaml")
 	GraylogStatefulset              = path.Join(BasePath, "statefulset.yaml")
 	GraylogService                  = path.Join(BasePath, "service.yaml")
+	GraylogNodesService             = path.Join(BasePath, "nodes-service.yaml")
+	GraylogInputService             = path.Join(BasePath, "input-service.yaml")
+	GraylogPodDisruptionBudget      = path.Join(BasePath, "pdb.yaml")
+	GraylogMongoStatefulset         = path.Join(BasePath, "mongo-statefulset.yaml")
+	GraylogMongoService             = path.Join(BasePath, "mongo-service.yaml")
+	GraylogMongoPodDisruptionBudget = path.Join(BasePath, "mongo-pdb.yaml")
+	GraylogEmbeddedMongoService     = path.Join(BasePath, "embedded-mongo-service.yaml")
+	GraylogMongoBackupCronJob       = path.Join(BasePath, "mongo-backup-cronjob.yaml")
+	GraylogMongoRestoreJob          = path.Join(BasePath, "mongo-restore-job.yaml")
+	GraylogMongoUpgradeJob          = path.Join(BasePath, "mongo-upgrade-job.yaml")
+	GraylogMongoPreUpgradeBackup    = path.Join(BasePath, "mongo-pre-upgrade-backup-job.yaml")
+	GraylogMongoProbeJob            = path.Join(BasePath, "mongo-probe-job.yaml")
 	GraylogConfigMapDirectory       = path.Join(GraylogConfig, "configmap")
 	GraylogGrokPatterns             = path.Join(GraylogConfig, "grok_patterns.json")
 	GraylogDefaultStream            = "Default Stream"
@@ -123,6 +150,7 @@ var (
 	GraylogMicroserviceFrameworkExtractorAsset = "microservice_framework_extractor.json"
 	Graylog4ExtractorsBasePath                 = path.Join(GraylogConfig, "extractors/graylog_4/")
 	Graylog5ExtractorsBasePath                 = path.Join(GraylogConfig, "extractors/graylog_5/")
+	Graylog6ExtractorsBasePath                 = path.Join(GraylogConfig, "extractors/graylog_6/")
 	Graylog4Extractors                         = map[string]string{
 		GraylogReplaceTimestampExtractorName:      path.Join(Graylog4ExtractorsBasePath, GraylogReplaceTimestampExtractorAsset),
 		GraylogKubernetesExtractorName:            path.Join(Graylog4ExtractorsBasePath, GraylogKubernetesExtractorAsset),
@@ -137,6 +165,13 @@ var (
 		GraylogDockerExtractorName:                path.Join(Graylog5ExtractorsBasePath, GraylogDockerExtractorAsset),
 		GraylogMicroserviceFrameworkExtractorName: path.Join(Graylog5ExtractorsBasePath, GraylogMicroserviceFrameworkExtractorAsset),
 	}
+	Graylog6Extractors = map[string]string{
+		GraylogReplaceTimestampExtractorName:      path.Join(Graylog6ExtractorsBasePath, GraylogReplaceTimestampExtractorAsset),
+		GraylogKubernetesExtractorName:            path.Join(Graylog6ExtractorsBasePath, GraylogKubernetesExtractorAsset),
+		GraylogKubernetesLabelsExtractorName:      path.Join(Graylog6ExtractorsBasePath, GraylogKubernetesLabelsExtractorAsset),
+		GraylogDockerExtractorName:                path.Join(Graylog6ExtractorsBasePath, GraylogDockerExtractorAsset),
+		GraylogMicroserviceFrameworkExtractorName: path.Join(Graylog6ExtractorsBasePath, GraylogMicroserviceFrameworkExtractorAsset),
+	}
 	GraylogAuditProcessingRule              = "Route Audit logs"
 	GraylogSystemLogsProcessingRule         = "Route System logs"
 	GraylogRemoveKubernetesRule             = "Remove kubernetes field"
@@ -198,26 +233,16 @@ var (
 	GraylogOperatorUser             = path.Join(GraylogConfig, "user_accounts/operator.json")
 	GraylogAuditViewerUser          = path.Join(GraylogConfig, "user_accounts/auditViewer.json")
 	GraylogAdminWithTrustedHeader   = path.Join(GraylogConfig, "user_accounts/admin_with_trusted_header.json")
+	GraylogServiceUserRole          = path.Join(GraylogConfig, "roles/loggingOperator.json")
+	GraylogServiceUser              = path.Join(GraylogConfig, "user_accounts/loggingOperator.json")
+	GraylogServiceUserName          = "logging-operator"
+	GraylogTokenSecretName          = "graylog-operator-token"
 	GraylogStartupTimeout           = time.Minute * 10
-	GraylogMongoUpgradeJobTimeout   = time.Minute * 2
+	GraylogMongoUpgradeJobTimeout   = time.Minute * 5
+	GraylogMongoRestoreJobTimeout   = time.Minute * 10
 	GraylogLabels                   = map[string]string{"name": "graylog"}
 	GraylogSecretSelector           = "graylog=secret"
-	GraylogConfigFileName           = "graylog.conf"
-	GraylogPasswordField            = "root_password_sha2"
-	GraylogUserField                = "root_username"
-	GraylogMongoUpgradeOrderedJobs  = []string{
-		"mongo-upgrade-job-40",
-		"mongo-upgrade-job-42",
-		"mongo-upgrade-job-44",
-		"mongo-upgrade-job-50",
-	}
-	GraylogMongoUpgradeAssets = map[string]string{
-		"mongo-upgrade-job-40": path.Join(BasePath, "mongo-upgrade-job-40.yaml"),
-		"mongo-upgrade-job-42": path.Join(BasePath, "mongo-upgrade-job-42.yaml"),
-		"mongo-upgrade-job-44": path.Join(BasePath, "mongo-upgrade-job-44.yaml"),
-		"mongo-upgrade-job-50": path.Join(BasePath, "mongo-upgrade-job-50.yaml"),
-	}
-	GraylogMongoUpgradeLabels = map[string]string{"name": "mongo-upgrade-job"}
+	GraylogMongoUpgradeLabels       = map[string]string{"name": "mongo-upgrade-job"}
 
 	ComponentPendingStatus            = "ComponentPendingStatus"
 	ComponentPendingTimeout           = time.Minute * 5
@@ -226,4 +251,6 @@ var (
 	ConnectionTimeout = 10
 
 	InitialDelay = time.Second * 5
+
+	PausedHealthCheckInterval = time.Minute * 5
 )
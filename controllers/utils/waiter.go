@@ -142,13 +142,14 @@ type ComponentsPendingReconciler struct {
 	ComponentList *[]Component
 }
 
-func NewComponentsPendingReconciler(client client.Client, scheme *runtime.Scheme, updater StatusUpdater, pendingComponents *[]Component) ComponentsPendingReconciler {
+func NewComponentsPendingReconciler(client client.Client, scheme *runtime.Scheme, updater StatusUpdater, recorder EventRecorder, pendingComponents *[]Component) ComponentsPendingReconciler {
 	return ComponentsPendingReconciler{
 		ComponentReconciler: &ComponentReconciler{
 			Client:        client,
 			Scheme:        scheme,
 			Log:           Logger("components-pending"),
 			StatusUpdater: updater,
+			EventRecorder: recorder,
 		},
 		ComponentList: pendingComponents,
 	}
@@ -174,7 +175,9 @@ func (r *ComponentsPendingReconciler) Run(cr *loggingService.LoggingService) (bo
 				r.Log.Info("Timeout waiting for component statuses")
 				for _, component := range *r.ComponentList {
 					r.Log.Error(fmt.Errorf("%s is not started", component.ComponentName), fmt.Sprintf("Deploy of the %s is failed", component.ComponentName))
+					ObservePendingComponent(component.ComponentName, time.Since(start)+InitialDelay, ResultTimeout)
 					r.StatusUpdater.UpdateStatus(component.StatusName, Failed, false, fmt.Sprintf("Reason: %s is not started", component.ComponentName))
+					r.EventRecorder.Warning(ReasonReconcileFailed, fmt.Sprintf("%s is not started in %s", component.ComponentName, ComponentPendingTimeout))
 				}
 				break
 			}
@@ -195,6 +198,7 @@ func (r *ComponentsPendingReconciler) Run(cr *loggingService.LoggingService) (bo
 				}
 				if isAvailable {
 					r.Log.Info(fmt.Sprintf("The %s component is started", component.ComponentName))
+					ObservePendingComponent(component.ComponentName, time.Since(start)+InitialDelay, ResultSuccess)
 					r.StatusUpdater.RemoveStatus(component.StatusName)
 					(*r.ComponentList)[i] = (*r.ComponentList)[len(*r.ComponentList)-1]
 					*r.ComponentList = (*r.ComponentList)[:len(*r.ComponentList)-1]